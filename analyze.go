@@ -0,0 +1,146 @@
+package main
+
+import (
+    "primer/constants"
+    "primer/word"
+    "encoding/json"
+    "fmt"
+    "os"
+)
+
+const analyzeUsage = `Usage: primer analyze [flags] <value>
+
+Scores a single constant, given in hex (0x...) or decimal, through every
+candidate test: primality, avalanche, weak keys and the statistical tests.
+With -nist, the NIST SP 800-22 battery also runs on megabit keystreams of
+the configured transform keyed with the value.
+
+Flags:
+`
+
+type analysisReport struct {
+    Candidate     constants.ConstantCandidate
+    OverallScore  float64
+    Accepted      bool
+    Rejection     string
+}
+
+func runAnalyze(args []string) {
+    fs := newFlagSet("analyze", analyzeUsage)
+    configPath := fs.String("config", "", "Path to configuration file")
+    wordSize := fs.Int("w", 0, "Word size in bits (default: from the configuration)")
+    seed := fs.String("seed", "", "Seed for reproducible avalanche scores (default: crypto/rand)")
+    runNIST := fs.Bool("nist", false, "Also run the NIST SP 800-22 battery on keystreams keyed with the value")
+    var overrides settingsFlag
+    fs.Var(&overrides, "set", "Override a setting as Key=Value (repeatable)")
+    var format OutputFormat
+    fs.Var((*outputFormatFlag)(&format), "format", "Output format (text, json)")
+    fs.Parse(args)
+
+    if fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    value, err := word.Parse(fs.Arg(0))
+    if err != nil {
+        fmt.Printf("Error parsing value: %v\n", err)
+        os.Exit(1)
+    }
+
+    config, _, err := constants.LoadConfigLayers(constants.ConfigLayers{
+        Path:      *configPath,
+        Env:       os.Environ(),
+        Warn:      warnConfig,
+        Overrides: overrides,
+    })
+    if err != nil {
+        fmt.Printf("Error loading configuration: %v\n", err)
+        os.Exit(1)
+    }
+    config.DetailedLogging = false
+    config.StatisticalAnalysis = true
+    if *wordSize != 0 {
+        config.WordSize = *wordSize
+    }
+    if *seed != "" {
+        config.Seed = *seed
+    }
+    if *runNIST {
+        config.NIST.Enabled = true
+    }
+    if err := constants.ValidateConfig(&config); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        os.Exit(1)
+    }
+    if value.BitLen() > config.WordSize {
+        fmt.Printf("Error: 0x%X does not fit in %d bits\n", value, config.WordSize)
+        os.Exit(1)
+    }
+
+    generator := constants.NewGenerator(config)
+    candidate := generator.AnalyzeConstant(value)
+    report := analysisReport{
+        Candidate:    candidate,
+        OverallScore: calculateOverallScore(candidate),
+        Accepted:     generator.Accepts(candidate),
+        Rejection:    generator.RejectionReason(candidate),
+    }
+
+    switch format {
+    case FormatJSON:
+        output, err := json.MarshalIndent(report, "", "  ")
+        if err != nil {
+            fmt.Printf("Error generating JSON output: %v\n", err)
+            os.Exit(1)
+        }
+        fmt.Println(string(output))
+    case FormatCSV:
+        fmt.Println("Error: analyze supports text and json output")
+        os.Exit(2)
+    default:
+        printAnalysisReport(report)
+    }
+}
+
+func printAnalysisReport(r analysisReport) {
+    c := r.Candidate
+    fmt.Printf("Constant Analysis:\n")
+    printConstantAnalysis(os.Stdout, c)
+
+    fmt.Printf("  Primality Tests:\n")
+    for _, test := range c.TestResults.PrimalityTests {
+        fmt.Printf("    %s: %v (%s)\n", test.Method, test.Passed, test.Details)
+    }
+
+    fmt.Printf("  Avalanche Tests:\n")
+    for _, test := range c.TestResults.AvalancheTests {
+        name := test.Transform
+        if test.Rounds > 0 {
+            name = fmt.Sprintf("%s, %d rounds", test.Transform, test.Rounds)
+        }
+        fmt.Printf("    %s: %.4f (%d of %d bits changed)\n", name, test.Score, test.Changes, test.Total)
+        if sac := test.SAC; sac != nil {
+            fmt.Printf("      SAC: mean deviation %.4f, worst %.4f (input bit %d, output bit %d), chi-square p-value %.4f\n",
+                sac.MeanDeviation, sac.MaxDeviation, sac.WorstInput, sac.WorstOutput, sac.PValue)
+        }
+        if bic := test.BIC; bic != nil {
+            fmt.Printf("      BIC: worst correlation %.4f (output bits %d and %d, input bit %d)\n",
+                bic.MaxCorrelation, bic.WorstOutputs[0], bic.WorstOutputs[1], bic.WorstInput)
+        }
+    }
+
+    fmt.Printf("  Weak Key Tests:\n")
+    for _, test := range c.TestResults.WeakKeyTests {
+        fmt.Printf("    %s: %v\n", test.Pattern, test.Passed)
+        if test.Details != "" {
+            fmt.Printf("      %s\n", test.Details)
+        }
+    }
+
+    fmt.Printf("\nOverall Score: %.4f\n", r.OverallScore)
+    fmt.Printf("Accepted as a candidate: %v\n", r.Accepted)
+    if r.Rejection != "" {
+        fmt.Printf("Rejected by: %s\n", r.Rejection)
+    }
+}
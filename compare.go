@@ -0,0 +1,147 @@
+package main
+
+import (
+    "primer/constants"
+    "encoding/json"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+    "text/tabwriter"
+)
+
+const compareUsage = `Usage: primer compare [flags] <a.json> <b.json> [more.json ...]
+
+Compares the constants and scores of two or more saved results.
+
+Flags:
+`
+
+// resultSummary is one column of a comparison.
+type resultSummary struct {
+    Name             string
+    WordSize         int
+    Seed             string
+    P                string
+    Q                string
+    PAvalanche       float64
+    QAvalanche       float64
+    PBitDistribution float64
+    QBitDistribution float64
+    PEntropy         float64
+    QEntropy         float64
+    PScore           float64
+    QScore           float64
+    PQDistance       int
+    TotalCandidates  int
+}
+
+func runCompare(args []string) {
+    fs := newFlagSet("compare", compareUsage)
+    var format OutputFormat
+    fs.Var((*outputFormatFlag)(&format), "format", "Output format (text, json, csv)")
+    fs.Parse(args)
+
+    if fs.NArg() < 2 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    summaries := make([]resultSummary, 0, fs.NArg())
+    for _, path := range fs.Args() {
+        result, err := constants.LoadResult(path)
+        if err != nil {
+            fmt.Printf("Error: %v\n", err)
+            os.Exit(1)
+        }
+        summaries = append(summaries, summarizeResult(path, result))
+    }
+
+    switch format {
+    case FormatJSON:
+        output, err := json.MarshalIndent(summaries, "", "  ")
+        if err != nil {
+            fmt.Printf("Error generating JSON output: %v\n", err)
+            os.Exit(1)
+        }
+        fmt.Println(string(output))
+    case FormatCSV:
+        printComparisonCSV(summaries)
+    default:
+        printComparison(os.Stdout, summaries)
+    }
+}
+
+func summarizeResult(name string, result *constants.GenerationResult) resultSummary {
+    p, q := result.SelectedP, result.SelectedQ
+    return resultSummary{
+        Name:             name,
+        WordSize:         result.Config.WordSize,
+        Seed:             result.Config.Seed,
+        P:                fmt.Sprintf("0x%X", p.Value),
+        Q:                fmt.Sprintf("0x%X", q.Value),
+        PAvalanche:       p.AvalancheScore,
+        QAvalanche:       q.AvalancheScore,
+        PBitDistribution: p.BitDistribution,
+        QBitDistribution: q.BitDistribution,
+        PEntropy:         p.EntropyScore,
+        QEntropy:         q.EntropyScore,
+        PScore:           calculateOverallScore(p),
+        QScore:           calculateOverallScore(q),
+        PQDistance:       p.Value.Xor(q.Value).OnesCount(),
+        TotalCandidates:  result.TotalCandidates,
+    }
+}
+
+// comparisonRows lists the rows of a comparison with one cell per result.
+func comparisonRows(summaries []resultSummary) [][]string {
+    rows := []struct {
+        label string
+        cell  func(s resultSummary) string
+    }{
+        {"", func(s resultSummary) string { return s.Name }},
+        {"Word Size", func(s resultSummary) string { return fmt.Sprint(s.WordSize) }},
+        {"Seed", func(s resultSummary) string { return s.Seed }},
+        {"P", func(s resultSummary) string { return s.P }},
+        {"Q", func(s resultSummary) string { return s.Q }},
+        {"P Avalanche Score", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.PAvalanche) }},
+        {"Q Avalanche Score", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.QAvalanche) }},
+        {"P Bit Distribution", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.PBitDistribution) }},
+        {"Q Bit Distribution", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.QBitDistribution) }},
+        {"P Entropy Score", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.PEntropy) }},
+        {"Q Entropy Score", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.QEntropy) }},
+        {"P Overall Score", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.PScore) }},
+        {"Q Overall Score", func(s resultSummary) string { return fmt.Sprintf("%.4f", s.QScore) }},
+        {"P/Q Hamming Distance", func(s resultSummary) string { return fmt.Sprint(s.PQDistance) }},
+        {"Candidates Tested", func(s resultSummary) string { return fmt.Sprint(s.TotalCandidates) }},
+    }
+
+    table := make([][]string, 0, len(rows))
+    for _, row := range rows {
+        cells := []string{row.label}
+        for _, s := range summaries {
+            cells = append(cells, row.cell(s))
+        }
+        table = append(table, cells)
+    }
+    return table
+}
+
+func printComparison(w io.Writer, summaries []resultSummary) {
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+    for _, row := range comparisonRows(summaries) {
+        fmt.Fprintln(tw, strings.Join(row, "\t"))
+    }
+    tw.Flush()
+}
+
+func printComparisonCSV(summaries []resultSummary) {
+    for _, row := range comparisonRows(summaries) {
+        for i, cell := range row {
+            if strings.ContainsAny(cell, ",\"") {
+                row[i] = `"` + strings.ReplaceAll(cell, `"`, `""`) + `"`
+            }
+        }
+        fmt.Println(strings.Join(row, ","))
+    }
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+    "primer/constants"
+    "encoding/json"
+    "fmt"
+    "os"
+    "text/tabwriter"
+)
+
+const configUsage = `Usage: primer config <command> [arguments]
+
+Commands:
+  schema     Print the JSON Schema of the configuration file
+  validate   Check configuration files for unknown keys and invalid values
+  show       Print the merged configuration and where each value came from
+`
+
+const configSchemaUsage = `Usage: primer config schema
+
+Prints a JSON Schema (draft 2020-12) for the configuration file, with the
+built-in defaults. Keys starting with "//" are allowed as comments.
+
+Flags:
+`
+
+const configShowUsage = `Usage: primer config show [flags]
+
+Prints the configuration generate would use: the defaults, then the -config
+file (JSON, YAML or TOML), then PRIMER_* environment variables, then -set
+flags. With -effective, lists every setting with the layer that set it.
+Environment variables name settings in upper snake case, for example
+PRIMER_PARALLEL_WORKERS or PRIMER_STATISTICAL_TESTS_MIN_P_VALUE.
+
+Flags:
+`
+
+const configValidateUsage = `Usage: primer config validate <config.json> [more.json ...]
+
+Loads each configuration file strictly, reporting unknown keys, type errors
+and out-of-range values with their file positions. Exits with status 1 if
+any file is invalid.
+
+Flags:
+`
+
+func runConfig(args []string) {
+    if len(args) == 0 {
+        fmt.Fprint(os.Stderr, configUsage)
+        os.Exit(2)
+    }
+
+    switch args[0] {
+    case "schema":
+        runConfigSchema(args[1:])
+    case "validate":
+        runConfigValidate(args[1:])
+    case "show":
+        runConfigShow(args[1:])
+    case "-h", "-help", "--help", "help":
+        fmt.Fprint(os.Stderr, configUsage)
+    default:
+        fmt.Fprintf(os.Stderr, "Unknown config command: %s\n\n", args[0])
+        fmt.Fprint(os.Stderr, configUsage)
+        os.Exit(2)
+    }
+}
+
+func runConfigSchema(args []string) {
+    fs := newFlagSet("config schema", configSchemaUsage)
+    fs.Parse(args)
+
+    output, err := json.MarshalIndent(constants.ConfigSchema(), "", "  ")
+    if err != nil {
+        fmt.Printf("Error generating schema: %v\n", err)
+        os.Exit(1)
+    }
+    fmt.Println(string(output))
+}
+
+func runConfigValidate(args []string) {
+    fs := newFlagSet("config validate", configValidateUsage)
+    fs.Parse(args)
+
+    if fs.NArg() == 0 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    failed := false
+    for _, path := range fs.Args() {
+        if _, err := constants.LoadConfig(path); err != nil {
+            fmt.Printf("%s: invalid\n%v\n", path, err)
+            failed = true
+            continue
+        }
+        fmt.Printf("%s: OK\n", path)
+    }
+
+    if failed {
+        os.Exit(1)
+    }
+}
+
+func runConfigShow(args []string) {
+    fs := newFlagSet("config show", configShowUsage)
+    configPath := fs.String("config", "", "Path to configuration file")
+    effective := fs.Bool("effective", false, "List each setting with where its value came from")
+    var format OutputFormat
+    fs.Var((*outputFormatFlag)(&format), "format", "Output format (text, json)")
+    var overrides settingsFlag
+    fs.Var(&overrides, "set", "Override a setting as Key=Value (repeatable)")
+    fs.Parse(args)
+
+    config, sources, err := constants.LoadConfigLayers(constants.ConfigLayers{
+        Path:      *configPath,
+        Env:       os.Environ(),
+        Warn:      warnConfig,
+        Overrides: overrides,
+    })
+    if err != nil {
+        fmt.Printf("Error loading configuration: %v\n", err)
+        os.Exit(1)
+    }
+
+    if !*effective {
+        output, err := json.MarshalIndent(config, "", "  ")
+        if err != nil {
+            fmt.Printf("Error generating JSON output: %v\n", err)
+            os.Exit(1)
+        }
+        fmt.Println(string(output))
+        return
+    }
+
+    settings := constants.EffectiveSettings(config, sources)
+    if format == FormatJSON {
+        output, err := json.MarshalIndent(settings, "", "  ")
+        if err != nil {
+            fmt.Printf("Error generating JSON output: %v\n", err)
+            os.Exit(1)
+        }
+        fmt.Println(string(output))
+        return
+    }
+
+    tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(tw, "Setting\tValue\tSource")
+    for _, setting := range settings {
+        value := fmt.Sprint(setting.Value)
+        if s, ok := setting.Value.(string); ok {
+            value = fmt.Sprintf("%q", s)
+        }
+        fmt.Fprintf(tw, "%s\t%s\t%s\n", setting.Path, value, setting.Source)
+    }
+    tw.Flush()
+}
@@ -0,0 +1,178 @@
+package constants
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"primer/special"
+)
+
+// Methods for combining the statistical tests of a candidate into one
+// decision, set in ValidationCriteria.TestAggregation. Every method but
+// AggregateFraction works on p-values at the significance level
+// StatisticalTests.MinPValue.
+const (
+	// AggregateFraction passes when the weighted fraction of failed tests
+	// is at most ValidationCriteria.MaxFailedTestFraction.
+	AggregateFraction = "fraction"
+	// AggregateBonferroni passes when no p-value is below alpha/m for m
+	// tests.
+	AggregateBonferroni = "bonferroni"
+	// AggregateHolm is the Holm-Bonferroni step-down procedure. It passes
+	// exactly when Bonferroni does, but rejects more of the tests when it
+	// fails.
+	AggregateHolm = "holm"
+	// AggregateBenjaminiHochberg controls the false discovery rate and
+	// passes when the procedure rejects no test.
+	AggregateBenjaminiHochberg = "benjamini-hochberg"
+	// AggregateFisher combines the p-values into -2 Σ ln p, chi-square
+	// with 2m degrees of freedom under the null hypothesis.
+	AggregateFisher = "fisher"
+)
+
+// aggregationMethods lists the valid TestAggregation values. There is no
+// Kolmogorov-Smirnov method: it has no power over the handful of p-values
+// one candidate gives, so it judges the p-values of the NIST battery across
+// its sequences instead, gated by NIST.MinUniformityKS.
+var aggregationMethods = []string{
+	AggregateFraction,
+	AggregateBonferroni,
+	AggregateHolm,
+	AggregateBenjaminiHochberg,
+	AggregateFisher,
+}
+
+func validateTestAggregation(method string) error {
+	if method == "" {
+		return nil
+	}
+	for _, known := range aggregationMethods {
+		if method == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("ValidationCriteria: unknown TestAggregation %q (want one of %s)",
+		method, strings.Join(aggregationMethods, ", "))
+}
+
+// testAggregation returns the configured aggregation method.
+func (g *Generator) testAggregation() string {
+	if method := g.config.ValidationCriteria.TestAggregation; method != "" {
+		return method
+	}
+	return AggregateFraction
+}
+
+// verifyTestResults reports whether the statistical tests of a candidate
+// pass as a whole under the configured aggregation method.
+func (g *Generator) verifyTestResults(tests []StatisticalTest) bool {
+	return g.aggregateSignificance(tests).Passed
+}
+
+// aggregateSignificance combines the results of tests into one decision
+// with the configured method. Tests with zero weight do not count. The
+// p-value methods take every test's PValue, except for tests that passed
+// with a zero p-value, which do not compute one.
+func (g *Generator) aggregateSignificance(tests []StatisticalTest) *TestAggregate {
+	method := g.testAggregation()
+	alpha := g.statisticalTests().MinPValue
+	result := &TestAggregate{Method: method, Alpha: alpha, Passed: true}
+
+	if method == AggregateFraction {
+		var failed, total float64
+		for _, test := range tests {
+			weight := g.statisticalTestWeight(test.Name)
+			total += weight
+			if !test.Passed && weight > 0 {
+				failed += weight
+				result.Rejected = append(result.Rejected, test.Name)
+			}
+		}
+		if total > 0 {
+			result.Passed = failed <= g.config.ValidationCriteria.MaxFailedTestFraction*total
+			result.Details = fmt.Sprintf("weighted failed fraction %.4f (maximum %.4f)",
+				failed/total, g.config.ValidationCriteria.MaxFailedTestFraction)
+		}
+		return result
+	}
+
+	var names []string
+	var pValues []float64
+	for _, test := range tests {
+		if g.statisticalTestWeight(test.Name) == 0 || (test.Passed && test.PValue == 0) {
+			continue
+		}
+		names = append(names, test.Name)
+		pValues = append(pValues, test.PValue)
+	}
+	m := len(pValues)
+	if m == 0 {
+		result.PValue = 1
+		result.Details = "no p-values to combine"
+		return result
+	}
+
+	// Tests in ascending order of p-value, for the step procedures
+	order := make([]int, m)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return pValues[order[a]] < pValues[order[b]] })
+	reject := func(count int) {
+		for _, i := range order[:count] {
+			result.Rejected = append(result.Rejected, names[i])
+		}
+	}
+
+	switch method {
+	case AggregateBonferroni:
+		result.PValue = math.Min(1, float64(m)*pValues[order[0]])
+		for _, i := range order {
+			if pValues[i] < alpha/float64(m) {
+				result.Rejected = append(result.Rejected, names[i])
+			}
+		}
+		result.Details = fmt.Sprintf("%d of %d tests below alpha/%d", len(result.Rejected), m, m)
+
+	case AggregateHolm:
+		result.PValue = math.Min(1, float64(m)*pValues[order[0]])
+		count := 0
+		for count < m && pValues[order[count]] < alpha/float64(m-count) {
+			count++
+		}
+		reject(count)
+		result.Details = fmt.Sprintf("%d of %d tests rejected step-down", count, m)
+
+	case AggregateBenjaminiHochberg:
+		// The largest k with p(k) <= k alpha/m rejects the k smallest
+		count := 0
+		result.PValue = 1
+		for k := 1; k <= m; k++ {
+			p := pValues[order[k-1]]
+			result.PValue = math.Min(result.PValue, p*float64(m)/float64(k))
+			if p <= float64(k)*alpha/float64(m) {
+				count = k
+			}
+		}
+		reject(count)
+		result.Details = fmt.Sprintf("%d of %d tests rejected at false discovery rate %.4f", count, m, alpha)
+
+	case AggregateFisher:
+		statistic := 0.0
+		for _, p := range pValues {
+			statistic -= 2 * math.Log(p)
+		}
+		result.PValue = special.ChiSquareSurvival(statistic, float64(2*m))
+		result.Details = fmt.Sprintf("Chi-square: %.4f (%d degrees of freedom)", statistic, 2*m)
+	}
+
+	switch method {
+	case AggregateFisher:
+		result.Passed = result.PValue >= alpha
+	default:
+		result.Passed = len(result.Rejected) == 0
+	}
+	return result
+}
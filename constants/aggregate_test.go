@@ -0,0 +1,104 @@
+package constants
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func pValueTests(pValues ...float64) []StatisticalTest {
+	tests := make([]StatisticalTest, len(pValues))
+	for i, p := range pValues {
+		tests[i] = StatisticalTest{Name: string(rune('A' + i)), PValue: p, Passed: p >= 0.05}
+	}
+	return tests
+}
+
+func TestAggregateSignificance(t *testing.T) {
+	// Bonferroni rejects below 0.01, Holm below 0.01 then 0.0125, and
+	// Benjamini-Hochberg up to the fourth p-value, 0.039 <= 4 * 0.05/5
+	mixed := pValueTests(0.001, 0.012, 0.029, 0.039, 0.9)
+	uniform := pValueTests(0.1, 0.3, 0.5, 0.7, 0.9)
+
+	tests := []struct {
+		name         string
+		method       string
+		tests        []StatisticalTest
+		wantPassed   bool
+		wantRejected []string
+		wantPValue   float64
+	}{
+		{"Fraction", AggregateFraction, mixed, false, []string{"A", "B", "C", "D"}, 0},
+		{"Fraction uniform", AggregateFraction, uniform, true, nil, 0},
+		{"Bonferroni", AggregateBonferroni, mixed, false, []string{"A"}, 0.005},
+		{"Bonferroni uniform", AggregateBonferroni, uniform, true, nil, 0.5},
+		{"Holm", AggregateHolm, mixed, false, []string{"A", "B"}, 0.005},
+		{"Benjamini-Hochberg", AggregateBenjaminiHochberg, mixed, false, []string{"A", "B", "C", "D"}, 0.005},
+		{"Benjamini-Hochberg uniform", AggregateBenjaminiHochberg, uniform, true, nil, 0.5},
+		// -2 ln(0.5) * 5 = 6.9315 with 10 degrees of freedom
+		{"Fisher", AggregateFisher, pValueTests(0.5, 0.5, 0.5, 0.5, 0.5), true, nil, 0.731898},
+		{"Fisher mixed", AggregateFisher, mixed, false, nil, 0.000071},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.StatisticalTests.MinPValue = 0.05
+			config.ValidationCriteria.TestAggregation = tt.method
+			got := NewGenerator(config).aggregateSignificance(tt.tests)
+
+			if got.Method != tt.method || got.Alpha != 0.05 {
+				t.Errorf("method %q at alpha %v", got.Method, got.Alpha)
+			}
+			if got.Passed != tt.wantPassed {
+				t.Errorf("Passed = %v, want %v (%s)", got.Passed, tt.wantPassed, got.Details)
+			}
+			if !reflect.DeepEqual(got.Rejected, tt.wantRejected) {
+				t.Errorf("Rejected = %v, want %v", got.Rejected, tt.wantRejected)
+			}
+			if math.Abs(got.PValue-tt.wantPValue) > 1e-6 {
+				t.Errorf("PValue = %.6f, want %.6f", got.PValue, tt.wantPValue)
+			}
+		})
+	}
+}
+
+func TestAggregateSignificanceSkipsTests(t *testing.T) {
+	config := DefaultConfig()
+	config.ValidationCriteria.TestAggregation = AggregateBonferroni
+	config.RandomnessTests.RunsTestWeight = 0
+	g := NewGenerator(config)
+
+	tests := []StatisticalTest{
+		{Name: bitFrequencyTestName, PValue: 0.5, Passed: true},
+		// Weighted out
+		{Name: runsTestName, PValue: 0, Passed: false},
+		// A registered test without a p-value
+		{Name: "Custom", PValue: 0, Passed: true},
+	}
+	got := g.aggregateSignificance(tests)
+	if !got.Passed || got.PValue != 0.5 || !strings.Contains(got.Details, "of 1 tests") {
+		t.Errorf("got %+v, want only the frequency test counted", got)
+	}
+
+	// A failed test without a p-value counts as a p-value of zero
+	tests[2].Passed = false
+	if g.aggregateSignificance(tests).Passed {
+		t.Error("failed test without a p-value ignored")
+	}
+}
+
+func TestTestAggregationValidation(t *testing.T) {
+	config := DefaultConfig()
+	for _, method := range append(aggregationMethods, "") {
+		config.ValidationCriteria.TestAggregation = method
+		if err := ValidateConfig(&config); err != nil {
+			t.Errorf("%q: %v", method, err)
+		}
+	}
+	config.ValidationCriteria.TestAggregation = "sidak"
+	if err := ValidateConfig(&config); err == nil || !strings.Contains(err.Error(), "TestAggregation") {
+		t.Errorf("unknown method: %v", err)
+	}
+}
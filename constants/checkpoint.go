@@ -0,0 +1,185 @@
+package constants
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// checkpointVersion is bumped whenever the checkpoint format changes.
+const checkpointVersion = 2
+
+// ConfigHash identifies the settings that determine which candidates a run
+// generates and accepts. Settings that only affect logging, output files,
+// checkpointing or the time limit are excluded, so a run that timed out can
+// be resumed with a longer TimeoutSeconds.
+func ConfigHash(config Config) string {
+	config.TimeoutSeconds = 0
+	config.DetailedLogging = false
+	config.ResultsFile = ""
+	config.CheckpointFile = ""
+	config.CheckpointSeconds = 0
+	config.StreamFile = ""
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		panic(fmt.Sprintf("marshalling config: %v", err))
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// randomState describes the random source. Each candidate's stream is
+// derived from the source and the candidate index alone, so together with
+// the worker progress this is all the state needed to continue a run.
+func (g *Generator) randomState() RandomState {
+	switch source := g.random.(type) {
+	case nil, CryptoSource:
+		return RandomState{Source: "crypto/rand"}
+	case *SeededSource:
+		sum := sha256.Sum256(source.seed)
+		return RandomState{Source: "hmac-drbg-sha256", SeedDigest: hex.EncodeToString(sum[:])}
+	default:
+		return RandomState{Source: fmt.Sprintf("%T", source)}
+	}
+}
+
+// LoadCheckpoint reads a checkpoint written during generation.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	if cp.Version != checkpointVersion {
+		return nil, fmt.Errorf("checkpoint %s has version %d, want %d", path, cp.Version, checkpointVersion)
+	}
+	return &cp, nil
+}
+
+// Resume makes the next Generate continue from cp instead of starting
+// over. The checkpoint must come from a run with the same configuration,
+// worker count and random source. With a seeded source the final selection
+// is the same as that of an uninterrupted run; with crypto/rand the
+// remaining candidates are drawn afresh.
+func (g *Generator) Resume(cp *Checkpoint) error {
+	if cp.ConfigHash != ConfigHash(g.config) {
+		return fmt.Errorf("checkpoint was written with a different configuration")
+	}
+	if len(cp.Workers) != g.config.ParallelWorkers {
+		return fmt.Errorf("checkpoint has %d workers, configuration has %d", len(cp.Workers), g.config.ParallelWorkers)
+	}
+	for i, progress := range cp.Workers {
+		if progress.Worker != i {
+			return fmt.Errorf("checkpoint worker %d is out of order", progress.Worker)
+		}
+	}
+	if cp.Random != g.randomState() {
+		return fmt.Errorf("checkpoint random source %s does not match %s", cp.Random.Source, g.randomState().Source)
+	}
+
+	g.resume = cp
+	return nil
+}
+
+// startProgress returns the first index each worker should process: zero,
+// or where the resumed checkpoint left off.
+func (g *Generator) startProgress(workers int) []int {
+	progress := make([]int, workers)
+	if g.resume != nil {
+		for i, p := range g.resume.Workers {
+			progress[i] = p.NextIndex
+		}
+	}
+	return progress
+}
+
+// resumedCandidates returns the accepted candidates carried over from the
+// resumed checkpoint.
+func (g *Generator) resumedCandidates() []ConstantCandidate {
+	if g.resume == nil {
+		return nil
+	}
+	return append([]ConstantCandidate(nil), g.resume.Candidates...)
+}
+
+// resumedStreamOffset returns how much of the candidate stream the resumed
+// checkpoint covers.
+func (g *Generator) resumedStreamOffset() int64 {
+	if g.resume == nil {
+		return 0
+	}
+	return g.resume.StreamOffset
+}
+
+// resumedFunnel returns the rejection counts carried over from the resumed
+// checkpoint.
+func (g *Generator) resumedFunnel() []WorkerFunnel {
+	if g.resume == nil {
+		return nil
+	}
+	return g.resume.Funnel
+}
+
+// saveCheckpoint writes the accepted candidates, the rejection counts, the
+// next index of each worker and the length of the candidate stream to
+// CheckpointFile. The file is replaced atomically so a crash
+// while writing leaves the previous checkpoint intact.
+func (g *Generator) saveCheckpoint(progress []int, funnel []WorkerFunnel, candidates []ConstantCandidate, streamOffset int64) error {
+	cp := Checkpoint{
+		Version:      checkpointVersion,
+		ConfigHash:   ConfigHash(g.config),
+		Random:       g.randomState(),
+		Workers:      make([]WorkerProgress, len(progress)),
+		Funnel:       funnel,
+		Candidates:   append([]ConstantCandidate(nil), candidates...),
+		StreamOffset: streamOffset,
+		SavedAt:      time.Now(),
+	}
+	for i, next := range progress {
+		cp.Workers[i] = WorkerProgress{Worker: i, NextIndex: next}
+	}
+	sort.Slice(cp.Candidates, func(i, j int) bool {
+		return cp.Candidates[i].Index < cp.Candidates[j].Index
+	})
+
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %v", err)
+	}
+
+	path := g.config.CheckpointFile
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create checkpoint: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
+
+// checkpointInterval returns how often to checkpoint, or zero for never.
+func (g *Generator) checkpointInterval() time.Duration {
+	if g.config.CheckpointFile == "" {
+		return 0
+	}
+	return time.Duration(g.config.CheckpointSeconds) * time.Second
+}
@@ -0,0 +1,127 @@
+package constants
+
+import (
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// interruptingSource stops the generator when the stream for a given
+// candidate index is first requested, simulating an interrupted run.
+type interruptingSource struct {
+	source RandomSource
+	at     uint64
+	stop   func()
+	once   sync.Once
+}
+
+func (s *interruptingSource) Stream(index uint64) io.Reader {
+	if index == s.at && s.stop != nil {
+		s.once.Do(s.stop)
+	}
+	return s.source.Stream(index)
+}
+
+func TestResumeMatchesUninterruptedRun(t *testing.T) {
+	config := testGenerationConfig("primer-checkpoint")
+	config.BatchSize = 2
+	config.CheckpointFile = filepath.Join(t.TempDir(), "checkpoint.json")
+
+	uninterrupted := NewGenerator(config)
+	uninterrupted.SetRandomSource(&interruptingSource{source: NewSeededSource(config.Seed)})
+	want, err := uninterrupted.Generate()
+	if err != nil {
+		t.Fatalf("uninterrupted Generate() error = %v", err)
+	}
+
+	interrupted := NewGenerator(config)
+	interrupted.SetRandomSource(&interruptingSource{
+		source: NewSeededSource(config.Seed),
+		at:     11,
+		stop:   interrupted.Cleanup,
+	})
+	if _, err := interrupted.Generate(); err == nil {
+		t.Fatal("interrupted Generate() succeeded, want cancellation error")
+	}
+
+	cp, err := LoadCheckpoint(config.CheckpointFile)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	done := 0
+	for _, w := range cp.Workers {
+		done += w.NextIndex
+	}
+	if done == 0 || len(cp.Candidates) == 0 {
+		t.Fatalf("checkpoint records no progress: %+v", cp.Workers)
+	}
+
+	resumed := NewGenerator(config)
+	resumed.SetRandomSource(&interruptingSource{source: NewSeededSource(config.Seed)})
+	if err := resumed.Resume(cp); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	got, err := resumed.Generate()
+	if err != nil {
+		t.Fatalf("resumed Generate() error = %v", err)
+	}
+
+	if got.SelectedP.Value != want.SelectedP.Value || got.SelectedQ.Value != want.SelectedQ.Value {
+		t.Errorf("resumed selection P=%s Q=%s, want P=%s Q=%s",
+			got.SelectedP.Value, got.SelectedQ.Value, want.SelectedP.Value, want.SelectedQ.Value)
+	}
+	if got.Funnel.Generated != want.Funnel.Generated || got.Funnel.Accepted != want.Funnel.Accepted {
+		t.Errorf("resumed funnel %d generated, %d accepted, want %d, %d",
+			got.Funnel.Generated, got.Funnel.Accepted, want.Funnel.Generated, want.Funnel.Accepted)
+	}
+	if got.SelectedP.Index != want.SelectedP.Index || got.SelectedQ.Index != want.SelectedQ.Index {
+		t.Errorf("resumed selection indices %d, %d, want %d, %d",
+			got.SelectedP.Index, got.SelectedQ.Index, want.SelectedP.Index, want.SelectedQ.Index)
+	}
+}
+
+func TestResumeRejectsMismatch(t *testing.T) {
+	config := testGenerationConfig("primer-checkpoint")
+	config.BatchSize = 2
+	config.CheckpointFile = filepath.Join(t.TempDir(), "checkpoint.json")
+	g := NewGenerator(config)
+	if err := g.saveCheckpoint(make([]int, config.ParallelWorkers), nil, nil, 0); err != nil {
+		t.Fatalf("saveCheckpoint() error = %v", err)
+	}
+	cp, err := LoadCheckpoint(config.CheckpointFile)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"Same config", func(c *Config) {}, false},
+		{"Longer timeout", func(c *Config) { c.TimeoutSeconds = 7200 }, false},
+		{"Different checkpoint file", func(c *Config) { c.CheckpointFile = "other.json" }, false},
+		{"Different seed", func(c *Config) { c.Seed = "other" }, true},
+		{"Different candidates", func(c *Config) { c.NumCandidates = 48 }, true},
+		{"Different workers", func(c *Config) { c.ParallelWorkers = 4 }, true},
+		{"Unseeded", func(c *Config) { c.Seed = "" }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := config
+			tt.modify(&c)
+			err := NewGenerator(c).Resume(cp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Resume() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadCheckpointErrors(t *testing.T) {
+	if _, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadCheckpoint() of a missing file succeeded")
+	}
+}
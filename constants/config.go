@@ -1,13 +1,72 @@
 package constants
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
+	"math"
+	"reflect"
+	"strings"
+
+	"primer/expansion"
+	"primer/nist"
+	"primer/rc6"
+)
+
+// Defaults for the nested sections. A generator whose section is entirely
+// unset, such as one built from a result saved by an older version, falls
+// back to these.
+var (
+	defaultStatisticalTests = StatisticalTestsConfig{
+		MinPValue:                0.01,
+		MaxPValue:                0.99,
+		MinEntropyScore:          0.9,
+		MaxEntropyScore:          1.0,
+		MaxBitFrequencyDeviation: 0.15,
+		MinRunsZScore:            -3.0,
+		MaxRunsZScore:            3.0,
+		MaxSerialCorrelation:     0.5,
+	}
+	defaultValidationCriteria = ValidationCriteria{
+		MaxFailedTestFraction: 0.2,
+		TestAggregation:       AggregateFraction,
+	}
+	defaultRandomnessTests = RandomnessTestsConfig{
+		EnableExtendedTests:    true,
+		RunsTestWeight:         1.0,
+		FrequencyTestWeight:    1.0,
+		SerialTestWeight:       1.0,
+		AutocorrelationWeight:  1.0,
+		LinearComplexityWeight: 1.0,
+	}
+	defaultScoringWeights = ScoringWeights{
+		BitDistribution: 1.0,
+		Avalanche:       2.0,
+		Entropy:         0.75,
+	}
+	defaultCryptanalysis = CryptanalysisConfig{
+		Enabled: true,
+		Bases:   1,
+	}
+	defaultKeySchedule = KeyScheduleConfig{
+		Enabled:           true,
+		Keys:              32,
+		EquivalentKeyBits: 12,
+	}
+	defaultPairAnalysis = PairAnalysisConfig{
+		Enabled:        true,
+		MaxCorrelation: 0.75,
+		MaxOutputBias:  0.25,
+	}
+	defaultNIST = NISTConfig{
+		Sequences:       10,
+		StreamBits:      1 << 20,
+		Alpha:           0.01,
+		MinUniformityKS: nist.MinUniformity,
+	}
 )
 
 func DefaultConfig() Config {
 	return Config{
+		WordSize:            32,
 		NumCandidates:       1000,
 		AvalancheTestCases:  10000,
 		MinPrimeAttempts:    100,
@@ -19,10 +78,76 @@ func DefaultConfig() Config {
 		ResultsFile:         "rc6_constants.json",
 		DetailedLogging:     true,
 		StatisticalAnalysis: true,
+		Transform:           TransformRC6,
+		CipherRounds:        rc6.DefaultRounds,
+		ReducedRounds:       4,
+		KeyBytes:            16,
+		GenerationMode:      ModeRandom,
+		RequirePrime:        true,
+		PrimeClass:          PrimeAny,
+		Derivation: DerivationConfig{
+			Sources: expansion.Names(),
+			Rules:   []string{RuleOdd, RuleNextPrime},
+			Offsets: []int{0},
+		},
+		StatisticalTests:   defaultStatisticalTests,
+		ValidationCriteria: defaultValidationCriteria,
+		RandomnessTests:    defaultRandomnessTests,
+		ScoringWeights:     defaultScoringWeights,
+		TimeoutSeconds:     1800,
+		BatchSize:          1,
+		CheckpointSeconds:  60,
+		ConstantNames:      append([]string(nil), defaultConstantNames...),
+		Alternates:         5,
+		SelectionStrategy:  StrategyScore,
+		ParetoTieBreakers:  append([]string(nil), defaultTieBreakers...),
+		NIST:               defaultNIST,
+		Cryptanalysis:      defaultCryptanalysis,
+		KeySchedule:        defaultKeySchedule,
+		PairAnalysis:       defaultPairAnalysis,
+	}
+}
+
+// validateFinite rejects NaN and infinite values anywhere in v. Every
+// comparison with NaN is false, so range checks alone let it through and
+// it would switch a threshold off.
+func validateFinite(v reflect.Value, path string) error {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		if f := v.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("%s must be a finite number, got %v", path, f)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := field.Name
+			if path != "" {
+				name = path + "." + name
+			}
+			if err := validateFinite(v.Field(i), name); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateFinite(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
 }
 
+// defaultConstantNames are the constants of RC6's key schedule.
+var defaultConstantNames = []string{"P", "Q"}
+
 func ValidateConfig(config *Config) error {
+	if err := validateFinite(reflect.ValueOf(*config), ""); err != nil {
+		return err
+	}
 	if config.NumCandidates < 1 {
 		return fmt.Errorf("NumCandidates must be positive")
 	}
@@ -35,24 +160,303 @@ func ValidateConfig(config *Config) error {
 	if config.MinAvalancheScore < 0 || config.MinAvalancheScore > 1 {
 		return fmt.Errorf("invalid avalanche score threshold")
 	}
+	if config.MaxSACDeviation < 0 || config.MaxSACDeviation > 0.5 {
+		return fmt.Errorf("MaxSACDeviation must be between 0 and 0.5")
+	}
+	if config.MinSACPValue < 0 || config.MinSACPValue >= 1 {
+		return fmt.Errorf("MinSACPValue must be at least 0 and below 1")
+	}
+	if config.MaxBICCorrelation < 0 || config.MaxBICCorrelation > 1 {
+		return fmt.Errorf("MaxBICCorrelation must be between 0 and 1")
+	}
+	switch config.WordSize {
+	case 16, 32, 64, 128:
+	default:
+		return fmt.Errorf("WordSize must be 16, 32, 64 or 128")
+	}
+	switch config.Transform {
+	case TransformRC6:
+		if config.CipherRounds < 1 || config.CipherRounds > 255 {
+			return fmt.Errorf("CipherRounds must be between 1 and 255")
+		}
+		if config.ReducedRounds < 0 || config.ReducedRounds > config.CipherRounds {
+			return fmt.Errorf("ReducedRounds must be between 0 and CipherRounds")
+		}
+		if config.KeyBytes < 0 || config.KeyBytes > 255 {
+			return fmt.Errorf("KeyBytes must be between 0 and 255")
+		}
+	case TransformSimple:
+	default:
+		return fmt.Errorf("unknown transform: %q", config.Transform)
+	}
+	switch config.GenerationMode {
+	case ModeRandom:
+	case ModeDerive:
+		if err := validateDerivationConfig(&config.Derivation); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown generation mode: %q", config.GenerationMode)
+	}
+	if err := validatePrimeClass(config); err != nil {
+		return err
+	}
+	if config.TimeoutSeconds < 0 {
+		return fmt.Errorf("TimeoutSeconds must not be negative")
+	}
+	if config.BatchSize < 1 {
+		return fmt.Errorf("BatchSize must be positive")
+	}
+	if config.CheckpointSeconds < 0 {
+		return fmt.Errorf("CheckpointSeconds must not be negative")
+	}
+	if err := validateConstantNames(config.ConstantNames); err != nil {
+		return err
+	}
+	if config.Alternates < 0 {
+		return fmt.Errorf("Alternates must not be negative")
+	}
+	if err := validateSelectionConfig(config); err != nil {
+		return err
+	}
+	if err := validateStatisticalTestsConfig(&config.StatisticalTests, config.WordSize); err != nil {
+		return err
+	}
+	if err := validateValidationCriteria(&config.ValidationCriteria, config.WordSize); err != nil {
+		return err
+	}
+	if err := validateRandomnessTestsConfig(&config.RandomnessTests); err != nil {
+		return err
+	}
+	if err := validateScoringWeights(&config.ScoringWeights); err != nil {
+		return err
+	}
+	if err := validateNISTConfig(&config.NIST); err != nil {
+		return err
+	}
+	if err := validateCryptanalysisConfig(&config.Cryptanalysis); err != nil {
+		return err
+	}
+	if err := validateKeyScheduleConfig(&config.KeySchedule); err != nil {
+		return err
+	}
+	return validatePairAnalysisConfig(&config.PairAnalysis)
+}
+
+// deprecatedStatisticalTests names the StatisticalTests fields that no
+// longer decide anything.
+var deprecatedStatisticalTests = []string{
+	"MaxPValue", "MaxBitFrequencyDeviation", "MinRunsZScore",
+	"MaxRunsZScore", "MaxSerialCorrelation", "MinLinearComplexity",
+}
+
+// deprecatedSettings returns the paths of the deprecated settings that
+// config sets to something other than their defaults.
+func deprecatedSettings(config *Config) []string {
+	var paths []string
+	set := reflect.ValueOf(config.StatisticalTests)
+	defaults := reflect.ValueOf(defaultStatisticalTests)
+	for _, name := range deprecatedStatisticalTests {
+		if !set.FieldByName(name).Equal(defaults.FieldByName(name)) {
+			paths = append(paths, "StatisticalTests."+name)
+		}
+	}
+	return paths
+}
+
+func validateStatisticalTestsConfig(s *StatisticalTestsConfig, wordSize int) error {
+	if s.MinPValue < 0 || s.MinPValue >= s.MaxPValue || s.MaxPValue > 1 {
+		return fmt.Errorf("StatisticalTests: need 0 <= MinPValue < MaxPValue <= 1")
+	}
+	// Entropy is measured in bits per bit, so it never exceeds 1
+	if s.MinEntropyScore < 0 || s.MinEntropyScore > s.MaxEntropyScore || s.MaxEntropyScore > 1 {
+		return fmt.Errorf("StatisticalTests: need 0 <= MinEntropyScore <= MaxEntropyScore <= 1")
+	}
+	if s.MaxBitFrequencyDeviation < 0 || s.MaxBitFrequencyDeviation > 0.5 {
+		return fmt.Errorf("StatisticalTests: MaxBitFrequencyDeviation must be between 0 and 0.5")
+	}
+	if s.MinRunsZScore >= s.MaxRunsZScore {
+		return fmt.Errorf("StatisticalTests: MinRunsZScore must be below MaxRunsZScore")
+	}
+	if s.MaxSerialCorrelation < 0 || s.MaxSerialCorrelation > 1 {
+		return fmt.Errorf("StatisticalTests: MaxSerialCorrelation must be between 0 and 1")
+	}
+	if s.MinLinearComplexity < 0 || s.MinLinearComplexity > wordSize {
+		return fmt.Errorf("StatisticalTests: MinLinearComplexity must be between 0 and WordSize")
+	}
 	return nil
 }
 
-func LoadConfig(path string) (Config, error) {
-	config := DefaultConfig()
+func validateValidationCriteria(v *ValidationCriteria, wordSize int) error {
+	for _, field := range []struct {
+		name  string
+		value int
+	}{
+		{"MinHammingWeight", v.MinHammingWeight},
+		{"MaxHammingWeight", v.MaxHammingWeight},
+		{"MinHammingDistance", v.MinHammingDistance},
+		{"MinDifferentBits", v.MinDifferentBits},
+	} {
+		if field.value < 0 || field.value > wordSize {
+			return fmt.Errorf("ValidationCriteria: %s must be between 0 and WordSize", field.name)
+		}
+	}
+	if v.MaxHammingWeight != 0 && v.MinHammingWeight > v.MaxHammingWeight {
+		return fmt.Errorf("ValidationCriteria: MinHammingWeight must not exceed MaxHammingWeight")
+	}
+	if v.MaxFailedTestFraction < 0 || v.MaxFailedTestFraction > 1 {
+		return fmt.Errorf("ValidationCriteria: MaxFailedTestFraction must be between 0 and 1")
+	}
+	return validateTestAggregation(v.TestAggregation)
+}
+
+func validateRandomnessTestsConfig(r *RandomnessTestsConfig) error {
+	weights := []float64{
+		r.RunsTestWeight,
+		r.FrequencyTestWeight,
+		r.SerialTestWeight,
+		r.AutocorrelationWeight,
+		r.LinearComplexityWeight,
+	}
+	total := 0.0
+	for _, weight := range weights {
+		if weight < 0 {
+			return fmt.Errorf("RandomnessTests: weights must not be negative")
+		}
+		total += weight
+	}
+	if total == 0 {
+		return fmt.Errorf("RandomnessTests: at least one weight must be positive")
+	}
+	return validateTestNames(r)
+}
+
+func validateScoringWeights(w *ScoringWeights) error {
+	if w.BitDistribution < 0 || w.Avalanche < 0 || w.Entropy < 0 {
+		return fmt.Errorf("ScoringWeights: weights must not be negative")
+	}
+	if w.BitDistribution+w.Avalanche+w.Entropy == 0 {
+		return fmt.Errorf("ScoringWeights: at least one weight must be positive")
+	}
+	return nil
+}
+
+// minNISTStreamBits is the shortest keystream every test of the battery
+// except the rank, universal and random excursions tests accepts.
+const minNISTStreamBits = 1 << 10
+
+// validateNISTConfig checks the battery settings when it is enabled; results
+// saved before it existed have an empty section.
+func validateNISTConfig(n *NISTConfig) error {
+	if !n.Enabled {
+		return nil
+	}
+	if n.Sequences < 1 {
+		return fmt.Errorf("NIST: Sequences must be positive")
+	}
+	if n.StreamBits < minNISTStreamBits {
+		return fmt.Errorf("NIST: StreamBits must be at least %d", minNISTStreamBits)
+	}
+	if n.Alpha <= 0 || n.Alpha >= 1 {
+		return fmt.Errorf("NIST: Alpha must be between 0 and 1")
+	}
+	if n.MinUniformityKS < 0 || n.MinUniformityKS >= 1 {
+		return fmt.Errorf("NIST: MinUniformityKS must be at least 0 and below 1")
+	}
+	return nil
+}
+
+func validateCryptanalysisConfig(c *CryptanalysisConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Bases < 1 {
+		return fmt.Errorf("Cryptanalysis: Bases must be positive")
+	}
+	if c.MaxDifferentialProbability < 0 || c.MaxDifferentialProbability > 1 {
+		return fmt.Errorf("Cryptanalysis: MaxDifferentialProbability must be between 0 and 1")
+	}
+	if c.MaxLinearBias < 0 || c.MaxLinearBias > 0.5 {
+		return fmt.Errorf("Cryptanalysis: MaxLinearBias must be between 0 and 0.5")
+	}
+	return nil
+}
+
+// maxEquivalentKeyBits bounds the exhaustive equivalent-key search.
+const maxEquivalentKeyBits = 24
 
-	if path == "" {
-		return config, ValidateConfig(&config)
+func validateKeyScheduleConfig(k *KeyScheduleConfig) error {
+	if !k.Enabled {
+		return nil
+	}
+	if k.Keys < 1 {
+		return fmt.Errorf("KeySchedule: Keys must be positive")
 	}
+	if k.EquivalentKeyBits < 1 || k.EquivalentKeyBits > maxEquivalentKeyBits {
+		return fmt.Errorf("KeySchedule: EquivalentKeyBits must be between 1 and %d", maxEquivalentKeyBits)
+	}
+	return nil
+}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return config, fmt.Errorf("reading config: %w", err)
+func validatePairAnalysisConfig(p *PairAnalysisConfig) error {
+	if !p.Enabled {
+		return nil
 	}
+	if p.MaxCorrelation < 0 || p.MaxCorrelation > 1 {
+		return fmt.Errorf("PairAnalysis: MaxCorrelation must be between 0 and 1")
+	}
+	if p.MaxOutputBias < 0 || p.MaxOutputBias > 0.5 {
+		return fmt.Errorf("PairAnalysis: MaxOutputBias must be between 0 and 0.5")
+	}
+	return nil
+}
 
-	if err := json.Unmarshal(data, &config); err != nil {
-		return config, fmt.Errorf("parsing config: %w", err)
+func validateDerivationConfig(d *DerivationConfig) error {
+	if len(d.Sources) == 0 || len(d.Rules) == 0 || len(d.Offsets) == 0 {
+		return fmt.Errorf("derivation needs at least one source, rule and offset")
+	}
+	for _, source := range d.Sources {
+		if _, err := expansion.Lookup(source); err != nil {
+			return err
+		}
+	}
+	for _, rule := range d.Rules {
+		switch rule {
+		case RuleWindow, RuleOdd, RuleNextPrime:
+		default:
+			return fmt.Errorf("unknown derivation rule: %q", rule)
+		}
 	}
+	for _, offset := range d.Offsets {
+		if offset < 0 {
+			return fmt.Errorf("derivation offsets must not be negative")
+		}
+	}
+	return nil
+}
 
-	return config, ValidateConfig(&config)
+// LoadConfig reads a JSON, YAML or TOML configuration file, chosen by
+// extension, over the defaults. An empty path gives the defaults.
+func LoadConfig(path string) (Config, error) {
+	config, _, err := LoadConfigLayers(ConfigLayers{Path: path})
+	return config, err
+}
+
+// validateConstantNames checks the names of the constants to select. An
+// empty list selects the default P and Q.
+func validateConstantNames(names []string) error {
+	if len(names) == 1 {
+		return fmt.Errorf("ConstantNames must name at least two constants")
+	}
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("ConstantNames must not contain empty names")
+		}
+		if seen[name] {
+			return fmt.Errorf("ConstantNames contains %q twice", name)
+		}
+		seen[name] = true
+	}
+	return nil
 }
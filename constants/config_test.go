@@ -2,8 +2,10 @@ package constants
 
 import (
 	"encoding/json"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -16,6 +18,8 @@ func TestDefaultConfig(t *testing.T) {
 		got      interface{}
 		expected interface{}
 	}{
+		{"WordSize", config.WordSize, 32},
+		{"Seed", config.Seed, ""},
 		{"NumCandidates", config.NumCandidates, 1000},
 		{"AvalancheTestCases", config.AvalancheTestCases, 10000},
 		{"MinPrimeAttempts", config.MinPrimeAttempts, 100},
@@ -27,6 +31,29 @@ func TestDefaultConfig(t *testing.T) {
 		{"ResultsFile", config.ResultsFile, "rc6_constants.json"},
 		{"DetailedLogging", config.DetailedLogging, true},
 		{"StatisticalAnalysis", config.StatisticalAnalysis, true},
+		{"Transform", config.Transform, TransformRC6},
+		{"CipherRounds", config.CipherRounds, 20},
+		{"ReducedRounds", config.ReducedRounds, 4},
+		{"KeyBytes", config.KeyBytes, 16},
+		{"GenerationMode", config.GenerationMode, ModeRandom},
+		{"RequirePrime", config.RequirePrime, true},
+		{"PrimeClass", config.PrimeClass, PrimeAny},
+		{"TimeoutSeconds", config.TimeoutSeconds, 1800},
+		{"BatchSize", config.BatchSize, 1},
+		{"Alternates", config.Alternates, 5},
+		{"MinEntropyScore", config.StatisticalTests.MinEntropyScore, 0.9},
+		{"MaxBitFrequencyDeviation", config.StatisticalTests.MaxBitFrequencyDeviation, 0.15},
+		{"MinHammingWeight", config.ValidationCriteria.MinHammingWeight, 0},
+		{"MaxFailedTestFraction", config.ValidationCriteria.MaxFailedTestFraction, 0.2},
+		{"EnableExtendedTests", config.RandomnessTests.EnableExtendedTests, true},
+		{"AvalancheWeight", config.ScoringWeights.Avalanche, 2.0},
+		{"NIST.Enabled", config.NIST.Enabled, false},
+		{"NIST.StreamBits", config.NIST.StreamBits, 1 << 20},
+		{"NIST.Alpha", config.NIST.Alpha, 0.01},
+		{"NIST.MinUniformityKS", config.NIST.MinUniformityKS, 0.0001},
+		{"KeySchedule.Keys", config.KeySchedule.Keys, 32},
+		{"KeySchedule.EquivalentKeyBits", config.KeySchedule.EquivalentKeyBits, 12},
+		{"PairAnalysis.MaxCorrelation", config.PairAnalysis.MaxCorrelation, 0.75},
 	}
 
 	for _, tt := range tests {
@@ -36,6 +63,10 @@ func TestDefaultConfig(t *testing.T) {
 			}
 		})
 	}
+
+	if !reflect.DeepEqual(config.ConstantNames, []string{"P", "Q"}) {
+		t.Errorf("ConstantNames = %q; want [P Q]", config.ConstantNames)
+	}
 }
 
 func TestValidateConfig(t *testing.T) {
@@ -85,6 +116,367 @@ func TestValidateConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "SAC deviation above one half",
+			config: func() Config {
+				c := DefaultConfig()
+				c.MaxSACDeviation = 0.6
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "SAC p-value of one",
+			config: func() Config {
+				c := DefaultConfig()
+				c.MinSACPValue = 1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Negative BIC correlation",
+			config: func() Config {
+				c := DefaultConfig()
+				c.MaxBICCorrelation = -0.1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Cryptanalysis without bases",
+			config: func() Config {
+				c := DefaultConfig()
+				c.Cryptanalysis.Bases = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Linear bias above one half",
+			config: func() Config {
+				c := DefaultConfig()
+				c.Cryptanalysis.MaxLinearBias = 0.6
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Key schedule without keys",
+			config: func() Config {
+				c := DefaultConfig()
+				c.KeySchedule.Keys = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Equivalent-key search too large",
+			config: func() Config {
+				c := DefaultConfig()
+				c.KeySchedule.EquivalentKeyBits = 32
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Disabled key schedule section left empty",
+			config: func() Config {
+				c := DefaultConfig()
+				c.KeySchedule = KeyScheduleConfig{}
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "Pair correlation above one",
+			config: func() Config {
+				c := DefaultConfig()
+				c.PairAnalysis.MaxCorrelation = 1.5
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Pair output bias above one half",
+			config: func() Config {
+				c := DefaultConfig()
+				c.PairAnalysis.MaxOutputBias = 0.6
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Unsupported word size",
+			config: func() Config {
+				c := DefaultConfig()
+				c.WordSize = 24
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Unknown transform",
+			config: func() Config {
+				c := DefaultConfig()
+				c.Transform = "feistel"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Unknown generation mode",
+			config: func() Config {
+				c := DefaultConfig()
+				c.GenerationMode = "lottery"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Unknown derivation source",
+			config: func() Config {
+				c := DefaultConfig()
+				c.GenerationMode = ModeDerive
+				c.Derivation.Sources = []string{"e", "tau"}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Unknown derivation rule",
+			config: func() Config {
+				c := DefaultConfig()
+				c.GenerationMode = ModeDerive
+				c.Derivation.Rules = []string{"round"}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Valid derivation config",
+			config: func() Config {
+				c := DefaultConfig()
+				c.GenerationMode = ModeDerive
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "NaN threshold",
+			config: func() Config {
+				c := DefaultConfig()
+				c.MinAvalancheScore = math.NaN()
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Infinite nested threshold",
+			config: func() Config {
+				c := DefaultConfig()
+				c.PairAnalysis.MaxCorrelation = math.Inf(1)
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Unknown prime class",
+			config: func() Config {
+				c := DefaultConfig()
+				c.PrimeClass = "mersenne"
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Prime class without primes",
+			config: func() Config {
+				c := DefaultConfig()
+				c.PrimeClass = PrimeSafe
+				c.RequirePrime = false
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Valid prime class",
+			config: func() Config {
+				c := DefaultConfig()
+				c.PrimeClass = PrimeStrong
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "Entropy above one bit per bit",
+			config: func() Config {
+				c := DefaultConfig()
+				c.StatisticalTests.MinEntropyScore = 1.5
+				c.StatisticalTests.MaxEntropyScore = 2.0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Inverted runs Z-score range",
+			config: func() Config {
+				c := DefaultConfig()
+				c.StatisticalTests.MinRunsZScore = 2
+				c.StatisticalTests.MaxRunsZScore = -2
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Hamming weight beyond word size",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ValidationCriteria.MaxHammingWeight = 40
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Inverted Hamming weight range",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ValidationCriteria.MinHammingWeight = 20
+				c.ValidationCriteria.MaxHammingWeight = 12
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Negative test weight",
+			config: func() Config {
+				c := DefaultConfig()
+				c.RandomnessTests.SerialTestWeight = -1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "All scoring weights zero",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ScoringWeights = ScoringWeights{}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Negative timeout",
+			config: func() Config {
+				c := DefaultConfig()
+				c.TimeoutSeconds = -1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Zero batch size",
+			config: func() Config {
+				c := DefaultConfig()
+				c.BatchSize = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Eight named constants",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ConstantNames = []string{"K0", "K1", "K2", "K3", "K4", "K5", "K6", "K7"}
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "Single named constant",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ConstantNames = []string{"P"}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Duplicate constant name",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ConstantNames = []string{"P", "Q", "P"}
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Negative alternates",
+			config: func() Config {
+				c := DefaultConfig()
+				c.Alternates = -1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Disabled NIST section left empty",
+			config: func() Config {
+				c := DefaultConfig()
+				c.NIST = NISTConfig{}
+				return c
+			}(),
+			wantErr: false,
+		},
+		{
+			name: "NIST without sequences",
+			config: func() Config {
+				c := DefaultConfig()
+				c.NIST.Enabled = true
+				c.NIST.Sequences = 0
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "NIST stream too short",
+			config: func() Config {
+				c := DefaultConfig()
+				c.NIST.Enabled = true
+				c.NIST.StreamBits = 100
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "NIST alpha out of range",
+			config: func() Config {
+				c := DefaultConfig()
+				c.NIST.Enabled = true
+				c.NIST.Alpha = 1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "NIST Kolmogorov-Smirnov threshold out of range",
+			config: func() Config {
+				c := DefaultConfig()
+				c.NIST.Enabled = true
+				c.NIST.MinUniformityKS = -0.1
+				return c
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "Reduced rounds exceed cipher rounds",
+			config: func() Config {
+				c := DefaultConfig()
+				c.ReducedRounds = c.CipherRounds + 1
+				return c
+			}(),
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -115,7 +507,7 @@ func TestLoadConfig(t *testing.T) {
 			wantErr: false,
 			validate: func(t *testing.T, c Config) {
 				default_config := DefaultConfig()
-				if c != default_config {
+				if !reflect.DeepEqual(c, default_config) {
 					t.Errorf("Expected default config, got %+v", c)
 				}
 			},
@@ -137,6 +529,45 @@ func TestLoadConfig(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "Shipped config file",
+			setup: func() string {
+				return filepath.Join("..", "config.json")
+			},
+			wantErr: false,
+			validate: func(t *testing.T, c Config) {
+				if c.StatisticalTests.MinEntropyScore != 0.95 {
+					t.Errorf("Expected MinEntropyScore=0.95, got %v", c.StatisticalTests.MinEntropyScore)
+				}
+				if paths := deprecatedSettings(&c); len(paths) != 0 {
+					t.Errorf("Shipped config sets deprecated %v", paths)
+				}
+				if c.ValidationCriteria.MinHammingDistance != 0 {
+					t.Errorf("Expected MinHammingDistance=0, got %d", c.ValidationCriteria.MinHammingDistance)
+				}
+				if c.TimeoutSeconds != 3600 || c.BatchSize != 100 {
+					t.Errorf("Expected TimeoutSeconds=3600 and BatchSize=100, got %d and %d",
+						c.TimeoutSeconds, c.BatchSize)
+				}
+			},
+		},
+		{
+			name: "Partial section keeps other defaults",
+			setup: func() string {
+				path := filepath.Join(tmpDir, "partial_config.json")
+				os.WriteFile(path, []byte(`{"StatisticalTests": {"MaxRunsZScore": 2.5}}`), 0644)
+				return path
+			},
+			wantErr: false,
+			validate: func(t *testing.T, c Config) {
+				if c.StatisticalTests.MaxRunsZScore != 2.5 {
+					t.Errorf("Expected MaxRunsZScore=2.5, got %v", c.StatisticalTests.MaxRunsZScore)
+				}
+				if c.StatisticalTests.MinRunsZScore != -3.0 {
+					t.Errorf("Expected default MinRunsZScore=-3, got %v", c.StatisticalTests.MinRunsZScore)
+				}
+			},
+		},
 		{
 			name: "Invalid JSON file",
 			setup: func() string {
@@ -171,3 +602,24 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestShippedConfigWordSizes(t *testing.T) {
+	for _, w := range []int{16, 32, 64, 128} {
+		config, err := LoadConfig(filepath.Join("..", "config.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.WordSize = w
+		if err := ValidateConfig(&config); err != nil {
+			t.Errorf("WordSize %d: %v", w, err)
+			continue
+		}
+		g := NewGenerator(config)
+		if lo, hi := g.hammingWeightBounds(); lo != 3*w/8 || hi != 5*w/8 {
+			t.Errorf("WordSize %d: Hamming weight bounds [%d, %d], want [%d, %d]", w, lo, hi, 3*w/8, 5*w/8)
+		}
+		if d := g.minHammingDistance(); d != 3*w/8 {
+			t.Errorf("WordSize %d: minimum Hamming distance %d, want %d", w, d, 3*w/8)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package constants
+
+import (
+	"primer/cryptanalysis"
+	"primer/word"
+)
+
+// cryptanalysisSeed seeds the fixed input bits of the slices. They are the
+// same for every candidate and every run, so that the metrics of two
+// constants are comparable and a saved result can be re-checked.
+const cryptanalysisSeed = "primer cryptanalysis"
+
+// cryptanalysisBlocks is the number of random blocks per base over which
+// the round of RC6 is sampled. The round rotates by amounts taken from the
+// data, so the slices at a single block would fix the rotations and give
+// differentials of probability one for every constant.
+const cryptanalysisBlocks = 8
+
+// analyzeTransform returns the largest differential probability and linear
+// bias of the configured transform keyed with constant, over 8-bit slices.
+// A failure is logged and leaves both at zero.
+func (g *Generator) analyzeTransform(constant word.Word) (maxDifferential, maxBias float64) {
+	if g.config.Transform == TransformSimple {
+		return g.analyzeSimpleTransform(constant)
+	}
+	maxDifferential, maxBias, err := g.analyzeRC6Round(constant)
+	if err != nil {
+		g.logger.Error("Cryptanalysis failed:", err)
+		return 0, 0
+	}
+	return maxDifferential, maxBias
+}
+
+// analyzeSimpleTransform slices the simple transform itself: input byte s
+// with the other bytes fixed to one of Bases values, output byte t.
+//
+// Only slices whose output byte lies wholly above the varied input bits
+// between the two rotations are measured. The lowest varied bit passes the
+// multiplication with probability one and the bits below it not at all,
+// for every odd multiplier, so the other slices say nothing about the
+// constant. 16-bit words have no such slice, and both metrics stay zero.
+func (g *Generator) analyzeSimpleTransform(constant word.Word) (maxDifferential, maxBias float64) {
+	w := g.wordSize()
+	transform := func(x word.Word) word.Word { return g.rc6Transform(x, constant) }
+
+	for _, base := range seededWords(cryptanalysisSeed, uint64(w), w, g.config.Cryptanalysis.Bases) {
+		for in := uint(0); in < w/8; in++ {
+			// Highest bit of the product the input byte reaches
+			top := 8*in + 7 + simpleRotateIn
+			if top >= w {
+				continue
+			}
+			for out := uint(0); out < w/8; out++ {
+				// Lowest bit of the product in the output byte
+				if 8*out < simpleRotateOut || 8*out-simpleRotateOut <= top {
+					continue
+				}
+				s := cryptanalysis.Slice(transform, w, base, in, out)
+				dp, _, _ := cryptanalysis.MaxDifferential(cryptanalysis.DDT(s))
+				bias, _, _ := cryptanalysis.MaxLinearBias(cryptanalysis.LAT(s))
+				maxDifferential = max(maxDifferential, dp)
+				maxBias = max(maxBias, bias)
+			}
+		}
+	}
+	return maxDifferential, maxBias
+}
+
+// analyzeRC6Round estimates the properties of one round of RC6 keyed with
+// constant as P and as Q under the all-zero key. The low byte of B or of D,
+// the words the round passes through its quadratic function, varies, and
+// the low byte of the two words the round computes is observed. Every slice is
+// sampled over cryptanalysisBlocks·Bases random blocks. Higher bytes of B
+// and D leave the bits of the quadratic function below them unchanged, so
+// whole output bytes would keep their value for every constant.
+func (g *Generator) analyzeRC6Round(constant word.Word) (maxDifferential, maxBias float64, err error) {
+	w := g.wordSize()
+	ciphers, err := g.roleCiphers(constant, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	blocks := cryptanalysisBlocks * g.config.Cryptanalysis.Bases
+	words := seededWords(cryptanalysisSeed, uint64(w), w, 4*blocks)
+	// B and D in, and the new B and D out: one round moves the A and C it
+	// computes into those positions
+	inputs, outputs := []int{1, 3}, []int{1, 3}
+
+	for _, c := range ciphers {
+		for _, in := range inputs {
+			for _, out := range outputs {
+				var sample cryptanalysis.Sample
+				for k := 0; k < blocks; k++ {
+					block := [4]word.Word(words[4*k : 4*k+4])
+					round := func(x word.Word) word.Word {
+						v := block
+						v[in] = x
+						return encryptBlock(c.cipher, v)[out]
+					}
+					sample.Add(cryptanalysis.Slice(round, w, block[in], 0, 0))
+				}
+				dp, _, _ := sample.MaxDifferential()
+				bias, _, _ := sample.MaxLinearBias()
+				maxDifferential = max(maxDifferential, dp)
+				maxBias = max(maxBias, bias)
+			}
+		}
+	}
+	return maxDifferential, maxBias, nil
+}
+
+// cryptanalysisReason returns the rejection reason for the first
+// cryptanalysis limit a candidate misses, or "" if it meets them all.
+func (g *Generator) cryptanalysisReason(candidate ConstantCandidate) string {
+	config := g.config.Cryptanalysis
+	if !config.Enabled {
+		return ""
+	}
+	if config.MaxDifferentialProbability > 0 && candidate.MaxDifferentialProbability > config.MaxDifferentialProbability {
+		return RejectDifferential
+	}
+	if config.MaxLinearBias > 0 && candidate.MaxLinearBias > config.MaxLinearBias {
+		return RejectLinear
+	}
+	return ""
+}
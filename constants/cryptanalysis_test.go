@@ -0,0 +1,80 @@
+package constants
+
+import (
+	"testing"
+
+	"primer/word"
+)
+
+func TestAnalyzeTransform(t *testing.T) {
+	tests := []struct {
+		name  string
+		value word.Word
+		// The worst differential and linear approximation of the simple
+		// transform hold with probability one
+		weak bool
+	}{
+		{"Golden ratio", word.FromUint64(0x9E3779B9), false},
+		{"RC6 Q", RC6_Q, false},
+		{"Sparse", word.FromUint64(0x00010001), true},
+		{"Three", word.FromUint64(3), true},
+		{"All ones", word.FromUint64(0xFFFFFFFF), true},
+	}
+	for _, transform := range []string{TransformSimple, TransformRC6} {
+		config := DefaultConfig()
+		config.Transform = transform
+		g := NewGenerator(config)
+		for _, tt := range tests {
+			t.Run(transform+"/"+tt.name, func(t *testing.T) {
+				dp, bias := g.analyzeTransform(tt.value)
+				if dp <= 0 || dp > 1 || bias <= 0 || bias > 0.5 {
+					t.Fatalf("differential %v, bias %v out of range", dp, bias)
+				}
+				// The key schedule hides the structure of the constant
+				// from the round of RC6
+				weak := tt.weak && transform == TransformSimple
+				if got := dp == 1 && bias == 0.5; got != weak {
+					t.Errorf("differential %v, bias %v, want weak = %v", dp, bias, weak)
+				}
+			})
+		}
+
+		// The bases are fixed, so the metrics are reproducible
+		dp1, bias1 := g.analyzeTransform(RC6_P)
+		dp2, bias2 := NewGenerator(config).analyzeTransform(RC6_P)
+		if dp1 != dp2 || bias1 != bias2 {
+			t.Errorf("%s: metrics differ between generators: %v, %v and %v, %v", transform, dp1, bias1, dp2, bias2)
+		}
+	}
+}
+
+func TestAnalyzeRC6RoundUsesConstant(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	dp1, bias1, err := g.analyzeRC6Round(RC6_P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dp2, bias2, err := g.analyzeRC6Round(word.FromUint64(0x12345677))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dp1 == dp2 && bias1 == bias2 {
+		t.Errorf("both constants give differential %v, bias %v", dp1, bias1)
+	}
+}
+
+func TestCryptanalysisDisabled(t *testing.T) {
+	config := DefaultConfig()
+	config.AvalancheTestCases = 10
+	config.Cryptanalysis.Enabled = false
+	config.Cryptanalysis.MaxLinearBias = 0.1
+	g := NewGenerator(config)
+
+	c := g.AnalyzeConstant(word.FromUint64(0x00010001))
+	if c.MaxDifferentialProbability != 0 || c.MaxLinearBias != 0 {
+		t.Errorf("metrics %v, %v with cryptanalysis disabled", c.MaxDifferentialProbability, c.MaxLinearBias)
+	}
+	if reason := g.cryptanalysisReason(c); reason != "" {
+		t.Errorf("rejected by %q with cryptanalysis disabled", reason)
+	}
+}
@@ -0,0 +1,129 @@
+package constants
+
+import (
+	"fmt"
+
+	"primer/expansion"
+	"primer/word"
+)
+
+// Generation modes.
+const (
+	// ModeRandom draws random primes.
+	ModeRandom = "random"
+	// ModeDerive derives nothing-up-my-sleeve candidates from the binary
+	// expansions of well-known irrational constants.
+	ModeDerive = "derive"
+)
+
+// Derivation rules applied to a window of an expansion.
+const (
+	// RuleWindow takes the window as is.
+	RuleWindow = "window"
+	// RuleOdd sets the lowest bit, giving the odd integer nearest to the
+	// scaled fraction as in Pw = Odd((e-2)*2^w).
+	RuleOdd = "odd"
+	// RuleNextPrime takes the smallest prime not below the window.
+	RuleNextPrime = "nextprime"
+)
+
+// derivationSpecs enumerates every (source, rule, offset) combination of
+// the derivation config in a fixed order; candidate i uses spec i.
+func (g *Generator) derivationSpecs() []Derivation {
+	g.derivationsOnce.Do(func() {
+		d := g.config.Derivation
+		for _, source := range d.Sources {
+			for _, rule := range d.Rules {
+				for _, offset := range d.Offsets {
+					g.derivations = append(g.derivations, Derivation{
+						Source: source,
+						Rule:   rule,
+						Offset: offset,
+					})
+				}
+			}
+		}
+	})
+	return g.derivations
+}
+
+// candidateCount returns the number of candidates a run will evaluate.
+func (g *Generator) candidateCount() int {
+	if g.config.GenerationMode == ModeDerive {
+		return len(g.derivationSpecs())
+	}
+	return g.config.NumCandidates
+}
+
+// deriveValue computes the w-bit constant described by spec and fills in its
+// formula.
+func (g *Generator) deriveValue(spec Derivation) (word.Word, Derivation, error) {
+	c, err := expansion.Lookup(spec.Source)
+	if err != nil {
+		return word.Word{}, spec, err
+	}
+	if spec.Offset < 0 {
+		return word.Word{}, spec, fmt.Errorf("negative derivation offset: %d", spec.Offset)
+	}
+
+	w := g.wordSize()
+	value := word.FromBig(c.Window(uint(spec.Offset), w))
+
+	switch spec.Rule {
+	case RuleWindow:
+	case RuleOdd:
+		value.Lo |= 1
+	case RuleNextPrime:
+		value, err = g.nextPrime(value)
+		if err != nil {
+			return word.Word{}, spec, fmt.Errorf("%s: %w", spec.Source, err)
+		}
+	default:
+		return word.Word{}, spec, fmt.Errorf("unknown derivation rule: %q", spec.Rule)
+	}
+
+	spec.Formula = derivationFormula(c, spec.Rule, uint(spec.Offset), w)
+	return value, spec, nil
+}
+
+// nextPrime returns the smallest prime >= n that fits in a word.
+func (g *Generator) nextPrime(n word.Word) (word.Word, error) {
+	w := g.wordSize()
+	two := word.FromUint64(2)
+	if n.Cmp(two) <= 0 {
+		return two, nil
+	}
+	n.Lo |= 1
+	for !g.isPrime(n) {
+		next := n.Add(two, w)
+		if next.Cmp(n) < 0 {
+			return word.Word{}, fmt.Errorf("no prime above %v fits in %d bits", n, w)
+		}
+		n = next
+	}
+	return n, nil
+}
+
+// derivationFormula renders a derivation, e.g. Odd((e-2)·2^32) or
+// NextPrime(⌊(π-3)·2^96⌋ mod 2^64).
+func derivationFormula(c expansion.Constant, rule string, offset, w uint) string {
+	frac := c.FractionSymbol()
+
+	var window string
+	if offset == 0 {
+		window = fmt.Sprintf("%s·2^%d", frac, w)
+	} else {
+		window = fmt.Sprintf("⌊%s·2^%d⌋ mod 2^%d", frac, offset+w, w)
+	}
+
+	switch rule {
+	case RuleOdd:
+		return "Odd(" + window + ")"
+	case RuleNextPrime:
+		return "NextPrime(" + window + ")"
+	}
+	if offset == 0 {
+		return "⌊" + window + "⌋"
+	}
+	return window
+}
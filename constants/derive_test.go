@@ -0,0 +1,124 @@
+package constants
+
+import (
+	"fmt"
+	"testing"
+
+	"primer/rc6"
+	"primer/word"
+)
+
+func TestDeriveMagicConstants(t *testing.T) {
+	for _, w := range []int{16, 32, 64, 128} {
+		config := DefaultConfig()
+		config.WordSize = w
+		g := NewGenerator(config)
+
+		wantP, wantQ, _ := rc6.MagicConstants(uint(w))
+
+		p, dp, err := g.deriveValue(Derivation{Source: "e", Rule: RuleOdd})
+		if err != nil {
+			t.Fatalf("WordSize %d: deriveValue(e) error = %v", w, err)
+		}
+		if p != wantP {
+			t.Errorf("WordSize %d: Odd((e-2)·2^w) = %v, want %v", w, p, wantP)
+		}
+		if want := fmt.Sprintf("Odd((e-2)·2^%d)", w); dp.Formula != want {
+			t.Errorf("WordSize %d: formula = %q, want %q", w, dp.Formula, want)
+		}
+
+		q, _, err := g.deriveValue(Derivation{Source: "phi", Rule: RuleOdd})
+		if err != nil {
+			t.Fatalf("WordSize %d: deriveValue(phi) error = %v", w, err)
+		}
+		if q != wantQ {
+			t.Errorf("WordSize %d: Odd((φ-1)·2^w) = %v, want %v", w, q, wantQ)
+		}
+	}
+}
+
+func TestDeriveRules(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+
+	window, d, err := g.deriveValue(Derivation{Source: "pi", Rule: RuleWindow})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if window != word.FromUint64(0x243F6A88) {
+		t.Errorf("⌊(π-3)·2^32⌋ = %v, want 0x243F6A88", window)
+	}
+	if d.Formula != "⌊(π-3)·2^32⌋" {
+		t.Errorf("formula = %q", d.Formula)
+	}
+
+	offset, d, err := g.deriveValue(Derivation{Source: "pi", Rule: RuleWindow, Offset: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset != word.FromUint64(0x85A308D3) {
+		t.Errorf("window at offset 32 = %v, want 0x85A308D3", offset)
+	}
+	if d.Formula != "⌊(π-3)·2^64⌋ mod 2^32" {
+		t.Errorf("formula = %q", d.Formula)
+	}
+
+	prime, d, err := g.deriveValue(Derivation{Source: "e", Rule: RuleNextPrime})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.isPrime(prime) || prime.Cmp(word.FromUint64(0xB7E15162)) < 0 {
+		t.Errorf("NextPrime((e-2)·2^32) = %v is not a prime >= 0xB7E15162", prime)
+	}
+	for v := uint64(0xB7E15162); v < prime.Lo; v++ {
+		if g.isPrime(word.FromUint64(v)) {
+			t.Errorf("NextPrime skipped prime 0x%X", v)
+		}
+	}
+	if d.Formula != "NextPrime((e-2)·2^32)" {
+		t.Errorf("formula = %q", d.Formula)
+	}
+
+	if _, _, err := g.deriveValue(Derivation{Source: "e", Rule: "round"}); err == nil {
+		t.Error("deriveValue() accepted an unknown rule")
+	}
+	if _, _, err := g.deriveValue(Derivation{Source: "tau", Rule: RuleOdd}); err == nil {
+		t.Error("deriveValue() accepted an unknown source")
+	}
+}
+
+func TestDerivedCandidates(t *testing.T) {
+	config := DefaultConfig()
+	config.GenerationMode = ModeDerive
+	config.AvalancheTestCases = 50
+	config.Derivation = DerivationConfig{
+		Sources: []string{"e", "phi"},
+		Rules:   []string{RuleOdd, RuleNextPrime},
+		Offsets: []int{0, 32},
+	}
+	g := NewGenerator(config)
+
+	if got := g.candidateCount(); got != 8 {
+		t.Fatalf("candidateCount() = %d, want 8", got)
+	}
+
+	c, err := g.generateCandidate(0)
+	if err != nil {
+		t.Fatalf("generateCandidate(0) error = %v", err)
+	}
+	if c.Value != word.FromUint64(0xB7E15163) {
+		t.Errorf("candidate 0 = %v, want 0xB7E15163", c.Value)
+	}
+	if c.Derivation == nil || c.Derivation.Formula != "Odd((e-2)·2^32)" {
+		t.Errorf("candidate 0 derivation = %+v", c.Derivation)
+	}
+
+	// 0xB7E15163 is divisible by 3, so it only passes when primes are not
+	// required
+	if g.validateCandidate(c) {
+		t.Error("non-prime derived candidate passed validation with RequirePrime")
+	}
+	g.config.RequirePrime = false
+	if !g.validateCandidate(c) {
+		t.Error("derived candidate rejected without RequirePrime")
+	}
+}
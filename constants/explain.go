@@ -0,0 +1,247 @@
+package constants
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"primer/expansion"
+	"primer/word"
+)
+
+// Defaults for the provenance search.
+const (
+	DefaultExplainMaxOffset = 256
+	DefaultExplainMaxDelta  = 16
+)
+
+// explainTransform maps a derived value to the value under review. The
+// search applies the inverse to the reviewed value and matches the result
+// against expansion windows.
+type explainTransform struct {
+	name    string
+	cost    float64
+	inverse func(word.Word) word.Word
+	render  func(formula string) string
+}
+
+// explainTransforms returns the identity, the complement and every rotation
+// for w-bit words.
+func explainTransforms(w uint) []explainTransform {
+	transforms := []explainTransform{
+		{
+			name:    "",
+			inverse: func(x word.Word) word.Word { return x },
+			render:  func(f string) string { return f },
+		},
+		{
+			name:    "complement",
+			cost:    1,
+			inverse: func(x word.Word) word.Word { return x.Not(w) },
+			render:  func(f string) string { return "¬" + f },
+		},
+	}
+	for r := uint(1); r < w; r++ {
+		r := r
+		transforms = append(transforms, explainTransform{
+			name:    fmt.Sprintf("rotl %d", r),
+			cost:    1 + math.Log2(float64(w-1)),
+			inverse: func(x word.Word) word.Word { return x.RotateRight(r, w) },
+			render:  func(f string) string { return fmt.Sprintf("ROTL(%s, %d)", f, r) },
+		})
+	}
+	return transforms
+}
+
+// Description lengths in bits of the rules the search tries, on top of the
+// source, offset and transform.
+const (
+	explainWindowCost    = 0
+	explainOddCost       = 1
+	explainNextPrimeCost = 2
+)
+
+// deltaCost returns the description length of adding delta to a window.
+func deltaCost(delta int64) float64 {
+	return 1 + 2*math.Log2(math.Abs(float64(delta))+1)
+}
+
+// ruleCosts returns the description length of every value the search
+// derives from one window: the window itself, its odd form, the next prime
+// and each window ± delta up to maxDelta.
+func ruleCosts(maxDelta int) []float64 {
+	costs := []float64{explainWindowCost, explainOddCost, explainNextPrimeCost}
+	for d := int64(1); d <= int64(maxDelta); d++ {
+		costs = append(costs, deltaCost(d), deltaCost(-d))
+	}
+	return costs
+}
+
+// Explain searches the expansions of well-known constants, under simple
+// rules and transforms, for derivations of value. Matches are ranked by
+// description length in bits; the rigidity score compares the simplest
+// description with the word size, so 1 means fully explained by a short
+// formula and 0 means no derivation was found. SearchSpace counts every
+// derivation tried and ChanceMatch is the chance that a random value has
+// a derivation at most as long as the best one.
+func (g *Generator) Explain(value word.Word, opts ExplainOptions) Explanation {
+	w := g.wordSize()
+	value = value.Truncate(w)
+	if opts.MaxOffset < 0 {
+		opts.MaxOffset = 0
+	}
+	if opts.MaxDelta < 0 {
+		opts.MaxDelta = 0
+	}
+
+	constants := expansion.All()
+	sourceCost := math.Log2(float64(len(constants)))
+	transforms := explainTransforms(w)
+
+	// The smallest prime above each window is needed for NextPrime matches;
+	// compute the largest prime below each prime target once.
+	type target struct {
+		transform explainTransform
+		value     word.Word
+		prime     bool
+		prevPrime word.Word
+	}
+	targets := make([]target, len(transforms))
+	for i, t := range transforms {
+		v := t.inverse(value)
+		targets[i] = target{transform: t, value: v, prime: g.isPrime(v)}
+		if targets[i].prime {
+			targets[i].prevPrime = g.prevPrime(v)
+		}
+	}
+
+	var matches []ProvenanceMatch
+	for _, c := range constants {
+		for offset := 0; offset <= opts.MaxOffset; offset++ {
+			window := word.FromBig(c.Window(uint(offset), w))
+			offsetCost := explainOffsetCost(offset)
+
+			for _, t := range targets {
+				add := func(rule string, ruleCost float64, delta int64) {
+					formula := derivationFormula(c, rule, uint(offset), w)
+					cost := sourceCost + offsetCost + ruleCost + t.transform.cost
+					transform := t.transform.name
+					if delta != 0 {
+						formula = fmt.Sprintf("%s %+d", formula, delta)
+						cost += deltaCost(delta)
+						transform = fmt.Sprintf("%+d", delta)
+						if t.transform.name != "" {
+							transform = t.transform.name + ", " + transform
+						}
+					}
+					matches = append(matches, ProvenanceMatch{
+						Source:     c.Name,
+						Rule:       rule,
+						Offset:     offset,
+						Transform:  transform,
+						Formula:    t.transform.render(formula),
+						Complexity: cost,
+					})
+				}
+
+				odd := window
+				odd.Lo |= 1
+				switch {
+				case window == t.value:
+					add(RuleWindow, explainWindowCost, 0)
+				case odd == t.value:
+					add(RuleOdd, explainOddCost, 0)
+				default:
+					if delta, ok := smallDelta(t.value, window, w, opts.MaxDelta); ok {
+						add(RuleWindow, explainWindowCost, delta)
+					}
+				}
+				if t.prime && window.Cmp(t.value) <= 0 && window.Cmp(t.prevPrime) > 0 && window != t.value {
+					add(RuleNextPrime, explainNextPrimeCost, 0)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Complexity < matches[j].Complexity
+	})
+
+	// Every derivation names one value, which a random value equals with
+	// chance 2^-w. The slack absorbs rounding in the sums of costs.
+	rules := ruleCosts(opts.MaxDelta)
+	count := func(budget float64) int {
+		n := 0
+		for offset := 0; offset <= opts.MaxOffset; offset++ {
+			for _, t := range transforms {
+				for _, rule := range rules {
+					if sourceCost+explainOffsetCost(offset)+t.cost+rule <= budget+1e-9 {
+						n += len(constants)
+					}
+				}
+			}
+		}
+		return n
+	}
+
+	explanation := Explanation{
+		Value:       value,
+		WordSize:    int(w),
+		Matches:     matches,
+		SearchSpace: len(constants) * (opts.MaxOffset + 1) * len(transforms) * len(rules),
+		Analysis:    g.AnalyzeConstant(value),
+	}
+	if len(matches) > 0 {
+		best := matches[0]
+		explanation.Best = &best
+		explanation.Rigidity = math.Max(0, 1-best.Complexity/float64(w))
+		explanation.ChanceMatch = math.Min(1, float64(count(best.Complexity))/math.Pow(2, float64(w)))
+	}
+	return explanation
+}
+
+// explainOffsetCost returns the description length of a window offset.
+func explainOffsetCost(offset int) float64 {
+	return 2 * math.Log2(float64(offset+1))
+}
+
+// smallDelta reports whether value - window, read as a signed w-bit
+// difference, is non-zero and at most maxDelta in magnitude.
+func smallDelta(value, window word.Word, w uint, maxDelta int) (int64, bool) {
+	if maxDelta == 0 {
+		return 0, false
+	}
+	up := value.Sub(window, w)
+	if up.Hi == 0 && up.Lo != 0 && up.Lo <= uint64(maxDelta) {
+		return int64(up.Lo), true
+	}
+	down := window.Sub(value, w)
+	if down.Hi == 0 && down.Lo != 0 && down.Lo <= uint64(maxDelta) {
+		return -int64(down.Lo), true
+	}
+	return 0, false
+}
+
+// prevPrime returns the largest prime below n, or zero if there is none.
+func (g *Generator) prevPrime(n word.Word) word.Word {
+	w := g.wordSize()
+	one := word.FromUint64(1)
+	for n.Cmp(word.FromUint64(2)) > 0 {
+		n = n.Sub(one, w)
+		if g.isPrime(n) {
+			return n
+		}
+	}
+	return word.Word{}
+}
+
+// InferWordSize returns the smallest supported word size that holds value.
+func InferWordSize(value word.Word) int {
+	n := value.BitLen()
+	for _, w := range []int{16, 32, 64, 128} {
+		if n <= w {
+			return w
+		}
+	}
+	return 128
+}
@@ -0,0 +1,132 @@
+package constants
+
+import (
+	"strings"
+	"testing"
+
+	"primer/expansion"
+	"primer/word"
+)
+
+func explainGenerator(w int) *Generator {
+	config := DefaultConfig()
+	config.WordSize = w
+	config.AvalancheTestCases = 20
+	config.DetailedLogging = false
+	return NewGenerator(config)
+}
+
+func TestExplainKnownConstants(t *testing.T) {
+	opts := ExplainOptions{MaxOffset: 64, MaxDelta: DefaultExplainMaxDelta}
+
+	tests := []struct {
+		name    string
+		w       int
+		value   word.Word
+		formula string
+		source  string
+	}{
+		{"RC6 P32", 32, word.FromUint64(0xB7E15163), "Odd((e-2)·2^32)", "e"},
+		{"RC6 Q32", 32, word.FromUint64(0x9E3779B9), "⌊(φ-1)·2^32⌋", "phi"},
+		{"RC5 P16", 16, word.FromUint64(0xB7E1), "⌊(e-2)·2^16⌋", "e"},
+		{"SHA-256 H0", 32, word.FromUint64(0x6A09E667), "⌊(√2-1)·2^32⌋", "sqrt2"},
+		{"Blowfish P[1]", 32, word.FromUint64(0x85A308D3), "⌊(π-3)·2^64⌋ mod 2^32", "pi"},
+		{"Complemented pi", 32, word.FromUint64(0x243F6A88).Not(32), "¬⌊(π-3)·2^32⌋", "pi"},
+		{"Pi plus four", 32, word.FromUint64(0x243F6A8C), "⌊(π-3)·2^32⌋ +4", "pi"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := explainGenerator(tt.w).Explain(tt.value, opts)
+			if e.Best == nil {
+				t.Fatalf("Explain(%v) found no derivation", tt.value)
+			}
+			if e.Best.Formula != tt.formula || e.Best.Source != tt.source {
+				t.Errorf("best = %s from %s, want %s from %s", e.Best.Formula, e.Best.Source, tt.formula, tt.source)
+			}
+			if e.Rigidity <= 0 || e.Rigidity > 1 {
+				t.Errorf("rigidity = %.3f, want in (0, 1]", e.Rigidity)
+			}
+			if e.Analysis.Value != tt.value {
+				t.Errorf("analysis value = %v, want %v", e.Analysis.Value, tt.value)
+			}
+		})
+	}
+}
+
+func TestExplainRotation(t *testing.T) {
+	value := word.FromUint64(0xB7E15163).RotateLeft(7, 32)
+	e := explainGenerator(32).Explain(value, ExplainOptions{MaxOffset: 8})
+	if e.Best == nil || !strings.HasPrefix(e.Best.Formula, "ROTL(") || e.Best.Transform != "rotl 7" {
+		t.Fatalf("best = %+v, want a rotation by 7", e.Best)
+	}
+}
+
+func TestExplainNextPrime(t *testing.T) {
+	g := explainGenerator(32)
+	prime, _, err := g.deriveValue(Derivation{Source: "pi", Rule: RuleNextPrime})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e := g.Explain(prime, ExplainOptions{MaxOffset: 8})
+	found := false
+	for _, m := range e.Matches {
+		if m.Rule == RuleNextPrime && m.Source == "pi" && m.Offset == 0 && m.Transform == "" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("NextPrime((π-3)·2^32) = %v not explained as such", prime)
+	}
+}
+
+func TestExplainArbitraryValue(t *testing.T) {
+	// A value with no short derivation should have low rigidity
+	e := explainGenerator(64).Explain(word.FromUint64(0x1B7DE9520C4A33F1), ExplainOptions{MaxOffset: 32})
+	if e.Best != nil && e.Rigidity > 0.5 {
+		t.Errorf("arbitrary value explained as %s with rigidity %.3f", e.Best.Formula, e.Rigidity)
+	}
+	if e.SearchSpace == 0 {
+		t.Error("search space not recorded")
+	}
+}
+
+func TestExplainSearchSpace(t *testing.T) {
+	g := explainGenerator(32)
+	opts := ExplainOptions{MaxOffset: 8, MaxDelta: 4}
+	e := g.Explain(word.FromUint64(0xB7E15163), opts)
+
+	// Window, odd, next prime and ±4, for every source, offset and transform
+	want := len(expansion.All()) * 9 * len(explainTransforms(32)) * (3 + 2*4)
+	if e.SearchSpace != want {
+		t.Errorf("SearchSpace = %d, want %d", e.SearchSpace, want)
+	}
+	// Few derivations are as short as Odd((e-2)·2^32)
+	if e.ChanceMatch <= 0 || e.ChanceMatch >= float64(e.SearchSpace)/(1<<32) {
+		t.Errorf("ChanceMatch = %g, want below %g", e.ChanceMatch, float64(e.SearchSpace)/(1<<32))
+	}
+
+	// A longer best match is more likely by chance
+	plus := g.Explain(word.FromUint64(0xB7E15163+3), opts)
+	if plus.Best == nil || plus.ChanceMatch <= e.ChanceMatch {
+		t.Errorf("ChanceMatch %g for a delta match, want above %g", plus.ChanceMatch, e.ChanceMatch)
+	}
+}
+
+func TestInferWordSize(t *testing.T) {
+	tests := []struct {
+		value word.Word
+		want  int
+	}{
+		{word.FromUint64(0xB7E1), 16},
+		{word.FromUint64(0xB7E15163), 32},
+		{word.FromUint64(0x1B7E15163), 64},
+		{word.Word{Hi: 1}, 128},
+	}
+	for _, tt := range tests {
+		if got := InferWordSize(tt.value); got != tt.want {
+			t.Errorf("InferWordSize(%v) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
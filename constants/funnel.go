@@ -0,0 +1,171 @@
+package constants
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rejection reasons, in the order validateCandidate applies the criteria.
+// Weak-key rejections are reported as RejectWeakKey followed by the name of
+// the failing pattern, for example "weak key: Simple Bit Pattern".
+const (
+	RejectBitDistribution = "bit distribution"
+	RejectAvalanche       = "avalanche"
+	RejectSAC             = "strict avalanche"
+	RejectBIC             = "bit independence"
+	RejectDifferential    = "differential"
+	RejectLinear          = "linear"
+	RejectHammingWeight   = "hamming weight"
+	RejectEntropy         = "entropy"
+	RejectWeakKey         = "weak key"
+	RejectStatistical     = "statistical tests"
+	RejectPrimality       = "primality"
+)
+
+var rejectionOrder = []string{
+	RejectBitDistribution,
+	RejectAvalanche,
+	RejectSAC,
+	RejectBIC,
+	RejectDifferential,
+	RejectLinear,
+	RejectHammingWeight,
+	RejectEntropy,
+	RejectWeakKey,
+	RejectStatistical,
+	RejectPrimality,
+}
+
+// weakKeyReason returns the rejection reason for a failed weak-key pattern.
+func weakKeyReason(pattern string) string {
+	return RejectWeakKey + ": " + pattern
+}
+
+// rejectionRank orders reasons by criterion, weak-key patterns by name.
+// Reasons from a custom Validator come last.
+func rejectionRank(reason string) int {
+	criterion, _, _ := strings.Cut(reason, ":")
+	for i, r := range rejectionOrder {
+		if r == criterion {
+			return i
+		}
+	}
+	return len(rejectionOrder)
+}
+
+// funnelCounter accumulates the per-worker counts during generation. It is
+// only used from the collector goroutine.
+type funnelCounter struct {
+	workers []WorkerFunnel
+}
+
+func newFunnelCounter(workers int, resumed []WorkerFunnel) *funnelCounter {
+	f := &funnelCounter{workers: make([]WorkerFunnel, workers)}
+	for i := range f.workers {
+		f.workers[i] = WorkerFunnel{Worker: i, Rejected: make(map[string]int)}
+		if i < len(resumed) {
+			f.workers[i].Generated = resumed[i].Generated
+			f.workers[i].Accepted = resumed[i].Accepted
+			for reason, n := range resumed[i].Rejected {
+				f.workers[i].Rejected[reason] = n
+			}
+		}
+	}
+	return f
+}
+
+// add counts a candidate of worker; an empty reason means it was accepted.
+func (f *funnelCounter) add(worker int, reason string) {
+	w := &f.workers[worker]
+	w.Generated++
+	if reason == "" {
+		w.Accepted++
+	} else {
+		w.Rejected[reason]++
+	}
+}
+
+// snapshot returns a copy of the per-worker counts.
+func (f *funnelCounter) snapshot() []WorkerFunnel {
+	workers := make([]WorkerFunnel, len(f.workers))
+	for i, w := range f.workers {
+		workers[i] = w
+		workers[i].Rejected = make(map[string]int, len(w.Rejected))
+		for reason, n := range w.Rejected {
+			workers[i].Rejected[reason] = n
+		}
+	}
+	return workers
+}
+
+// summary combines the worker counts into a funnel. Every criterion is
+// listed, even when it rejected nothing, so runs can be compared.
+func (f *funnelCounter) summary() RejectionFunnel {
+	funnel := RejectionFunnel{Workers: f.snapshot()}
+	rejected := make(map[string]int)
+	for _, w := range funnel.Workers {
+		funnel.Generated += w.Generated
+		funnel.Accepted += w.Accepted
+		for reason, n := range w.Rejected {
+			rejected[reason] += n
+		}
+	}
+
+	// The default criteria, then weak-key patterns and the reasons of a
+	// custom Validator as they occurred
+	var reasons []string
+	listed := make(map[string]bool)
+	for _, reason := range rejectionOrder {
+		if reason != RejectWeakKey {
+			reasons = append(reasons, reason)
+			listed[reason] = true
+		}
+	}
+	for reason := range rejected {
+		if !listed[reason] {
+			reasons = append(reasons, reason)
+		}
+	}
+	sort.SliceStable(reasons, func(i, j int) bool {
+		ri, rj := rejectionRank(reasons[i]), rejectionRank(reasons[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return reasons[i] < reasons[j]
+	})
+
+	remaining := funnel.Generated
+	for _, reason := range reasons {
+		remaining -= rejected[reason]
+		funnel.Stages = append(funnel.Stages, FunnelStage{
+			Reason:    reason,
+			Rejected:  rejected[reason],
+			Remaining: remaining,
+		})
+	}
+	return funnel
+}
+
+// String lists the criteria that rejected candidates, most first, for
+// error messages.
+func (f RejectionFunnel) String() string {
+	stages := make([]FunnelStage, 0, len(f.Stages))
+	for _, s := range f.Stages {
+		if s.Rejected > 0 {
+			stages = append(stages, s)
+		}
+	}
+	if len(stages) == 0 {
+		return fmt.Sprintf("%d generated, none rejected", f.Generated)
+	}
+	sort.SliceStable(stages, func(i, j int) bool {
+		return stages[i].Rejected > stages[j].Rejected
+	})
+
+	parts := make([]string, len(stages))
+	for i, s := range stages {
+		parts[i] = fmt.Sprintf("%s %d", s.Reason, s.Rejected)
+	}
+	return fmt.Sprintf("%d generated, rejected by %s", f.Generated, strings.Join(parts, ", "))
+}
@@ -0,0 +1,140 @@
+package constants
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRejectionReason(t *testing.T) {
+	config := DefaultConfig()
+	config.AvalancheTestCases = 50
+	config.RequirePrime = false
+	base := NewGenerator(config).AnalyzeConstant(RC6_P)
+
+	tests := []struct {
+		name   string
+		modify func(*Config, *ConstantCandidate)
+		want   string
+	}{
+		{"Accepted", func(*Config, *ConstantCandidate) {}, ""},
+		{"Bit distribution", func(_ *Config, c *ConstantCandidate) { c.BitDistribution = 0.9 }, RejectBitDistribution},
+		{"Avalanche", func(_ *Config, c *ConstantCandidate) { c.AvalancheScore = 0.1 }, RejectAvalanche},
+		{"Strict avalanche", func(cfg *Config, _ *ConstantCandidate) { cfg.MaxSACDeviation = 0.01 }, RejectSAC},
+		{"Bit independence", func(cfg *Config, _ *ConstantCandidate) { cfg.MaxBICCorrelation = 0.01 }, RejectBIC},
+		{"Differential", func(cfg *Config, c *ConstantCandidate) {
+			cfg.Cryptanalysis.MaxDifferentialProbability = c.MaxDifferentialProbability / 2
+		}, RejectDifferential},
+		{"Linear", func(cfg *Config, c *ConstantCandidate) { cfg.Cryptanalysis.MaxLinearBias = c.MaxLinearBias / 2 }, RejectLinear},
+		// RC6 P has Hamming weight 17
+		{"Hamming weight", func(cfg *Config, _ *ConstantCandidate) { cfg.ValidationCriteria.MaxHammingWeight = 16 }, RejectHammingWeight},
+		{"Entropy", func(cfg *Config, _ *ConstantCandidate) { cfg.StatisticalTests.MinEntropyScore = 0.999 }, RejectEntropy},
+		{"Weak key", func(_ *Config, c *ConstantCandidate) {
+			c.TestResults.WeakKeyTests = []WeakKeyTest{{Pattern: "Simple Bit Pattern", Passed: false}}
+		}, "weak key: Simple Bit Pattern"},
+		{"Statistical tests", func(_ *Config, c *ConstantCandidate) {
+			tests := append([]StatisticalTest(nil), c.TestResults.StatisticalTests...)
+			for i := range tests {
+				tests[i].Passed = false
+			}
+			c.TestResults.StatisticalTests = tests
+		}, RejectStatistical},
+		{"Primality", func(cfg *Config, c *ConstantCandidate) {
+			cfg.RequirePrime = true
+			c.TestResults.PrimalityTests = []PrimalityTest{{Passed: false}}
+		}, RejectPrimality},
+		{"First criterion wins", func(_ *Config, c *ConstantCandidate) {
+			c.BitDistribution = 0.9
+			c.AvalancheScore = 0.1
+		}, RejectBitDistribution},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, c := config, base
+			tt.modify(&cfg, &c)
+			g := NewGenerator(cfg)
+			if got := g.RejectionReason(c); got != tt.want {
+				t.Errorf("RejectionReason() = %q, want %q", got, tt.want)
+			}
+			if got := g.Accepts(c); got != (tt.want == "") {
+				t.Errorf("Accepts() = %v, want %v", got, tt.want == "")
+			}
+		})
+	}
+}
+
+func TestFunnelSummary(t *testing.T) {
+	f := newFunnelCounter(2, nil)
+	f.add(0, "")
+	f.add(0, RejectEntropy)
+	f.add(1, weakKeyReason("Simple Bit Pattern"))
+	f.add(1, RejectBitDistribution)
+	f.add(1, RejectEntropy)
+
+	summary := f.summary()
+	if summary.Generated != 5 || summary.Accepted != 1 {
+		t.Errorf("Generated, Accepted = %d, %d, want 5, 1", summary.Generated, summary.Accepted)
+	}
+
+	var reasons []string
+	for _, s := range summary.Stages {
+		reasons = append(reasons, s.Reason)
+	}
+	want := []string{
+		RejectBitDistribution, RejectAvalanche, RejectSAC, RejectBIC, RejectDifferential, RejectLinear,
+		RejectHammingWeight, RejectEntropy,
+		"weak key: Simple Bit Pattern", RejectStatistical, RejectPrimality,
+	}
+	if strings.Join(reasons, "|") != strings.Join(want, "|") {
+		t.Errorf("stages = %q, want %q", reasons, want)
+	}
+	if last := summary.Stages[len(summary.Stages)-1]; last.Remaining != summary.Accepted {
+		t.Errorf("last stage leaves %d, want %d", last.Remaining, summary.Accepted)
+	}
+	if summary.Workers[1].Rejected[RejectEntropy] != 1 || summary.Workers[1].Generated != 3 {
+		t.Errorf("worker 1 = %+v", summary.Workers[1])
+	}
+
+	if got := summary.String(); !strings.HasPrefix(got, "5 generated, rejected by entropy 2") {
+		t.Errorf("String() = %q", got)
+	}
+
+	// Counts carry over from a resumed checkpoint without aliasing it
+	resumed := newFunnelCounter(2, f.snapshot())
+	resumed.add(0, RejectEntropy)
+	if f.workers[0].Rejected[RejectEntropy] != 1 || resumed.workers[0].Rejected[RejectEntropy] != 2 {
+		t.Error("resumed counter shares state with the checkpoint")
+	}
+}
+
+func TestGenerateFunnel(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = "primer-funnel"
+	config.NumCandidates = 16
+	config.AvalancheTestCases = 50
+	config.ParallelWorkers = 3
+	config.DetailedLogging = false
+	config.ResultsFile = ""
+
+	result, err := NewGenerator(config).Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	f := result.Funnel
+	if f.Generated != config.NumCandidates {
+		t.Errorf("Funnel.Generated = %d, want %d", f.Generated, config.NumCandidates)
+	}
+	if f.Accepted != result.TotalCandidates {
+		t.Errorf("Funnel.Accepted = %d, want %d", f.Accepted, result.TotalCandidates)
+	}
+	if len(f.Workers) != config.ParallelWorkers {
+		t.Errorf("got %d worker funnels, want %d", len(f.Workers), config.ParallelWorkers)
+	}
+
+	// With an impossible threshold the error names the responsible criterion
+	config.MinAvalancheScore = 0.99
+	_, err = NewGenerator(config).Generate()
+	if err == nil || !strings.Contains(err.Error(), "rejected by avalanche") {
+		t.Errorf("Generate() error = %v, want avalanche rejections", err)
+	}
+}
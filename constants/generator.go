@@ -2,33 +2,162 @@ package constants
 
 import (
 	"context"
-	"crypto/rand"
-	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
 	"math/bits"
 	"os"
+	"reflect"
 	"sort"
 	"sync"
 	"time"
+
+	"primer/rc6"
+	"primer/word"
 )
 
 type Generator struct {
 	config Config
 	logger *Logger
+	random RandomSource
 	ctx    context.Context
 	cancel context.CancelFunc
+
+	derivations     []Derivation
+	derivationsOnce sync.Once
+
+	resume          *Checkpoint
+	candidateOutput io.Writer
+
+	pairs   map[[2]word.Word]PairAnalysis
+	pairsMu sync.Mutex
+
+	scorer    Scorer
+	validator Validator
+	selector  Selector
 }
 
-func NewGenerator(config Config) *Generator {
+// NewGenerator creates a generator for config. Without options it scores,
+// validates and selects candidates with DefaultScorer, DefaultValidator and
+// DefaultSelector.
+func NewGenerator(config Config, opts ...Option) *Generator {
 	ctx, cancel := context.WithCancel(context.Background())
-	return &Generator{
+	var random RandomSource = CryptoSource{}
+	if config.Seed != "" {
+		random = NewSeededSource(config.Seed)
+	}
+	g := &Generator{
 		config: config,
 		logger: NewLogger(config.DetailedLogging),
+		random: random,
 		ctx:    ctx,
 		cancel: cancel,
 	}
+	g.scorer = defaultScorer{g}
+	g.validator = defaultValidator{g}
+	g.selector = defaultSelector{g}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// SetRandomSource replaces the source of randomness for generation and
+// scoring. It must be called before Generate.
+func (g *Generator) SetRandomSource(random RandomSource) {
+	g.random = random
+}
+
+// SetLogOutput sends the generator's log to w instead of standard output.
+func (g *Generator) SetLogOutput(w io.Writer) {
+	g.logger.SetOutput(w)
+}
+
+// stream returns the random stream for the given candidate index.
+func (g *Generator) stream(index uint64) io.Reader {
+	if g.random == nil {
+		return CryptoSource{}.Stream(index)
+	}
+	return g.random.Stream(index)
+}
+
+// wordSize returns the configured word size in bits, defaulting to 32.
+func (g *Generator) wordSize() uint {
+	if g.config.WordSize == 0 {
+		return 32
+	}
+	return uint(g.config.WordSize)
+}
+
+// statisticalTests returns the statistical test thresholds.
+func (g *Generator) statisticalTests() StatisticalTestsConfig {
+	if g.config.StatisticalTests == (StatisticalTestsConfig{}) {
+		return defaultStatisticalTests
+	}
+	return g.config.StatisticalTests
+}
+
+// randomnessTests returns the statistical test selection and weights.
+func (g *Generator) randomnessTests() RandomnessTestsConfig {
+	if reflect.ValueOf(g.config.RandomnessTests).IsZero() {
+		return defaultRandomnessTests
+	}
+	return g.config.RandomnessTests
+}
+
+// scoringWeights returns the weights used to rank candidates.
+func (g *Generator) scoringWeights() ScoringWeights {
+	if g.config.ScoringWeights == (ScoringWeights{}) {
+		return defaultScoringWeights
+	}
+	return g.config.ScoringWeights
+}
+
+// hammingWeightBounds returns the accepted range of candidate Hamming
+// weights, [3w/8, 5w/8] unless configured.
+func (g *Generator) hammingWeightBounds() (int, int) {
+	w := int(g.wordSize())
+	lo, hi := g.config.ValidationCriteria.MinHammingWeight, g.config.ValidationCriteria.MaxHammingWeight
+	if lo == 0 {
+		lo = 3 * w / 8
+	}
+	if hi == 0 {
+		hi = 5 * w / 8
+	}
+	return lo, hi
+}
+
+// minHammingDistance returns the number of bits in which P and Q must
+// differ, 3w/8 unless configured.
+func (g *Generator) minHammingDistance() int {
+	if d := g.config.ValidationCriteria.MinHammingDistance; d != 0 {
+		return d
+	}
+	return int(3 * g.wordSize() / 8)
+}
+
+// minDifferentBits returns the number of bits in which P must differ from
+// every shift of Q and vice versa, w/8 unless configured.
+func (g *Generator) minDifferentBits() int {
+	if d := g.config.ValidationCriteria.MinDifferentBits; d != 0 {
+		return d
+	}
+	return int(g.wordSize() / 8)
+}
+
+// constantNames returns the names of the constants to select.
+func (g *Generator) constantNames() []string {
+	if len(g.config.ConstantNames) == 0 {
+		return defaultConstantNames
+	}
+	return g.config.ConstantNames
+}
+
+// timeout returns the generation time limit, or zero for none.
+func (g *Generator) timeout() time.Duration {
+	return time.Duration(g.config.TimeoutSeconds) * time.Second
 }
 
 func (g *Generator) Cleanup() {
@@ -39,22 +168,37 @@ func (g *Generator) Cleanup() {
 
 func (g *Generator) Generate() (*GenerationResult, error) {
 	start := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
-	defer cancel()
 
 	// Validate configuration
 	if err := ValidateConfig(&g.config); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
+	ctx, cancel := context.WithCancel(g.ctx)
+	if timeout := g.timeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(g.ctx, timeout)
+	}
+	defer cancel()
+
 	// Initialize channels
 	workerCount := g.config.ParallelWorkers
-	batchSize := g.config.NumCandidates / workerCount
 	bufferSize := workerCount * 2
 
-	candidateChan := make(chan ConstantCandidate, bufferSize)
+	resultChan := make(chan workerResult, bufferSize)
 	errorChan := make(chan error, bufferSize)
 
+	// Resumed runs start from the checkpointed progress and candidates
+	progress := g.startProgress(workerCount)
+	candidates := g.resumedCandidates()
+	funnel := newFunnelCounter(workerCount, g.resumedFunnel())
+
+	output, closeOutput, err := g.openStream()
+	if err != nil {
+		return nil, err
+	}
+	defer closeOutput()
+	stream := newCandidateStream(output, g.resumedStreamOffset())
+
 	var wg sync.WaitGroup
 
 	// Start worker pool
@@ -62,42 +206,103 @@ func (g *Generator) Generate() (*GenerationResult, error) {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
-			g.worker(workerID, candidateChan, errorChan, batchSize)
+			g.worker(ctx, workerID, workerCount, progress[workerID], resultChan, errorChan)
 		}(i)
 	}
 
-	// Collect results
-	var candidates []ConstantCandidate
+	// Collect results while the workers run so they never block on a full
+	// channel. Progress and candidates are only touched here, so every
+	// checkpoint is consistent.
+	var firstErr error
+	var collectors sync.WaitGroup
+	collectors.Add(2)
+	go func() {
+		defer collectors.Done()
+		var tick <-chan time.Time
+		if interval := g.checkpointInterval(); interval > 0 {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+		for {
+			select {
+			case r, ok := <-resultChan:
+				if !ok {
+					return
+				}
+				progress[r.worker] = r.index + 1
+				funnel.add(r.worker, r.rejection)
+				stream.write(CandidateRecord{
+					Worker:    r.worker,
+					Accepted:  r.rejection == "",
+					Rejection: r.rejection,
+					Candidate: r.candidate,
+				})
+				if r.rejection == "" {
+					candidates = append(candidates, r.candidate)
+				}
+			case <-tick:
+				if err := g.saveCheckpoint(progress, funnel.snapshot(), candidates, stream.offset()); err != nil {
+					g.logger.Error("Failed to save checkpoint:", err)
+				}
+			}
+		}
+	}()
+	go func() {
+		defer collectors.Done()
+		for err := range errorChan {
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}()
+
 	done := make(chan struct{})
 
 	go func() {
 		wg.Wait()
+		close(resultChan)
+		close(errorChan)
+		collectors.Wait()
 		close(done)
 	}()
 
-	// Handle completion or timeout
+	// Handle completion, cancellation or timeout. Workers stop after their
+	// current candidate, and the final checkpoint records where they got to.
+	var stopErr error
 	select {
 	case <-ctx.Done():
-		return nil, fmt.Errorf("generation timed out: %v", ctx.Err())
+		<-done
+		stopErr = fmt.Errorf("generation cancelled: %v", ctx.Err())
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			stopErr = fmt.Errorf("generation timed out: %v", ctx.Err())
+		}
 	case <-done:
-		// Process any remaining errors
-		close(errorChan)
-		for err := range errorChan {
-			if err != nil {
-				return nil, fmt.Errorf("worker error: %v", err)
-			}
+		if firstErr != nil {
+			stopErr = fmt.Errorf("worker error: %v", firstErr)
 		}
-
-		// Collect remaining candidates
-		close(candidateChan)
-		for candidate := range candidateChan {
-			candidates = append(candidates, candidate)
+	}
+	if g.config.CheckpointFile != "" {
+		if err := g.saveCheckpoint(progress, funnel.snapshot(), candidates, stream.offset()); err != nil {
+			g.logger.Error("Failed to save checkpoint:", err)
 		}
 	}
+	if stream != nil && stream.err != nil {
+		g.logger.Error("Failed to stream candidates:", stream.err)
+	}
+	if stopErr != nil {
+		return nil, stopErr
+	}
+
+	// Order candidates by index so selection does not depend on scheduling
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Index < candidates[j].Index
+	})
 
 	// Validate we have enough candidates
-	if len(candidates) < 2 {
-		return nil, fmt.Errorf("insufficient valid candidates generated: got %d, need at least 2", len(candidates))
+	summary := funnel.summary()
+	if need := len(g.constantNames()); len(candidates) < need {
+		return nil, fmt.Errorf("insufficient valid candidates generated: got %d, need at least %d (%s)", len(candidates), need, summary)
 	}
 
 	// Process results and create final output
@@ -105,6 +310,7 @@ func (g *Generator) Generate() (*GenerationResult, error) {
 	if err != nil {
 		return nil, fmt.Errorf("processing results: %w", err)
 	}
+	result.Funnel = summary
 
 	// Save results if configured
 	if g.config.ResultsFile != "" {
@@ -118,52 +324,81 @@ func (g *Generator) Generate() (*GenerationResult, error) {
 
 func (g *Generator) processResults(candidates []ConstantCandidate, startTime time.Time) (*GenerationResult, error) {
 	// Select best constants
-	bestP, bestQ := g.selectBestConstants(candidates)
+	names := g.constantNames()
+	selection, err := g.selector.Select(candidates, len(names))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSelection(selection, len(names)); err != nil {
+		return nil, err
+	}
+	selected := selection.Constants
 
 	// Validate selected constants
-	if err := g.validateSelectedConstants(bestP, bestQ); err != nil {
+	if err := g.validateSelectedConstants(selected); err != nil {
 		return nil, fmt.Errorf("invalid selected constants: %w", err)
 	}
 
 	// Create result
 	result := &GenerationResult{
-		SelectedP:       bestP,
-		SelectedQ:       bestQ,
+		Alternates:      selection.Alternates,
+		ParetoFront:     selection.ParetoFront,
 		TotalCandidates: len(candidates),
 		Duration:        time.Since(startTime),
 		StartTime:       startTime,
 		EndTime:         time.Now(),
 		Config:          g.config,
 	}
+	for i, name := range names {
+		result.Selected = append(result.Selected, NamedConstant{Name: name, Candidate: selected[i]})
+	}
+	markSelected(result.ParetoFront, result.Selected)
 
 	// Run final validation tests
 	if err := g.runFinalValidation(result); err != nil {
 		return nil, fmt.Errorf("final validation failed: %w", err)
 	}
+	result.SelectedP = result.Selected[0].Candidate
+	result.SelectedQ = result.Selected[1].Candidate
 
 	return result, nil
 }
 
-func (g *Generator) validateSelectedConstants(p, q ConstantCandidate) error {
-	// Check for nil or zero values
-	if p.Value == 0 || q.Value == 0 {
-		return fmt.Errorf("zero value constant selected")
+// Constants returns the selected constants. Results saved before Selected
+// existed only record P and Q.
+func (r *GenerationResult) Constants() []NamedConstant {
+	if len(r.Selected) > 0 {
+		return r.Selected
 	}
+	return []NamedConstant{{Name: "P", Candidate: r.SelectedP}, {Name: "Q", Candidate: r.SelectedQ}}
+}
 
-	// Validate minimum scores
-	if p.AvalancheScore < g.config.MinAvalancheScore ||
-		q.AvalancheScore < g.config.MinAvalancheScore {
-		return fmt.Errorf("constants do not meet minimum avalanche score")
-	}
+func (g *Generator) validateSelectedConstants(selected []ConstantCandidate) error {
+	for _, c := range selected {
+		// Check for nil or zero values
+		if c.Value.IsZero() {
+			return fmt.Errorf("zero value constant selected")
+		}
 
-	// Validate bit distribution
-	if !g.isValidBitDistribution(p) || !g.isValidBitDistribution(q) {
-		return fmt.Errorf("constants do not meet bit distribution requirements")
-	}
+		// Validate minimum scores
+		if c.AvalancheScore < g.config.MinAvalancheScore {
+			return fmt.Errorf("constants do not meet minimum avalanche score")
+		}
 
-	// Validate primality
-	if !g.isPrime(p.Value) || !g.isPrime(q.Value) {
-		return fmt.Errorf("selected constants are not prime")
+		// Validate bit distribution
+		if !g.isValidBitDistribution(c) {
+			return fmt.Errorf("constants do not meet bit distribution requirements")
+		}
+
+		// Validate primality
+		if g.config.RequirePrime && !g.isPrime(c.Value) {
+			return fmt.Errorf("selected constants are not prime")
+		}
+		if class := g.primeClass(); class != PrimeAny {
+			if passed, _ := g.primeClassTest(c.Value, class); !passed {
+				return fmt.Errorf("selected constants are not %s primes", class)
+			}
+		}
 	}
 
 	return nil
@@ -175,146 +410,277 @@ func (g *Generator) isValidBitDistribution(c ConstantCandidate) bool {
 }
 
 func (g *Generator) runFinalValidation(result *GenerationResult) error {
-	// Perform final statistical tests
-	pTests := g.runAllStatisticalTests(result.SelectedP.Value)
-	qTests := g.runAllStatisticalTests(result.SelectedQ.Value)
+	for i := range result.Selected {
+		c := &result.Selected[i].Candidate
+
+		// Perform final statistical tests and update the results
+		tests := g.runAllStatisticalTests(c.Value)
+		c.TestResults.StatisticalTests = tests
+		c.TestResults.Aggregate = g.aggregateSignificance(tests)
+
+		// Verify minimum test passing requirements
+		if !c.TestResults.Aggregate.Passed {
+			return fmt.Errorf("final statistical tests failed (%s: %s)",
+				c.TestResults.Aggregate.Method, c.TestResults.Aggregate.Details)
+		}
 
-	// Update results with final tests
-	result.SelectedP.TestResults.StatisticalTests = pTests
-	result.SelectedQ.TestResults.StatisticalTests = qTests
+		if g.config.NIST.Enabled {
+			report, err := g.runNISTTests(c.Value)
+			if err != nil {
+				return fmt.Errorf("NIST tests failed: %v", err)
+			}
+			c.TestResults.NIST = report
+		}
 
-	// Verify minimum test passing requirements
-	if !g.verifyTestResults(pTests) || !g.verifyTestResults(qTests) {
-		return fmt.Errorf("final statistical tests failed")
+		if g.config.KeySchedule.Enabled {
+			tests, err := g.runKeyScheduleTests(c.Value)
+			if err != nil {
+				return fmt.Errorf("key schedule tests failed: %v", err)
+			}
+			c.TestResults.KeyScheduleTests = tests
+		}
 	}
 
-	// Verify constants are sufficiently different
-	if !g.areSufficientlyDifferent(result.SelectedP, result.SelectedQ) {
-		return fmt.Errorf("selected constants are not sufficiently different")
+	// Verify constants are pairwise sufficiently different
+	for i := range result.Selected {
+		for j := i + 1; j < len(result.Selected); j++ {
+			if g.config.PairAnalysis.Enabled {
+				analysis := g.pairAnalysis(result.Selected[i].Candidate.Value, result.Selected[j].Candidate.Value)
+				analysis.First, analysis.Second = result.Selected[i].Name, result.Selected[j].Name
+				result.PairAnalysis = append(result.PairAnalysis, analysis)
+			}
+			if !g.areSufficientlyDifferent(result.Selected[i].Candidate, result.Selected[j].Candidate) {
+				return fmt.Errorf("selected constants %s and %s are not sufficiently different",
+					result.Selected[i].Name, result.Selected[j].Name)
+			}
+		}
 	}
 
 	return nil
 }
 
-func (g *Generator) verifyTestResults(tests []StatisticalTest) bool {
-	failedTests := 0
-	for _, test := range tests {
-		if !test.Passed {
-			failedTests++
-		}
-	}
-	// Allow up to 20% of tests to fail
-	return failedTests <= len(tests)/5
-}
+// Supported transforms for avalanche scoring.
+const (
+	// TransformRC6 keys real RC6-w/r/b encryptions with the candidate.
+	TransformRC6 = "rc6"
+	// TransformSimple is the word-level ROL5, multiply, ROL3 transform.
+	TransformSimple = "simple"
+)
+
+// Rotations of the simple transform before and after the multiplication.
+const (
+	simpleRotateIn  = 5
+	simpleRotateOut = 3
+)
 
-func (g *Generator) rc6Transform(input, constant uint32) uint32 {
+func (g *Generator) rc6Transform(input, constant word.Word) word.Word {
 	// Simplified RC6-like transformation
+	w := g.wordSize()
 	x := input
-	x = ((x << 5) | (x >> 27)) // ROL by 5
-	x *= constant
-	x = ((x << 3) | (x >> 29)) // ROL by 3
+	x = x.RotateLeft(simpleRotateIn, w)
+	x = x.Mul(constant, w)
+	x = x.RotateLeft(simpleRotateOut, w)
 	return x
 }
 
-func (g *Generator) worker(workerID int, candidates chan<- ConstantCandidate, errors chan<- error, batchSize int) {
-	// Use context from Generator struct
-	for i := 0; i < batchSize; i++ {
-		// Check for context cancellation
-		select {
-		case <-g.ctx.Done():
-			errors <- fmt.Errorf("worker %d cancelled: %v", workerID, g.ctx.Err())
-			return
-		default:
-			// Continue processing
-		}
+// workerResult reports a candidate a worker has generated and why it was
+// rejected, if it was.
+type workerResult struct {
+	worker    int
+	index     int
+	candidate ConstantCandidate
+	rejection string
+}
 
-		candidate, err := g.generateCandidate()
-		if err != nil {
-			errors <- fmt.Errorf("worker %d error: %v", workerID, err)
-			continue
-		}
+// worker generates the candidates in every stride-th batch of BatchSize
+// consecutive indices, starting with batch workerID and skipping indices
+// below start.
+func (g *Generator) worker(ctx context.Context, workerID, stride, start int, results chan<- workerResult, errors chan<- error) {
+	batchSize := g.config.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	count := g.candidateCount()
+
+	for batch := workerID; batch*batchSize < count; batch += stride {
+		for i := max(batch*batchSize, start); i < (batch+1)*batchSize && i < count; i++ {
+			// Check for cancellation or timeout
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				// Continue processing
+			}
+
+			candidate, err := g.generateCandidate(i)
+			if err != nil {
+				errors <- fmt.Errorf("worker %d error: %v", workerID, err)
+				continue
+			}
 
-		if g.validateCandidate(candidate) {
-			candidates <- candidate
+			results <- workerResult{
+				worker:    workerID,
+				index:     i,
+				candidate: candidate,
+				rejection: g.validator.Validate(candidate),
+			}
 		}
 	}
 }
 
-func (g *Generator) generateCandidate() (ConstantCandidate, error) {
+// generateCandidate draws and scores candidate number index. All of its
+// randomness comes from the stream for that index.
+func (g *Generator) generateCandidate(index int) (ConstantCandidate, error) {
 	start := time.Now()
+	rng := g.stream(uint64(index))
 
-	value, err := g.generate32BitPrime()
+	value, derivation, err := g.drawValue(index, rng)
 	if err != nil {
 		return ConstantCandidate{}, err
 	}
 
+	candidate := g.evaluateCandidate(value, rng)
+	candidate.Index = index
+	candidate.Derivation = derivation
+	candidate.GenerationTime = start
+	candidate.TestDuration = time.Since(start)
+
+	return candidate, nil
+}
+
+// AnalyzeConstant runs every candidate test on a user-supplied value.
+// With NIST.Enabled it also runs the NIST battery on the value's keystreams.
+func (g *Generator) AnalyzeConstant(value word.Word) ConstantCandidate {
+	candidate := g.evaluateCandidate(value.Truncate(g.wordSize()), g.stream(analysisStream))
+	if g.config.NIST.Enabled {
+		report, err := g.runNISTTests(candidate.Value)
+		if err != nil {
+			g.logger.Error("NIST tests failed:", err)
+		}
+		candidate.TestResults.NIST = report
+	}
+	if g.config.KeySchedule.Enabled {
+		tests, err := g.runKeyScheduleTests(candidate.Value)
+		if err != nil {
+			g.logger.Error("Key schedule tests failed:", err)
+		}
+		candidate.TestResults.KeyScheduleTests = tests
+	}
+	return candidate
+}
+
+// evaluateCandidate computes the scores and test results for value.
+func (g *Generator) evaluateCandidate(value word.Word, rng io.Reader) ConstantCandidate {
+	start := time.Now()
+
 	bitDist := g.calculateBitDistribution(value)
-	avalanche := g.testAvalancheEffect(value)
 	entropy := g.calculateEntropy(value)
-	hammingWeight := bits.OnesCount32(value)
+	hammingWeight := value.OnesCount()
 
 	candidate := ConstantCandidate{
 		Value:           value,
+		WordSize:        int(g.wordSize()),
 		BitDistribution: bitDist,
-		AvalancheScore:  avalanche,
 		HammingWeight:   hammingWeight,
 		EntropyScore:    entropy,
-		TestDuration:    time.Since(start),
 		GenerationTime:  start,
 	}
 
 	// Perform additional tests
-	candidate.TestResults = g.runTests(candidate)
+	candidate.TestResults = g.runTests(candidate, rng)
+	candidate.AvalancheScore = averageAvalancheScore(candidate.TestResults.AvalancheTests)
+	if g.config.Cryptanalysis.Enabled {
+		candidate.MaxDifferentialProbability, candidate.MaxLinearBias = g.analyzeTransform(value)
+	}
+	candidate.TestDuration = time.Since(start)
 
-	return candidate, nil
+	return candidate
 }
 
-func (g *Generator) generate32BitPrime() (uint32, error) {
+// drawValue produces the value of candidate index: a random prime, or in
+// derive mode the constant described by the index-th derivation.
+func (g *Generator) drawValue(index int, rng io.Reader) (word.Word, *Derivation, error) {
+	if g.config.GenerationMode != ModeDerive {
+		value, err := g.generatePrime(rng)
+		return value, nil, err
+	}
+
+	specs := g.derivationSpecs()
+	if index < 0 || index >= len(specs) {
+		return word.Word{}, nil, fmt.Errorf("no derivation for candidate %d", index)
+	}
+	value, derivation, err := g.deriveValue(specs[index])
+	if err != nil {
+		return word.Word{}, nil, err
+	}
+	return value, &derivation, nil
+}
+
+// generatePrime draws random odd w-bit values until one is prime. Primes of
+// a class other than PrimeAny come from a sieved search instead.
+func (g *Generator) generatePrime(rng io.Reader) (word.Word, error) {
+	if g.primeClass() != PrimeAny {
+		return g.generateClassPrime(rng)
+	}
+	buf := make([]byte, g.wordSize()/8)
 	for attempt := 0; attempt < g.config.MaxPrimeAttempts; attempt++ {
-		var b [4]byte
-		n, err := rand.Read(b[:])
+		n, err := io.ReadFull(rng, buf)
 		if err != nil {
-			return 0, fmt.Errorf("random generation failed: %w", err)
+			return word.Word{}, fmt.Errorf("random generation failed: %w", err)
 		}
-		if n != 4 {
-			return 0, fmt.Errorf("incomplete random read: got %d bytes", n)
+		if n != len(buf) {
+			return word.Word{}, fmt.Errorf("incomplete random read: got %d bytes", n)
 		}
 
-		value := binary.BigEndian.Uint32(b[:])
-
-		// Avoid overflow in primality testing
-		if value > math.MaxUint32-100 {
-			continue
-		}
+		// Every prime of interest is odd
+		value := word.FromBytes(buf)
+		value.Lo |= 1
 
 		if g.isPrime(value) {
 			return value, nil
 		}
 	}
-	return 0, fmt.Errorf("prime generation failed after %d attempts",
+	return word.Word{}, fmt.Errorf("prime generation failed after %d attempts",
 		g.config.MaxPrimeAttempts)
 }
 
-func (g *Generator) isPrime(n uint32) bool {
+// Miller-Rabin bases that are deterministic below 2^32 and 2^64
+var (
+	millerRabinBases32 = []uint64{2, 7, 61}
+	millerRabinBases64 = []uint64{2, 3, 5, 7, 11, 13, 17, 19, 23, 29, 31, 37}
+)
+
+// probablePrimeRounds is the number of Miller-Rabin rounds, on top of
+// Baillie-PSW, used for values wider than 64 bits.
+const probablePrimeRounds = 20
+
+func (g *Generator) isPrime(n word.Word) bool {
+	if n.Hi != 0 {
+		return n.Big().ProbablyPrime(probablePrimeRounds)
+	}
+	return g.isPrime64(n.Lo)
+}
+
+func (g *Generator) isPrime64(n uint64) bool {
 	if n <= 1 || n == 4 {
 		return false
 	}
 	if n <= 3 {
 		return true
 	}
-
-	// Miller-Rabin test bases for 32-bit integers
-	bases := []uint32{2, 7, 61}
+	if n%2 == 0 {
+		return false
+	}
 
 	// Find d such that n-1 = d * 2^r
 	d := n - 1
-	r := uint32(0)
+	r := uint64(0)
 	for d%2 == 0 {
 		d /= 2
 		r++
 	}
 
-	for _, a := range bases {
+	for _, a := range millerRabinBasesFor(n) {
 		if !g.millerRabinTest(n, d, r, a) {
 			return false
 		}
@@ -322,16 +688,25 @@ func (g *Generator) isPrime(n uint32) bool {
 	return true
 }
 
-func (g *Generator) millerRabinTest(n, d, r, a uint32) bool {
-	if n == a {
+// millerRabinBasesFor returns the smallest deterministic base set for n.
+func millerRabinBasesFor(n uint64) []uint64 {
+	if n < 1<<32 {
+		return millerRabinBases32
+	}
+	return millerRabinBases64
+}
+
+func (g *Generator) millerRabinTest(n, d, r, a uint64) bool {
+	a %= n
+	if a == 0 {
 		return true
 	}
 	x := g.modPow(a, d, n)
 	if x == 1 || x == n-1 {
 		return true
 	}
-	for j := uint32(0); j < r-1; j++ {
-		x = (x * x) % n
+	for j := uint64(1); j < r; j++ {
+		x = mulMod(x, x, n)
 		if x == n-1 {
 			return true
 		}
@@ -342,183 +717,375 @@ func (g *Generator) millerRabinTest(n, d, r, a uint32) bool {
 	return false
 }
 
-func (g *Generator) modPow(base, exp, mod uint32) uint32 {
+func (g *Generator) modPow(base, exp, mod uint64) uint64 {
 	if mod == 0 {
 		panic("modulus cannot be zero")
 	}
 
-	result := uint64(1)
-	b := uint64(base) % uint64(mod)
-	e := uint64(exp)
+	result := uint64(1) % mod
+	b := base % mod
+	e := exp
 
 	for e > 0 {
 		if e&1 == 1 {
-			result = (result * b) % uint64(mod)
+			result = mulMod(result, b, mod)
 		}
-		b = (b * b) % uint64(mod)
+		b = mulMod(b, b, mod)
 		e >>= 1
 	}
 
-	return uint32(result)
+	return result
 }
 
-func (g *Generator) calculateBitDistribution(n uint32) float64 {
-	ones := 0
-	for i := 0; i < 32; i++ {
-		if n&(1<<uint(i)) != 0 {
-			ones++
-		}
+// mulMod returns a*b mod m without overflow.
+func mulMod(a, b, m uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return bits.Rem64(hi, lo, m)
+}
+
+func (g *Generator) calculateBitDistribution(n word.Word) float64 {
+	w := g.wordSize()
+	return float64(n.Truncate(w).OnesCount()) / float64(w)
+}
+
+// randomWord returns a uniformly random w-bit value.
+func (g *Generator) randomWord(rng io.Reader) (word.Word, error) {
+	buf := make([]byte, g.wordSize()/8)
+	if _, err := io.ReadFull(rng, buf); err != nil {
+		return word.Word{}, fmt.Errorf("random generation failed: %w", err)
 	}
-	return float64(ones) / 32.0
+	return word.FromBytes(buf), nil
+}
+
+// testAvalancheEffect returns the average fraction of output bits that flip
+// when a single input bit is flipped, using the configured transform keyed
+// with the constant.
+func (g *Generator) testAvalancheEffect(constant word.Word) float64 {
+	return averageAvalancheScore(g.runAvalancheTests(constant, g.stream(analysisStream)))
 }
 
-func (g *Generator) testAvalancheEffect(constant uint32) float64 {
+// testSimpleAvalanche scores the word-level rc6Transform and returns its
+// strict avalanche and bit independence matrices.
+func (g *Generator) testSimpleAvalanche(constant word.Word, rng io.Reader) (float64, *SACMatrix, *BICMatrix, error) {
 	var totalChanges float64
 	testCases := g.config.AvalancheTestCases
+	w := g.wordSize()
 
-	for i := 0; i < testCases; i++ {
-		// Generate random input
-		var input uint32
-		for j := 0; j < 4; j++ {
-			b := make([]byte, 1)
-			rand.Read(b)
-			input = (input << 8) | uint32(b[0])
+	// Generate random inputs and their outputs
+	inputs := make([]word.Word, testCases)
+	outputs := make([]word.Word, testCases)
+	for i := range inputs {
+		input, err := g.randomWord(rng)
+		if err != nil {
+			return 0, nil, nil, err
 		}
+		inputs[i] = input
+		outputs[i] = g.rc6Transform(inputs[i], constant)
+	}
 
-		// Test each bit position
-		for bitPos := 0; bitPos < 32; bitPos++ {
-			// Flip one bit
-			modifiedInput := input ^ (1 << uint(bitPos))
-
-			// Apply RC6-like transformation
-			result1 := g.rc6Transform(input, constant)
-			result2 := g.rc6Transform(modifiedInput, constant)
-
-			// Count changed bits in output
-			changes := bits.OnesCount32(result1 ^ result2)
-			totalChanges += float64(changes)
+	// Flip each bit position of every input and collect the changed
+	// output bits
+	sac, bic := avalancheMatrices(w, func(bitPos uint, yield func(word.Word)) {
+		for i, input := range inputs {
+			modifiedInput := input.Xor(word.FromUint64(1).Lsh(bitPos, w))
+			diff := outputs[i].Xor(g.rc6Transform(modifiedInput, constant))
+			totalChanges += float64(diff.OnesCount())
+			yield(diff)
 		}
-	}
+	})
 
 	// Average changes per bit flip (normalize to 0-1 range)
-	return totalChanges / float64(testCases*32*32)
+	return totalChanges / float64(testCases*int(w)*int(w)), sac, bic, nil
 }
 
-func (g *Generator) compareOutputs(input1, input2 []byte, constant uint32) int {
-	result1 := g.encryptionTest(input1, constant)
-	result2 := g.encryptionTest(input2, constant)
+// testCipherAvalanche measures plaintext avalanche of RC6-w/r/b with the
+// constant substituted for P (paired with the standard Q) and for Q (paired
+// with the standard P). Each test case flips one plaintext bit, cycling
+// through every bit position of the block. It returns the number of output
+// bits that changed and the number of output bits observed, with the
+// strict avalanche and bit independence matrices by bit position within
+// the words of the block: row i collects every flip of bit i of a
+// plaintext word, column j bit j of one ciphertext word. Each flip adds
+// one sample to its row, from the next ciphertext word on each pass over
+// the block, since the words of one ciphertext are not independent samples.
+func (g *Generator) testCipherAvalanche(constant word.Word, rounds int, rng io.Reader) (int, int, *SACMatrix, *BICMatrix, error) {
+	w := g.wordSize()
+	p, q, err := rc6.MagicConstants(w)
+	if err != nil {
+		return 0, 0, nil, nil, err
+	}
+	roles := [][2]word.Word{
+		{constant, q},
+		{p, constant},
+	}
+
+	key := make([]byte, g.config.KeyBytes)
+	if _, err := io.ReadFull(rng, key); err != nil {
+		return 0, 0, nil, nil, fmt.Errorf("random key generation failed: %w", err)
+	}
+
+	changes, total := 0, 0
+	diffs := make([][]word.Word, w)
+	wordBytes := int(w / 8)
+	for _, role := range roles {
+		cipher, err := rc6.New(key, w, rounds, role[0], role[1])
+		if err != nil {
+			return 0, 0, nil, nil, err
+		}
+
+		blockBits := cipher.BlockSize() * 8
+		plaintexts := make([]byte, g.config.AvalancheTestCases*cipher.BlockSize())
+		if _, err := io.ReadFull(rng, plaintexts); err != nil {
+			return 0, 0, nil, nil, fmt.Errorf("random plaintext generation failed: %w", err)
+		}
 
-	differences := 0
-	for i := 0; i < len(result1); i++ {
-		diff := result1[i] ^ result2[i]
-		differences += bits.OnesCount8(uint8(diff))
+		out1 := make([]byte, cipher.BlockSize())
+		out2 := make([]byte, cipher.BlockSize())
+		modified := make([]byte, cipher.BlockSize())
+		for i := 0; i < g.config.AvalancheTestCases; i++ {
+			input := plaintexts[i*cipher.BlockSize() : (i+1)*cipher.BlockSize()]
+			copy(modified, input)
+			bitPos := i % blockBits
+			modified[bitPos/8] ^= 1 << uint(bitPos%8)
+
+			cipher.Encrypt(out1, input)
+			cipher.Encrypt(out2, modified)
+			for j := range out1 {
+				out2[j] ^= out1[j]
+				changes += bits.OnesCount8(out2[j])
+			}
+			total += blockBits
+
+			row := uint(bitPos) % w
+			j := (i / blockBits) % (len(out2) / wordBytes) * wordBytes
+			diffs[row] = append(diffs[row], loadWord(out2[j:j+wordBytes], w))
+		}
 	}
-	return differences
+
+	sac, bic := avalancheMatrices(w, func(bitPos uint, yield func(word.Word)) {
+		for _, diff := range diffs[bitPos] {
+			yield(diff)
+		}
+	})
+	return changes, total, sac, bic, nil
 }
 
-func (g *Generator) encryptionTest(input []byte, constant uint32) []byte {
-	output := make([]byte, len(input))
-	for i := 0; i < len(input); i++ {
-		output[i] = input[i] ^ byte(constant>>(uint(i%4)*8))
+// averageAvalancheScore combines the per-round-count avalanche results into
+// the single score stored on a candidate.
+func averageAvalancheScore(tests []AvalancheTest) float64 {
+	if len(tests) == 0 {
+		return 0
+	}
+	var total float64
+	for _, test := range tests {
+		total += test.Score
 	}
-	return output
+	return total / float64(len(tests))
+}
+
+// Accepts reports whether candidate passes the rules applied to every
+// generated candidate.
+func (g *Generator) Accepts(candidate ConstantCandidate) bool {
+	return g.validateCandidate(candidate)
 }
 
 func (g *Generator) validateCandidate(candidate ConstantCandidate) bool {
+	return g.validator.Validate(candidate) == ""
+}
+
+// RejectionReason returns why the generator's Validator rejects candidate,
+// or "" if it would be accepted.
+func (g *Generator) RejectionReason(candidate ConstantCandidate) string {
+	return g.validator.Validate(candidate)
+}
+
+// rejectionReason is the default validation: the first criterion candidate
+// fails, or "" if it passes them all.
+func (g *Generator) rejectionReason(candidate ConstantCandidate) string {
 	if candidate.BitDistribution < g.config.MinBitDistribution ||
 		candidate.BitDistribution > g.config.MaxBitDistribution {
-		return false
+		return RejectBitDistribution
 	}
 
 	if candidate.AvalancheScore < g.config.MinAvalancheScore {
-		return false
+		return RejectAvalanche
 	}
 
-	if candidate.HammingWeight < 12 || candidate.HammingWeight > 20 {
-		return false
+	if reason := g.avalancheCriteriaReason(candidate.TestResults.AvalancheTests); reason != "" {
+		return reason
 	}
 
-	if candidate.EntropyScore < 1.5 {
-		return false
+	if reason := g.cryptanalysisReason(candidate); reason != "" {
+		return reason
+	}
+
+	minWeight, maxWeight := g.hammingWeightBounds()
+	if candidate.HammingWeight < minWeight || candidate.HammingWeight > maxWeight {
+		return RejectHammingWeight
+	}
+
+	stats := g.statisticalTests()
+	if candidate.EntropyScore < stats.MinEntropyScore || candidate.EntropyScore > stats.MaxEntropyScore {
+		return RejectEntropy
 	}
 
 	for _, test := range candidate.TestResults.WeakKeyTests {
 		if !test.Passed {
-			return false
+			return weakKeyReason(test.Pattern)
 		}
 	}
 
-	return true
+	// Reject candidates that could never pass the final statistical check
+	if len(candidate.TestResults.StatisticalTests) > 0 &&
+		!g.verifyTestResults(candidate.TestResults.StatisticalTests) {
+		return RejectStatistical
+	}
+
+	// Derived constants are not prime unless the rule makes them so
+	if g.config.RequirePrime {
+		for _, test := range candidate.TestResults.PrimalityTests {
+			if !test.Passed {
+				return RejectPrimality
+			}
+		}
+	}
+
+	return ""
 }
 
-func (g *Generator) selectBestConstants(candidates []ConstantCandidate) (ConstantCandidate, ConstantCandidate) {
-	if len(candidates) < 2 {
-		panic("insufficient candidates")
+// maxSelectionSteps bounds the search for a pairwise different set of
+// constants, which is exponential in the worst case.
+const maxSelectionSteps = 1000000
+
+// selectBestConstants picks n ranked candidates so that every pair is
+// sufficiently different. Of all such sets it returns the one that is best
+// in rank order: the best-ranked candidate that is part of any valid set,
+// then the best remaining one, and so on. The next best candidates that
+// were not selected are returned as alternates.
+func (g *Generator) selectBestConstants(ranked []RankedCandidate, n int) ([]ConstantCandidate, []RankedCandidate, error) {
+	if len(ranked) < n {
+		return nil, nil, fmt.Errorf("insufficient candidates: got %d, need %d", len(ranked), n)
 	}
 
-	// Pre-calculate scores to avoid repeated calculations
-	type scoredCandidate struct {
-		candidate ConstantCandidate
-		score     float64
+	chosen := make([]int, 0, n)
+	steps := 0
+	var search func(from int) bool
+	search = func(from int) bool {
+		if len(chosen) == n {
+			return true
+		}
+		// Leave enough candidates to fill the remaining names
+		for i := from; i <= len(ranked)-(n-len(chosen)); i++ {
+			if steps++; steps > maxSelectionSteps {
+				return false
+			}
+			if !g.differentFromAll(ranked[i].Candidate, ranked, chosen) {
+				continue
+			}
+			chosen = append(chosen, i)
+			if search(i + 1) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+	if !search(0) {
+		if steps > maxSelectionSteps {
+			return nil, nil, fmt.Errorf("no %d pairwise different constants found among %d candidates within %d steps",
+				n, len(ranked), maxSelectionSteps)
+		}
+		return nil, nil, fmt.Errorf("no %d pairwise different constants among %d candidates", n, len(ranked))
+	}
+
+	selected := make([]ConstantCandidate, n)
+	picked := make(map[int]bool, n)
+	for i, r := range chosen {
+		selected[i] = ranked[r].Candidate
+		picked[r] = true
 	}
 
-	// Allocate with capacity
-	scored := make([]scoredCandidate, 0, len(candidates))
+	var alternates []RankedCandidate
+	for i, r := range ranked {
+		if len(alternates) == g.config.Alternates {
+			break
+		}
+		if !picked[i] {
+			alternates = append(alternates, r)
+		}
+	}
+
+	return selected, alternates, nil
+}
 
-	// Calculate scores once
+// rankCandidates orders candidates best first according to the selection
+// strategy. By default that is by score, keeping index order between ties.
+func (g *Generator) rankCandidates(candidates []ConstantCandidate) []RankedCandidate {
+	if g.config.SelectionStrategy == StrategyPareto {
+		return g.rankPareto(candidates)
+	}
+
+	// Pre-calculate scores to avoid repeated calculations
+	ranked := make([]RankedCandidate, 0, len(candidates))
 	for _, c := range candidates {
-		scored = append(scored, scoredCandidate{
-			candidate: c,
-			score:     g.calculateScore(c),
-		})
+		ranked = append(ranked, RankedCandidate{Candidate: c, Score: g.scorer.Score(c)})
 	}
 
-	// Sort with pre-calculated scores
-	sort.Slice(scored, func(i, j int) bool {
-		return scored[i].score > scored[j].score
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Score > ranked[j].Score
 	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+	return ranked
+}
 
-	// Select best pair
-	bestP := scored[0].candidate
-	for _, sc := range scored[1:] {
-		if g.areSufficientlyDifferent(bestP, sc.candidate) {
-			return bestP, sc.candidate
+// differentFromAll reports whether c is sufficiently different from each of
+// the chosen ranked candidates.
+func (g *Generator) differentFromAll(c ConstantCandidate, ranked []RankedCandidate, chosen []int) bool {
+	for _, i := range chosen {
+		if !g.areSufficientlyDifferent(ranked[i].Candidate, c) {
+			return false
 		}
 	}
-
-	// Fallback if no sufficiently different pair found
-	return bestP, scored[1].candidate
+	return true
 }
 
 func (g *Generator) calculateScore(candidate ConstantCandidate) float64 {
 	// Weighted scoring of various properties
+	weights := g.scoringWeights()
 	bitDistScore := 1.0 - math.Abs(0.5-candidate.BitDistribution)
-	avalancheWeight := 2.0 // Give more weight to avalanche effect
-	entropyWeight := 1.5
 
-	return (bitDistScore +
-		avalancheWeight*candidate.AvalancheScore +
-		entropyWeight*(candidate.EntropyScore/2.0)) /
-		(1.0 + avalancheWeight + entropyWeight)
+	return (weights.BitDistribution*bitDistScore +
+		weights.Avalanche*candidate.AvalancheScore +
+		weights.Entropy*candidate.EntropyScore) /
+		(weights.BitDistribution + weights.Avalanche + weights.Entropy)
 }
 
 func (g *Generator) areSufficientlyDifferent(a, b ConstantCandidate) bool {
 	// Check if constants are sufficiently different
-	diff := a.Value ^ b.Value
-	hammingDistance := bits.OnesCount32(diff)
+	w := g.wordSize()
+	diff := a.Value.Xor(b.Value)
+	hammingDistance := diff.OnesCount()
 
-	// Should have at least 12 bits different
-	if hammingDistance < 12 {
+	if hammingDistance < g.minHammingDistance() {
 		return false
 	}
 
-	// Should not be related by simple shifts
-	for i := 1; i < 32; i++ {
-		if a.Value == b.Value<<uint(i) || a.Value == b.Value>>uint(i) {
+	// Should not be related, even approximately, by simple shifts
+	minDiff := g.minDifferentBits()
+	for i := uint(1); i < w; i++ {
+		if a.Value.Xor(b.Value.Lsh(i, w)).OnesCount() < minDiff ||
+			a.Value.Xor(b.Value.Rsh(i)).OnesCount() < minDiff {
 			return false
 		}
 	}
 
+	// The pairwise analysis is the costly part, so it runs last
+	if g.config.PairAnalysis.Enabled && len(g.pairAnalysis(a.Value, b.Value).Relations) > 0 {
+		return false
+	}
+
 	return true
 }
 
@@ -536,138 +1103,102 @@ func (g *Generator) saveResults(result *GenerationResult) error {
 	return nil
 }
 
-func (g *Generator) runTests(candidate ConstantCandidate) TestResults {
+func (g *Generator) runTests(candidate ConstantCandidate, rng io.Reader) TestResults {
 	results := TestResults{
 		PrimalityTests: g.runPrimalityTests(candidate.Value),
-		AvalancheTests: g.runAvalancheTests(candidate.Value),
+		AvalancheTests: g.runAvalancheTests(candidate.Value, rng),
 		WeakKeyTests:   g.runWeakKeyTests(candidate.Value),
 	}
 
 	// Add statistical tests when enabled in config
 	if g.config.StatisticalAnalysis {
 		results.StatisticalTests = g.runAllStatisticalTests(candidate.Value)
+		results.Aggregate = g.aggregateSignificance(results.StatisticalTests)
 	}
 
 	return results
 }
 
-func (g *Generator) runPrimalityTests(value uint32) []PrimalityTest {
-	tests := []PrimalityTest{
-		{
-			Method:  "Miller-Rabin",
-			Passed:  g.isPrime(value),
-			Details: fmt.Sprintf("Tested with bases [2, 7, 61]"),
-		},
-	}
-	return tests
-}
-
-func (g *Generator) runAvalancheTests(value uint32) []AvalancheTest {
+func (g *Generator) runPrimalityTests(value word.Word) []PrimalityTest {
 	start := time.Now()
-	score := g.testAvalancheEffect(value)
+	details := fmt.Sprintf("Baillie-PSW with %d Miller-Rabin rounds", probablePrimeRounds)
+	if value.Hi == 0 {
+		details = fmt.Sprintf("Tested with bases %v", millerRabinBasesFor(value.Lo))
+	}
 
-	return []AvalancheTest{
+	tests := []PrimalityTest{
 		{
-			Score:    score,
-			Changes:  int(score * float64(g.config.AvalancheTestCases*128)),
-			Total:    g.config.AvalancheTestCases * 128,
+			Method:   "Miller-Rabin",
+			Passed:   g.isPrime(value),
 			Duration: time.Since(start),
+			Details:  details,
 		},
 	}
-}
-
-func (g *Generator) runWeakKeyTests(value uint32) []WeakKeyTest {
-	tests := []WeakKeyTest{
-		{
-			Pattern: "Low Hamming Weight",
-			Passed:  bits.OnesCount32(value) >= 12,
-		},
-		{
-			Pattern: "Simple Bit Pattern",
-			Passed:  !g.hasSimpleBitPattern(value),
-		},
+	if class := g.primeClass(); class != PrimeAny {
+		start := time.Now()
+		passed, details := g.primeClassTest(value, class)
+		tests = append(tests, PrimalityTest{
+			Method:   "Prime Class",
+			Class:    class,
+			Passed:   passed,
+			Duration: time.Since(start),
+			Details:  details,
+		})
 	}
 	return tests
 }
 
-func (g *Generator) hasSimpleBitPattern(value uint32) bool {
-	// Check for simple repeating patterns
-	patterns := []uint32{
-		0xAAAAAAAA, // alternating bits
-		0x55555555, // alternating bits
-		0x33333333, // repeating pairs
-		0xCCCCCCCC, // repeating pairs
-		0x0F0F0F0F, // repeating quads
-		0xF0F0F0F0, // repeating quads
-	}
-
-	for _, pattern := range patterns {
-		if value == pattern || value == ^pattern {
-			return true
-		}
-	}
-
-	return false
-}
-
-func (g *Generator) testConstantCorrelation(p, q uint32) float64 {
-	// Convert to bit arrays
-	pBits := make([]int, 32)
-	qBits := make([]int, 32)
-
-	for i := 0; i < 32; i++ {
-		if p&(1<<uint(i)) != 0 {
-			pBits[i] = 1
+func (g *Generator) runAvalancheTests(value word.Word, rng io.Reader) []AvalancheTest {
+	if g.config.Transform == TransformSimple {
+		start := time.Now()
+		score, sac, bic, err := g.testSimpleAvalanche(value, rng)
+		if err != nil {
+			g.logger.Error("Avalanche test failed:", err)
+			return nil
 		}
-		if q&(1<<uint(i)) != 0 {
-			qBits[i] = 1
+		w := int(g.wordSize())
+		total := g.config.AvalancheTestCases * w * w
+		return []AvalancheTest{
+			{
+				Transform: TransformSimple,
+				Score:     score,
+				Changes:   int(score * float64(total)),
+				Total:     total,
+				SAC:       sac,
+				BIC:       bic,
+				Duration:  time.Since(start),
+			},
 		}
 	}
 
-	// Calculate correlation coefficient
-	var sum, pSum, qSum, pSqSum, qSqSum float64
-	n := float64(32)
-
-	for i := 0; i < 32; i++ {
-		pVal := float64(pBits[i])
-		qVal := float64(qBits[i])
-		sum += pVal * qVal
-		pSum += pVal
-		qSum += qVal
-		pSqSum += pVal * pVal
-		qSqSum += qVal * qVal
-	}
-
-	numerator := sum - (pSum * qSum / n)
-	denominator := ((pSqSum - (pSum * pSum / n)) * (qSqSum - (qSum * qSum / n)))
-
-	if denominator == 0 {
-		return 1.0
+	// Score against reduced-round and full-round encryptions
+	rounds := []int{g.config.CipherRounds}
+	if g.config.ReducedRounds > 0 && g.config.ReducedRounds < g.config.CipherRounds {
+		rounds = []int{g.config.ReducedRounds, g.config.CipherRounds}
 	}
-	return numerator / denominator
-}
-
-func (g *Generator) testCombinedAvalancheEffect(p, q uint32) float64 {
-	var totalChanges int
-	testCases := g.config.AvalancheTestCases
-
-	for i := 0; i < testCases; i++ {
-		// Test how changes in input affect both P and Q operations
-		input := uint32(i)
-		modified := input ^ 1 // Flip lowest bit
 
-		result1 := (input * p) ^ (input * q)
-		result2 := (modified * p) ^ (modified * q)
-
-		changes := 0
-		diff := result1 ^ result2
-		for diff != 0 {
-			changes += int(diff & 1)
-			diff >>= 1
+	tests := make([]AvalancheTest, 0, len(rounds))
+	for _, r := range rounds {
+		start := time.Now()
+		changes, total, sac, bic, err := g.testCipherAvalanche(value, r, rng)
+		if err != nil {
+			g.logger.Error("Avalanche test failed:", err)
+			continue
 		}
-
-		totalChanges += changes
+		score := 0.0
+		if total > 0 {
+			score = float64(changes) / float64(total)
+		}
+		tests = append(tests, AvalancheTest{
+			Transform: TransformRC6,
+			Rounds:    r,
+			Score:     score,
+			Changes:   changes,
+			Total:     total,
+			SAC:       sac,
+			BIC:       bic,
+			Duration:  time.Since(start),
+		})
 	}
-
-	return float64(totalChanges) / float64(testCases*32)
+	return tests
 }
@@ -1,9 +1,26 @@
 package constants
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
+
+	"primer/word"
 )
 
+// testGenerationConfig returns a small, seeded, quiet configuration for
+// tests that run Generate end to end.
+func testGenerationConfig(seed string) Config {
+	config := DefaultConfig()
+	config.Seed = seed
+	config.NumCandidates = 24
+	config.AvalancheTestCases = 50
+	config.ParallelWorkers = 3
+	config.DetailedLogging = false
+	config.ResultsFile = ""
+	return config
+}
+
 func TestNewGenerator(t *testing.T) {
 	config := DefaultConfig()
 	generator := NewGenerator(config)
@@ -11,7 +28,7 @@ func TestNewGenerator(t *testing.T) {
 	if generator == nil {
 		t.Error("NewGenerator returned nil")
 	}
-	if generator.config != config {
+	if !reflect.DeepEqual(generator.config, config) {
 		t.Error("Config not properly set")
 	}
 	if generator.logger == nil {
@@ -74,6 +91,221 @@ func TestGenerate(t *testing.T) {
 	}
 }
 
+func TestIsPrime(t *testing.T) {
+	generator := NewGenerator(DefaultConfig())
+
+	mustParse := func(s string) word.Word {
+		v, err := word.Parse(s)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", s, err)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name  string
+		value word.Word
+		want  bool
+	}{
+		{"Two", word.FromUint64(2), true},
+		{"Even", word.FromUint64(0x9E3779B8), false},
+		{"Carmichael 561", word.FromUint64(561), false},
+		{"Largest 16-bit prime", word.FromUint64(65521), true},
+		{"Largest 32-bit prime", word.FromUint64(4294967291), true},
+		{"Strong pseudoprime to bases 2, 3, 5, 7", word.FromUint64(3215031751), false},
+		{"Mersenne 2^61-1", word.FromUint64(1<<61 - 1), true},
+		{"Largest 64-bit prime", word.FromUint64(18446744073709551557), true},
+		{"64-bit semiprime", word.FromUint64(4294967291 * 4294967279), false},
+		{"Mersenne 2^127-1", mustParse("0x7FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"), true},
+		{"128-bit composite", mustParse("0xFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := generator.isPrime(tt.value); got != tt.want {
+				t.Errorf("isPrime(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePrimeWordSizes(t *testing.T) {
+	for _, w := range []int{16, 32, 64, 128} {
+		config := DefaultConfig()
+		config.WordSize = w
+		generator := NewGenerator(config)
+
+		value, err := generator.generatePrime(generator.stream(0))
+		if err != nil {
+			t.Fatalf("WordSize %d: generatePrime() error = %v", w, err)
+		}
+		if value.BitLen() > w {
+			t.Errorf("WordSize %d: %v exceeds word size", w, value)
+		}
+		if !generator.isPrime(value) {
+			t.Errorf("WordSize %d: %v is not prime", w, value)
+		}
+	}
+}
+
+func TestAvalancheWordSizes(t *testing.T) {
+	for _, w := range []int{16, 32, 64, 128} {
+		for _, transform := range []string{TransformRC6, TransformSimple} {
+			config := DefaultConfig()
+			config.WordSize = w
+			config.Transform = transform
+			config.AvalancheTestCases = 50
+			generator := NewGenerator(config)
+
+			p, _ := word.Parse("0xB7E151628AED2A6ABF7158809CF4F3C7")
+			score := generator.testAvalancheEffect(p.Truncate(uint(w)))
+			if score <= 0 || score >= 1 {
+				t.Errorf("WordSize %d %s: avalanche score %.4f out of range", w, transform, score)
+			}
+		}
+	}
+}
+
+func TestSeededCandidatesReproducible(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = "primer-reproducibility"
+	config.AvalancheTestCases = 100
+
+	a := NewGenerator(config)
+	b := NewGenerator(config)
+
+	for _, index := range []int{0, 1, 7} {
+		ca, err := a.generateCandidate(index)
+		if err != nil {
+			t.Fatalf("generateCandidate(%d) error = %v", index, err)
+		}
+		cb, err := b.generateCandidate(index)
+		if err != nil {
+			t.Fatalf("generateCandidate(%d) error = %v", index, err)
+		}
+
+		if ca.Value != cb.Value {
+			t.Errorf("candidate %d: values differ: %v vs %v", index, ca.Value, cb.Value)
+		}
+		if ca.AvalancheScore != cb.AvalancheScore {
+			t.Errorf("candidate %d: avalanche scores differ: %v vs %v", index, ca.AvalancheScore, cb.AvalancheScore)
+		}
+		for i := range ca.TestResults.StatisticalTests {
+			if ca.TestResults.StatisticalTests[i].Name != cb.TestResults.StatisticalTests[i].Name {
+				t.Errorf("candidate %d: statistical test order differs", index)
+			}
+		}
+	}
+
+	first, _ := a.generateCandidate(0)
+	second, _ := a.generateCandidate(1)
+	if first.Value == second.Value {
+		t.Error("different candidate indices produced the same value")
+	}
+
+	config.Seed = "another seed"
+	other, _ := NewGenerator(config).generateCandidate(0)
+	if other.Value == first.Value {
+		t.Error("different seeds produced the same value")
+	}
+}
+
+func TestConfiguredThresholds(t *testing.T) {
+	config := DefaultConfig()
+	config.AvalancheTestCases = 50
+	config.RequirePrime = false
+	g := NewGenerator(config)
+
+	c := g.AnalyzeConstant(RC6_P)
+	if !g.validateCandidate(c) {
+		t.Fatal("RC6 P rejected with the default thresholds")
+	}
+
+	// RC6 P has Hamming weight 17
+	g.config.ValidationCriteria.MaxHammingWeight = 16
+	if g.validateCandidate(c) {
+		t.Error("candidate accepted above MaxHammingWeight")
+	}
+	g.config.ValidationCriteria.MaxHammingWeight = 0
+
+	g.config.StatisticalTests.MinEntropyScore = 0.999
+	if g.validateCandidate(c) {
+		t.Error("candidate accepted below MinEntropyScore")
+	}
+	g.config.StatisticalTests = config.StatisticalTests
+
+	// RC6 P and Q differ in 15 bits
+	p, q := ConstantCandidate{Value: RC6_P}, ConstantCandidate{Value: RC6_Q}
+	if !g.areSufficientlyDifferent(p, q) {
+		t.Error("RC6 P and Q not sufficiently different by default")
+	}
+	g.config.ValidationCriteria.MinHammingDistance = 16
+	if g.areSufficientlyDifferent(p, q) {
+		t.Error("pair accepted below MinHammingDistance")
+	}
+	g.config.ValidationCriteria.MinHammingDistance = 0
+
+	// RC6 P deviates from balance by 1/32, a frequency p-value of 0.7237
+	g.config.StatisticalTests.MinPValue = 0.75
+	if g.runBitFrequencyTest(RC6_P).Passed {
+		t.Error("frequency test ignored MinPValue")
+	}
+	g.config.StatisticalTests = config.StatisticalTests
+
+	g.config.RandomnessTests.EnableExtendedTests = false
+	if got := len(g.runAllStatisticalTests(RC6_P)); got != 2 {
+		t.Errorf("got %d statistical tests without extended tests, want 2", got)
+	}
+}
+
+func TestVerifyTestResultsWeights(t *testing.T) {
+	config := DefaultConfig()
+	g := NewGenerator(config)
+	tests := []StatisticalTest{
+		{Name: bitFrequencyTestName, Passed: true},
+		{Name: runsTestName, Passed: true},
+		{Name: serialTestName, Passed: false},
+		{Name: autocorrelationTestName, Passed: true},
+		{Name: linearComplexityTestName, Passed: true},
+	}
+
+	if !g.verifyTestResults(tests) {
+		t.Error("one failure in five rejected with MaxFailedTestFraction 0.2")
+	}
+	g.config.RandomnessTests.SerialTestWeight = 2
+	if g.verifyTestResults(tests) {
+		t.Error("doubly weighted failure accepted")
+	}
+	g.config.RandomnessTests.SerialTestWeight = 0
+	g.config.ValidationCriteria.MaxFailedTestFraction = 0
+	if !g.verifyTestResults(tests) {
+		t.Error("failure of a zero-weight test counted")
+	}
+}
+
+func TestGenerateBatchSizeIndependent(t *testing.T) {
+	var selected []string
+	for _, batch := range []int{1, 7} {
+		config := DefaultConfig()
+		config.Seed = "primer-batches"
+		config.NumCandidates = 20
+		config.AvalancheTestCases = 50
+		config.ParallelWorkers = 3
+		config.BatchSize = batch
+		config.DetailedLogging = false
+		config.ResultsFile = ""
+
+		result, err := NewGenerator(config).Generate()
+		if err != nil {
+			t.Fatalf("BatchSize %d: Generate() error = %v", batch, err)
+		}
+		selected = append(selected, result.SelectedP.Value.String()+" "+result.SelectedQ.Value.String())
+	}
+	if selected[0] != selected[1] {
+		t.Errorf("selection depends on BatchSize: %s vs %s", selected[0], selected[1])
+	}
+}
+
 // TODO: This can't possibly be a real test
 // func TestGenerateCandidate(t *testing.T) {
 // 	generator := NewGenerator(DefaultConfig())
@@ -148,7 +380,7 @@ func BenchmarkGenerateCandidate(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		_, err := generator.generateCandidate()
+		_, err := generator.generateCandidate(i)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -157,7 +389,7 @@ func BenchmarkGenerateCandidate(b *testing.B) {
 
 func BenchmarkIsPrime(b *testing.B) {
 	generator := NewGenerator(DefaultConfig())
-	value := uint32(104729) // A prime number
+	value := word.FromUint64(104729) // A prime number
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
@@ -186,3 +418,139 @@ func BenchmarkRC6Constants(b *testing.B) {
 		}
 	})
 }
+
+func TestSelectBestConstants(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = "primer-select"
+	config.AvalancheTestCases = 50
+	config.RequirePrime = false
+	g := NewGenerator(config)
+
+	var candidates []ConstantCandidate
+	for i := 0; i < 12; i++ {
+		c, err := g.generateCandidate(i)
+		if err != nil {
+			t.Fatalf("generateCandidate(%d): %v", i, err)
+		}
+		candidates = append(candidates, c)
+	}
+	ranked := g.rankCandidates(candidates)
+
+	// firstValid enumerates rank combinations in lexicographic order
+	firstValid := func(n int) []int {
+		combo := make([]int, n)
+		var walk func(pos, from int) bool
+		walk = func(pos, from int) bool {
+			if pos == n {
+				for i := 0; i < n; i++ {
+					for j := i + 1; j < n; j++ {
+						if !g.areSufficientlyDifferent(ranked[combo[i]].Candidate, ranked[combo[j]].Candidate) {
+							return false
+						}
+					}
+				}
+				return true
+			}
+			for i := from; i < len(ranked); i++ {
+				combo[pos] = i
+				if walk(pos+1, i+1) {
+					return true
+				}
+			}
+			return false
+		}
+		if !walk(0, 0) {
+			return nil
+		}
+		return combo
+	}
+
+	for _, n := range []int{2, 3, 4, 6} {
+		t.Run(fmt.Sprintf("%d constants", n), func(t *testing.T) {
+			g.config.ConstantNames = make([]string, n)
+			for i := range g.config.ConstantNames {
+				g.config.ConstantNames[i] = fmt.Sprintf("K%d", i)
+			}
+			g.config.Alternates = 3
+
+			selected, alternates, err := g.selectBestConstants(ranked, n)
+			want := firstValid(n)
+			if want == nil {
+				if err == nil {
+					t.Fatal("selectBestConstants() succeeded without a valid set")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("selectBestConstants() error = %v", err)
+			}
+			for i, r := range want {
+				if selected[i].Index != ranked[r].Candidate.Index {
+					t.Errorf("constant %d is candidate %d, want %d", i, selected[i].Index, ranked[r].Candidate.Index)
+				}
+			}
+
+			picked := make(map[int]bool)
+			for _, c := range selected {
+				picked[c.Index] = true
+			}
+			if len(alternates) != 3 {
+				t.Fatalf("got %d alternates, want 3", len(alternates))
+			}
+			for i, alt := range alternates {
+				if picked[alt.Candidate.Index] {
+					t.Errorf("alternate %d was also selected", alt.Candidate.Index)
+				}
+				if i > 0 && (alt.Rank <= alternates[i-1].Rank || alt.Score > alternates[i-1].Score) {
+					t.Errorf("alternates out of order: %+v then %+v", alternates[i-1], alt)
+				}
+			}
+		})
+	}
+
+	// Identical candidates can never be pairwise different
+	g.config.ConstantNames = []string{"P", "Q"}
+	same := []ConstantCandidate{candidates[0], candidates[0], candidates[0]}
+	if _, _, err := g.selectBestConstants(g.rankCandidates(same), 2); err == nil {
+		t.Error("selectBestConstants() accepted identical constants")
+	}
+}
+
+func TestGenerateNamedConstants(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = "primer-named"
+	config.NumCandidates = 40
+	config.AvalancheTestCases = 50
+	config.DetailedLogging = false
+	config.ResultsFile = ""
+	config.ConstantNames = []string{"K0", "K1", "K2", "K3"}
+	config.Alternates = 2
+
+	result, err := NewGenerator(config).Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(result.Selected) != 4 {
+		t.Fatalf("got %d constants, want 4", len(result.Selected))
+	}
+	for i, c := range result.Selected {
+		if c.Name != config.ConstantNames[i] {
+			t.Errorf("constant %d is named %q, want %q", i, c.Name, config.ConstantNames[i])
+		}
+	}
+	if result.SelectedP.Value != result.Selected[0].Candidate.Value || result.SelectedQ.Value != result.Selected[1].Candidate.Value {
+		t.Error("SelectedP and SelectedQ are not the first two constants")
+	}
+	if len(result.Alternates) != 2 {
+		t.Errorf("got %d alternates, want 2", len(result.Alternates))
+	}
+
+	g := NewGenerator(result.Config)
+	if v := g.Verify(result); !v.Passed {
+		for _, check := range v.Checks {
+			if !check.Passed {
+				t.Errorf("Verify %s %s: %s", check.Constant, check.Name, check.Details)
+			}
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package constants
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"slices"
+
+	"primer/rc6"
+	"primer/special"
+	"primer/word"
+)
+
+// Names of the key-schedule tests.
+const (
+	keyAvalancheTestName    = "Key Avalanche"
+	scheduleBalanceTestName = "Schedule Bit Balance"
+	relatedKeyTestName      = "Related-Key Collisions"
+	equivalentKeyTestName   = "Equivalent Keys"
+)
+
+// keyScheduleCounts accumulates the measurements over random keys.
+type keyScheduleCounts struct {
+	// flips counts the S bits that changed, by flipped key bit
+	flips []int
+	// ones counts the set bits of the base schedules, by S bit position
+	ones      []int
+	schedules int
+	// Related keys: S words compared, S words found equal, and related
+	// keys with an identical schedule
+	comparisons int
+	equalWords  int
+	collisions  int
+}
+
+func (c *keyScheduleCounts) add(o keyScheduleCounts) {
+	if c.flips == nil {
+		c.flips = make([]int, len(o.flips))
+		c.ones = make([]int, len(o.ones))
+	}
+	for i, n := range o.flips {
+		c.flips[i] += n
+	}
+	for i, n := range o.ones {
+		c.ones[i] += n
+	}
+	c.schedules += o.schedules
+	c.comparisons += o.comparisons
+	c.equalWords += o.equalWords
+	c.collisions += o.collisions
+}
+
+// runKeyScheduleTests expands KeySchedule.Keys random keys of KeyBytes
+// bytes with value as P (paired with the standard Q) and as Q (paired with
+// the standard P), and measures how the expanded key array S responds.
+// Without key bytes there is nothing to flip, and only the balance and
+// equivalent-key tests run.
+func (g *Generator) runKeyScheduleTests(value word.Word) ([]KeyScheduleTest, error) {
+	w := g.wordSize()
+	p, q, err := rc6.MagicConstants(w)
+	if err != nil {
+		return nil, err
+	}
+	roles := [][2]word.Word{
+		{value, q},
+		{p, value},
+	}
+	config := g.config.KeySchedule
+
+	keys, err := g.drawKeys(keyScheduleStream, config.Keys, g.config.KeyBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := parallelMap(len(roles)*len(keys), g.config.ParallelWorkers, func(i int) (keyScheduleCounts, error) {
+		return g.keyScheduleCounts(roles[i%len(roles)], keys[i/len(roles)])
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counts keyScheduleCounts
+	for _, result := range results {
+		counts.add(result)
+	}
+
+	var tests []KeyScheduleTest
+	if len(counts.flips) > 0 {
+		tests = append(tests, g.keyAvalancheTest(counts))
+	}
+	tests = append(tests, g.scheduleBalanceTest(counts))
+	if len(counts.flips) > 0 {
+		tests = append(tests, g.relatedKeyTest(counts))
+	}
+
+	equivalent := 0
+	for _, role := range roles {
+		pairs, err := g.equivalentKeyPairs(role, config.EquivalentKeyBits)
+		if err != nil {
+			return nil, err
+		}
+		equivalent += pairs
+	}
+	searched := len(roles) << config.EquivalentKeyBits
+	tests = append(tests, KeyScheduleTest{
+		Name:    equivalentKeyTestName,
+		Score:   1 - float64(2*equivalent)/float64(searched),
+		Passed:  equivalent == 0,
+		Details: fmt.Sprintf("%d equivalent key pairs among all %d-bit keys", equivalent, config.EquivalentKeyBits),
+	})
+	return tests, nil
+}
+
+// keySchedule returns the expanded key array of key under role's P and Q.
+func (g *Generator) keySchedule(key []byte, role [2]word.Word) ([]word.Word, error) {
+	cipher, err := rc6.New(key, g.wordSize(), g.config.CipherRounds, role[0], role[1])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.Schedule(), nil
+}
+
+// keyScheduleCounts measures one key under one role. The related keys are
+// the key with one bit flipped, which also gives the key avalanche, and
+// with two adjacent bits flipped.
+func (g *Generator) keyScheduleCounts(role [2]word.Word, key []byte) (keyScheduleCounts, error) {
+	w := int(g.wordSize())
+	base, err := g.keySchedule(key, role)
+	if err != nil {
+		return keyScheduleCounts{}, err
+	}
+
+	keyBits := 8 * len(key)
+	c := keyScheduleCounts{
+		flips:     make([]int, keyBits),
+		ones:      make([]int, len(base)*w),
+		schedules: 1,
+	}
+	for i, s := range base {
+		for h, v := range [2]uint64{s.Lo, s.Hi} {
+			for ; v != 0; v &= v - 1 {
+				c.ones[i*w+64*h+bits.TrailingZeros64(v)]++
+			}
+		}
+	}
+
+	related := make([]byte, len(key))
+	for b := 0; b < keyBits; b++ {
+		for width := 1; width <= 2 && b+width <= keyBits; width++ {
+			copy(related, key)
+			for k := b; k < b+width; k++ {
+				related[k/8] ^= 1 << uint(k%8)
+			}
+			s, err := g.keySchedule(related, role)
+			if err != nil {
+				return keyScheduleCounts{}, err
+			}
+
+			changed, equal := 0, 0
+			for i := range s {
+				d := s[i].Xor(base[i])
+				changed += d.OnesCount()
+				if d.IsZero() {
+					equal++
+				}
+			}
+			if width == 1 {
+				c.flips[b] += changed
+			}
+			c.comparisons += len(s)
+			c.equalWords += equal
+			if equal == len(s) {
+				c.collisions++
+			}
+		}
+	}
+	return c, nil
+}
+
+// keyAvalancheTest checks that flipping any one key bit changes each bit
+// of S with probability one half. The p-value is the Šidák-corrected
+// smallest two-sided p-value over the key bits.
+func (g *Generator) keyAvalancheTest(c keyScheduleCounts) KeyScheduleTest {
+	// Every flip of a key bit is compared over the whole of S
+	observed := float64(c.schedules * len(c.ones))
+	changed, worst, worstBit := 0, 0.5, 0
+	minPValue := 1.0
+	for b, flips := range c.flips {
+		changed += flips
+		fraction := float64(flips) / observed
+		if math.Abs(fraction-0.5) > math.Abs(worst-0.5) {
+			worst, worstBit = fraction, b
+		}
+		z := (2*float64(flips) - observed) / math.Sqrt(observed)
+		minPValue = math.Min(minPValue, special.NormalTwoSided(z))
+	}
+	pValue := -math.Expm1(float64(len(c.flips)) * math.Log1p(-minPValue))
+	mean := float64(changed) / (observed * float64(len(c.flips)))
+
+	return KeyScheduleTest{
+		Name:   keyAvalancheTestName,
+		Score:  mean,
+		PValue: pValue,
+		Passed: pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("%.4f of S bits change per key bit, worst key bit %d at %.4f (p-value: %.4f over %d key bits)",
+			mean, worstBit, worst, pValue, len(c.flips)),
+	}
+}
+
+// scheduleBalanceTest checks that every bit of S is set in half the
+// schedules, with a chi-square test over all bit positions.
+func (g *Generator) scheduleBalanceTest(c keyScheduleCounts) KeyScheduleTest {
+	w := int(g.wordSize())
+	n := float64(c.schedules)
+	chiSquare, worst, worstPosition := 0.0, 0.5, 0
+	for i, ones := range c.ones {
+		d := 2*float64(ones) - n
+		chiSquare += d * d / n
+		if fraction := float64(ones) / n; math.Abs(fraction-0.5) > math.Abs(worst-0.5) {
+			worst, worstPosition = fraction, i
+		}
+	}
+	pValue := special.ChiSquareSurvival(chiSquare, float64(len(c.ones)))
+
+	return KeyScheduleTest{
+		Name:   scheduleBalanceTestName,
+		Score:  pValue,
+		PValue: pValue,
+		Passed: pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("Chi-square: %.1f over %d bit positions of %d schedules, worst S[%d] bit %d set in %.4f (p-value: %.4f)",
+			chiSquare, len(c.ones), c.schedules, worstPosition/w, worstPosition%w, worst, pValue),
+	}
+}
+
+// relatedKeyTest checks that related keys share no more S words than
+// random schedules would: equal words are Poisson with mean 2^-w per
+// comparison. Any related key with the whole of S identical fails.
+func (g *Generator) relatedKeyTest(c keyScheduleCounts) KeyScheduleTest {
+	expected := float64(c.comparisons) * math.Ldexp(1, -int(g.wordSize()))
+	pValue := 1.0
+	if c.equalWords > 0 {
+		// P(X >= k) for a Poisson variable with mean expected
+		pValue = special.Igam(float64(c.equalWords), expected)
+	}
+
+	return KeyScheduleTest{
+		Name:   relatedKeyTestName,
+		Score:  pValue,
+		PValue: pValue,
+		Passed: c.collisions == 0 && pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("%d identical schedules, %d of %d S words equal (%.4g expected, p-value: %.4f)",
+			c.collisions, c.equalWords, c.comparisons, expected, pValue),
+	}
+}
+
+// equivalentKeyPairs expands every key of keyBits bits under role and
+// counts the pairs of keys with identical schedules. All keys have the
+// same length: RC6 pads the key with zero bytes, so keys that differ only
+// in trailing zero bytes of their last word are equivalent whatever the
+// constants.
+func (g *Generator) equivalentKeyPairs(role [2]word.Word, keyBits int) (int, error) {
+	key := make([]byte, (keyBits+7)/8)
+	expand := func(i uint64) ([]word.Word, error) {
+		for j := range key {
+			key[j] = byte(i >> (8 * uint(j)))
+		}
+		return g.keySchedule(key, role)
+	}
+
+	// Index the schedules by hash and only compare keys whose hashes match
+	seen := make(map[uint64][]uint64)
+	var buf [16]byte
+	pairs := 0
+	for i := uint64(0); i < 1<<keyBits; i++ {
+		s, err := expand(i)
+		if err != nil {
+			return 0, err
+		}
+		h := fnv.New64a()
+		for _, x := range s {
+			binary.LittleEndian.PutUint64(buf[:8], x.Lo)
+			binary.LittleEndian.PutUint64(buf[8:], x.Hi)
+			h.Write(buf[:])
+		}
+		sum := h.Sum64()
+		for _, j := range seen[sum] {
+			other, err := expand(j)
+			if err != nil {
+				return 0, err
+			}
+			if slices.Equal(s, other) {
+				pairs++
+			}
+		}
+		seen[sum] = append(seen[sum], i)
+	}
+	return pairs, nil
+}
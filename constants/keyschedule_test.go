@@ -0,0 +1,121 @@
+package constants
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"primer/word"
+)
+
+func keyScheduleConfig() Config {
+	config := DefaultConfig()
+	config.Seed = "key schedule"
+	config.KeySchedule = KeyScheduleConfig{Enabled: true, Keys: 8, EquivalentKeyBits: 10}
+	return config
+}
+
+func TestRunKeyScheduleTests(t *testing.T) {
+	names := []string{keyAvalancheTestName, scheduleBalanceTestName, relatedKeyTestName, equivalentKeyTestName}
+	for _, w := range []int{16, 32, 64, 128} {
+		config := keyScheduleConfig()
+		config.WordSize = w
+		g := NewGenerator(config)
+		value := RC6_P
+		if w == 16 {
+			value = word.FromUint64(0xB7E1)
+		}
+
+		tests, err := g.runKeyScheduleTests(value)
+		if err != nil {
+			t.Fatalf("w=%d: %v", w, err)
+		}
+		if len(tests) != len(names) {
+			t.Fatalf("w=%d: %d tests, want %d", w, len(tests), len(names))
+		}
+		for i, test := range tests {
+			if test.Name != names[i] {
+				t.Errorf("w=%d: test %d is %q, want %q", w, i, test.Name, names[i])
+			}
+			if !test.Passed {
+				t.Errorf("w=%d: %s failed: %s", w, test.Name, test.Details)
+			}
+		}
+		if score := tests[0].Score; score < 0.49 || score > 0.51 {
+			t.Errorf("w=%d: key avalanche %.4f, want about 0.5", w, score)
+		}
+		if !strings.HasPrefix(tests[2].Details, "0 identical schedules") {
+			t.Errorf("w=%d: %s", w, tests[2].Details)
+		}
+
+		again, err := NewGenerator(config).runKeyScheduleTests(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(again, tests) {
+			t.Errorf("w=%d: seeded key-schedule tests are not reproducible", w)
+		}
+	}
+}
+
+func TestKeyScheduleWithoutKeyBytes(t *testing.T) {
+	config := keyScheduleConfig()
+	config.KeyBytes = 0
+	tests, err := NewGenerator(config).runKeyScheduleTests(RC6_Q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 2 || tests[0].Name != scheduleBalanceTestName || tests[1].Name != equivalentKeyTestName {
+		t.Errorf("got %+v, want only the balance and equivalent-key tests", tests)
+	}
+}
+
+func TestKeyScheduleStatistics(t *testing.T) {
+	g := NewGenerator(keyScheduleConfig())
+	// 100 schedules of four bit positions, two key bits
+	counts := func(flips, ones []int) keyScheduleCounts {
+		return keyScheduleCounts{flips: flips, ones: ones, schedules: 100}
+	}
+
+	tests := []struct {
+		name   string
+		test   KeyScheduleTest
+		passed bool
+	}{
+		{"Key avalanche", g.keyAvalancheTest(counts([]int{200, 210}, make([]int, 4))), true},
+		{"Key bit without effect", g.keyAvalancheTest(counts([]int{200, 0}, make([]int, 4))), false},
+		{"Balanced schedules", g.scheduleBalanceTest(counts(nil, []int{50, 45, 55, 50})), true},
+		{"Constant schedule bit", g.scheduleBalanceTest(counts(nil, []int{50, 100, 50, 50})), false},
+		{"Related keys", g.relatedKeyTest(keyScheduleCounts{comparisons: 1 << 20}), true},
+		// 2^20 comparisons of 32-bit words expect 1/4096 equal words
+		{"Equal words", g.relatedKeyTest(keyScheduleCounts{comparisons: 1 << 20, equalWords: 2}), false},
+		{"Identical schedule", g.relatedKeyTest(keyScheduleCounts{comparisons: 1 << 32, equalWords: 1, collisions: 1}), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.test.Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v (%s)", tt.test.Passed, tt.passed, tt.test.Details)
+			}
+		})
+	}
+}
+
+func TestGenerateRecordsKeyScheduleTests(t *testing.T) {
+	for _, enabled := range []bool{true, false} {
+		config := keyScheduleConfig()
+		config.NumCandidates = 20
+		config.AvalancheTestCases = 100
+		config.KeySchedule.Enabled = enabled
+		config.ResultsFile = ""
+
+		result, err := NewGenerator(config).Generate()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range result.Constants() {
+			if got := len(c.Candidate.TestResults.KeyScheduleTests); (got == 4) != enabled {
+				t.Errorf("enabled = %v: %s has %d key-schedule tests", enabled, c.Name, got)
+			}
+		}
+	}
+}
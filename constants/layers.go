@@ -0,0 +1,395 @@
+package constants
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Configuration layers, lowest precedence first.
+const (
+	LayerDefault = "default"
+	LayerFile    = "file"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+// envPrefix starts every environment variable that overrides a setting.
+const envPrefix = "PRIMER_"
+
+// ConfigLayers are the inputs merged over DefaultConfig by
+// LoadConfigLayers, in increasing order of precedence.
+type ConfigLayers struct {
+	// Path is a JSON, YAML or TOML file, chosen by extension. It may be
+	// empty.
+	Path string
+	// Env holds "KEY=value" entries as returned by os.Environ. Only
+	// PRIMER_* entries are used.
+	Env []string
+	// Overrides holds "Key=Value" settings from -set flags. Nested fields
+	// use dotted paths such as StatisticalTests.MinPValue.
+	Overrides []string
+	// Warn, if set, receives problems that do not stop loading, such as
+	// PRIMER_* variables that name no setting or deprecated settings that
+	// were changed.
+	Warn func(msg string)
+}
+
+// ValueSource records which layer set a configuration value and where.
+type ValueSource struct {
+	Layer    string
+	Location string
+}
+
+func (s ValueSource) String() string {
+	if s.Location == "" {
+		return s.Layer
+	}
+	return s.Layer + " " + s.Location
+}
+
+// ConfigSources maps the dotted path of each configuration value to the
+// layer that set it. Paths missing from the map hold their defaults.
+type ConfigSources map[string]ValueSource
+
+// Setting is one configuration value with its origin.
+type Setting struct {
+	Path   string
+	Value  interface{}
+	Source ValueSource
+}
+
+// LoadConfigLayers builds a configuration from the defaults, the file, the
+// PRIMER_* environment variables and the overrides, in that order, and
+// validates the result.
+func LoadConfigLayers(layers ConfigLayers) (Config, ConfigSources, error) {
+	config := DefaultConfig()
+	sources := ConfigSources{}
+
+	if layers.Path != "" {
+		if err := loadConfigFile(layers.Path, &config, sources); err != nil {
+			return config, sources, err
+		}
+	}
+
+	if err := applyEnv(layers.Env, &config, sources, layers.Warn); err != nil {
+		return config, sources, err
+	}
+
+	for _, override := range layers.Overrides {
+		key, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return config, sources, fmt.Errorf("-set %s: expected Key=Value", override)
+		}
+		path, err := setConfigString(&config, strings.TrimSpace(key), value)
+		if err != nil {
+			return config, sources, fmt.Errorf("-set %s: %w", override, err)
+		}
+		sources[path] = ValueSource{Layer: LayerFlag, Location: "-set " + override}
+	}
+
+	if layers.Warn != nil {
+		for _, path := range deprecatedSettings(&config) {
+			layers.Warn(fmt.Sprintf("%s (set by %s) is deprecated and ignored", path, sources[path]))
+		}
+	}
+
+	return config, sources, ValidateConfig(&config)
+}
+
+// loadConfigFile decodes the file at path into config according to its
+// extension, recording the line of each value it sets.
+func loadConfigFile(path string, config *Config, sources ConfigSources) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+
+	var lines map[string]int
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		lines, err = decodeAssignments(parseYAML, data, path, config)
+	case ".toml":
+		lines, err = decodeAssignments(parseTOML, data, path, config)
+	default:
+		lines, err = decodeStrict(data, path, config)
+	}
+	if err != nil {
+		return fmt.Errorf("parsing config: %w", err)
+	}
+
+	for fieldPath, line := range lines {
+		sources[fieldPath] = ValueSource{Layer: LayerFile, Location: fmt.Sprintf("%s:%d", path, line)}
+	}
+	return nil
+}
+
+// applyEnv applies PRIMER_* variables. Each setting's variable is its path
+// in upper snake case, for example PRIMER_PARALLEL_WORKERS or
+// PRIMER_STATISTICAL_TESTS_MIN_P_VALUE. Variables that name no setting are
+// ignored and reported to warn, since the environment is shared with
+// everything else the user runs.
+func applyEnv(env []string, config *Config, sources ConfigSources, warn func(string)) error {
+	names := make(map[string]string)
+	var known []string
+	for _, path := range settingPaths() {
+		names[envName(path)] = path
+		known = append(known, envName(path))
+	}
+
+	var errs []error
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		if !strings.HasPrefix(name, envPrefix) {
+			continue
+		}
+		path, ok := names[name]
+		if !ok {
+			if warn != nil {
+				msg := fmt.Sprintf("%s: unknown setting, ignored", name)
+				if best := closestName(name, known); best != "" {
+					msg += fmt.Sprintf(" (did you mean %s?)", best)
+				}
+				warn(msg)
+			}
+			continue
+		}
+		if _, err := setConfigString(config, path, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		sources[path] = ValueSource{Layer: LayerEnv, Location: name}
+	}
+	return errors.Join(errs...)
+}
+
+// envName returns the environment variable for a dotted setting path.
+func envName(path string) string {
+	var parts []string
+	for _, name := range strings.Split(path, ".") {
+		parts = append(parts, upperSnake(name))
+	}
+	return envPrefix + strings.Join(parts, "_")
+}
+
+// upperSnake converts a Go field name to upper snake case, keeping
+// acronyms together: MinPValue becomes MIN_P_VALUE.
+func upperSnake(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// settingPaths lists the dotted path of every leaf field of Config in
+// declaration order.
+func settingPaths() []string {
+	var paths []string
+	var walk func(t reflect.Type, prefix string)
+	walk = func(t reflect.Type, prefix string) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			path := joinPath(prefix, field.Name)
+			if field.Type.Kind() == reflect.Struct {
+				walk(field.Type, path)
+				continue
+			}
+			paths = append(paths, path)
+		}
+	}
+	walk(reflect.TypeOf(Config{}), "")
+	return paths
+}
+
+// EffectiveSettings lists every configuration value with the layer that
+// set it, in declaration order.
+func EffectiveSettings(config Config, sources ConfigSources) []Setting {
+	settings := make([]Setting, 0, len(sources))
+	root := reflect.ValueOf(config)
+	for _, path := range settingPaths() {
+		v := root
+		for _, name := range strings.Split(path, ".") {
+			v = v.FieldByName(name)
+		}
+		source, ok := sources[path]
+		if !ok {
+			source = ValueSource{Layer: LayerDefault}
+		}
+		settings = append(settings, Setting{Path: path, Value: v.Interface(), Source: source})
+	}
+	return settings
+}
+
+// resolveField finds the leaf field of config named by a dotted path,
+// matching names case-insensitively, and returns it with its canonical
+// path.
+func resolveField(config *Config, path []string) (reflect.Value, string, error) {
+	v := reflect.ValueOf(config).Elem()
+	canonical := ""
+	for i, name := range path {
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, "", fmt.Errorf("%s is not a section", canonical)
+		}
+		field, ok := lookupField(v.Type(), name)
+		if !ok {
+			return reflect.Value{}, "", errors.New(unknownFieldMessage(v.Type(), name))
+		}
+		canonical = joinPath(canonical, field.Name)
+		v = v.FieldByIndex(field.Index)
+		if i == len(path)-1 && v.Kind() == reflect.Struct {
+			return reflect.Value{}, "", fmt.Errorf("%s is a section; set its fields individually", canonical)
+		}
+	}
+	return v, canonical, nil
+}
+
+// setConfigString sets the field at a dotted path from its text form. Lists
+// are comma separated. It returns the canonical path.
+func setConfigString(config *Config, path, value string) (string, error) {
+	field, canonical, err := resolveField(config, strings.Split(path, "."))
+	if err != nil {
+		return "", err
+	}
+
+	raw := rawValue{items: []rawScalar{{text: value}}}
+	if field.Kind() == reflect.Slice {
+		raw = rawValue{list: true}
+		if strings.TrimSpace(value) != "" {
+			for _, item := range strings.Split(value, ",") {
+				raw.items = append(raw.items, rawScalar{text: strings.TrimSpace(item)})
+			}
+		}
+	}
+	return canonical, setField(field, raw)
+}
+
+// rawScalar is a scalar from a YAML or TOML file, environment variable or
+// flag, before conversion to the type of its field.
+type rawScalar struct {
+	text   string
+	quoted bool
+}
+
+// rawValue is a scalar, or a list of scalars when list is set.
+type rawValue struct {
+	items []rawScalar
+	list  bool
+}
+
+// assignment sets the field at path, found at line and column of a file.
+type assignment struct {
+	path   []string
+	value  rawValue
+	line   int
+	column int
+}
+
+// decodeAssignments applies the assignments parsed from a YAML or TOML file
+// to config, reporting unknown keys and type errors with their positions.
+func decodeAssignments(parse func([]byte, string) ([]assignment, error), data []byte, file string, config *Config) (map[string]int, error) {
+	assignments, err := parse(data, file)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make(map[string]int)
+	var errs []error
+	for _, a := range assignments {
+		if isCommentPath(a.path) {
+			continue
+		}
+		field, canonical, err := resolveField(config, a.path)
+		if err == nil {
+			err = setField(field, a.value)
+		}
+		if err != nil {
+			errs = append(errs, &DecodeError{File: file, Line: a.line, Column: a.column, Msg: err.Error()})
+			continue
+		}
+		lines[canonical] = a.line
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return lines, nil
+}
+
+func isCommentPath(path []string) bool {
+	for _, name := range path {
+		if strings.HasPrefix(name, commentPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// setField converts raw to the type of field and stores it.
+func setField(field reflect.Value, raw rawValue) error {
+	if field.Kind() == reflect.Slice {
+		if !raw.list {
+			return fmt.Errorf("expected a list")
+		}
+		list := reflect.MakeSlice(field.Type(), len(raw.items), len(raw.items))
+		for i, item := range raw.items {
+			if err := setScalar(list.Index(i), item); err != nil {
+				return err
+			}
+		}
+		field.Set(list)
+		return nil
+	}
+
+	if raw.list || len(raw.items) != 1 {
+		return fmt.Errorf("expected a single %s, got a list", field.Kind())
+	}
+	return setScalar(field, raw.items[0])
+}
+
+func setScalar(field reflect.Value, s rawScalar) error {
+	if s.quoted && field.Kind() != reflect.String {
+		return fmt.Errorf("expected %s, got string %q", field.Kind(), s.text)
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(s.text)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s.text)
+		if err != nil {
+			return fmt.Errorf("expected bool, got %q", s.text)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s.text, 0, 64)
+		if err != nil {
+			return fmt.Errorf("expected integer, got %q", s.text)
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(s.text, 64)
+		if err != nil {
+			return fmt.Errorf("expected number, got %q", s.text)
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
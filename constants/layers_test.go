@@ -0,0 +1,173 @@
+package constants
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigLayersPrecedence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+    "NumCandidates": 500,
+    "ParallelWorkers": 2,
+    "Seed": "from-file"
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, sources, err := LoadConfigLayers(ConfigLayers{
+		Path: path,
+		Env: []string{
+			"HOME=/root",
+			"PRIMER_PARALLEL_WORKERS=16",
+			"PRIMER_SEED=from-env",
+			"PRIMER_STATISTICAL_TESTS_MIN_P_VALUE=0.02",
+			"PRIMER_DERIVATION_OFFSETS=0, 32",
+		},
+		Overrides: []string{"Seed=from-flag", "statisticaltests.maxpvalue=0.98"},
+	})
+	if err != nil {
+		t.Fatalf("LoadConfigLayers() error = %v", err)
+	}
+
+	tests := []struct {
+		path      string
+		got, want interface{}
+		layer     string
+		location  string
+	}{
+		{"WordSize", config.WordSize, 32, LayerDefault, ""},
+		{"NumCandidates", config.NumCandidates, 500, LayerFile, path + ":2"},
+		{"ParallelWorkers", config.ParallelWorkers, 16, LayerEnv, "PRIMER_PARALLEL_WORKERS"},
+		{"Seed", config.Seed, "from-flag", LayerFlag, "-set Seed=from-flag"},
+		{"StatisticalTests.MinPValue", config.StatisticalTests.MinPValue, 0.02, LayerEnv, "PRIMER_STATISTICAL_TESTS_MIN_P_VALUE"},
+		{"StatisticalTests.MaxPValue", config.StatisticalTests.MaxPValue, 0.98, LayerFlag, "-set statisticaltests.maxpvalue=0.98"},
+		{"Derivation.Offsets", len(config.Derivation.Offsets), 2, LayerEnv, "PRIMER_DERIVATION_OFFSETS"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.path, tt.got, tt.want)
+		}
+		source, ok := sources[tt.path]
+		if !ok {
+			source = ValueSource{Layer: LayerDefault}
+		}
+		if source.Layer != tt.layer || source.Location != tt.location {
+			t.Errorf("%s source = %v, want %s %s", tt.path, source, tt.layer, tt.location)
+		}
+	}
+}
+
+func TestLoadConfigLayersErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		layers  ConfigLayers
+		wantErr string
+	}{
+		{"Bad environment value", ConfigLayers{Env: []string{"PRIMER_NUM_CANDIDATES=many"}}, `PRIMER_NUM_CANDIDATES: expected integer, got "many"`},
+		{"Override without value", ConfigLayers{Overrides: []string{"NumCandidates"}}, "expected Key=Value"},
+		{"Unknown override", ConfigLayers{Overrides: []string{"Derivation.Rule=odd"}}, `unknown field "Rule" (did you mean "Rules"?)`},
+		{"Override of a section", ConfigLayers{Overrides: []string{"Derivation=odd"}}, "Derivation is a section"},
+		{"Invalid merged value", ConfigLayers{Overrides: []string{"WordSize=24"}}, "WordSize must be 16, 32, 64 or 128"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := LoadConfigLayers(tt.layers)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUnknownEnvIgnored(t *testing.T) {
+	var warnings []string
+	config, _, err := LoadConfigLayers(ConfigLayers{
+		Env:  []string{"PRIMER_WORDSIZE=64", "PRIMER_NUM_CANDIDATES=5"},
+		Warn: func(msg string) { warnings = append(warnings, msg) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.WordSize != 32 || config.NumCandidates != 5 {
+		t.Errorf("WordSize %d, NumCandidates %d; want 32 and 5", config.WordSize, config.NumCandidates)
+	}
+	want := []string{"PRIMER_WORDSIZE: unknown setting, ignored (did you mean PRIMER_WORD_SIZE?)"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings %q, want %q", warnings, want)
+	}
+
+	// Without Warn the variable is silently ignored
+	if _, _, err := LoadConfigLayers(ConfigLayers{Env: []string{"PRIMER_WORDSIZE=64"}}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDeprecatedSettingWarns(t *testing.T) {
+	var warnings []string
+	_, _, err := LoadConfigLayers(ConfigLayers{
+		Overrides: []string{"StatisticalTests.MaxRunsZScore=2.5", "StatisticalTests.MinRunsZScore=-3"},
+		Warn:      func(msg string) { warnings = append(warnings, msg) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// MinRunsZScore is set to its default, so only MaxRunsZScore warns
+	want := []string{"StatisticalTests.MaxRunsZScore (set by flag -set StatisticalTests.MaxRunsZScore=2.5) is deprecated and ignored"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings %q, want %q", warnings, want)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"NumCandidates":                       "PRIMER_NUM_CANDIDATES",
+		"MinPrimeAttempts":                    "PRIMER_MIN_PRIME_ATTEMPTS",
+		"StatisticalTests.MinPValue":          "PRIMER_STATISTICAL_TESTS_MIN_P_VALUE",
+		"RandomnessTests.RunsTestWeight":      "PRIMER_RANDOMNESS_TESTS_RUNS_TEST_WEIGHT",
+		"ValidationCriteria.MinDifferentBits": "PRIMER_VALIDATION_CRITERIA_MIN_DIFFERENT_BITS",
+	}
+	for path, want := range tests {
+		if got := envName(path); got != want {
+			t.Errorf("envName(%q) = %q, want %q", path, got, want)
+		}
+	}
+
+	// Every setting needs its own variable
+	seen := make(map[string]string)
+	for _, path := range settingPaths() {
+		name := envName(path)
+		if other, ok := seen[name]; ok {
+			t.Errorf("%s and %s share %s", path, other, name)
+		}
+		seen[name] = path
+	}
+}
+
+func TestEffectiveSettings(t *testing.T) {
+	config := DefaultConfig()
+	config.NumCandidates = 7
+	sources := ConfigSources{"NumCandidates": {Layer: LayerFlag, Location: "-set NumCandidates=7"}}
+
+	settings := EffectiveSettings(config, sources)
+	if len(settings) != len(settingPaths()) {
+		t.Fatalf("got %d settings, want %d", len(settings), len(settingPaths()))
+	}
+	for _, s := range settings {
+		switch s.Path {
+		case "NumCandidates":
+			if s.Value != 7 || s.Source.String() != "flag -set NumCandidates=7" {
+				t.Errorf("NumCandidates setting = %+v", s)
+			}
+		case "StatisticalTests.MinPValue":
+			if s.Value != 0.01 || s.Source.String() != "default" {
+				t.Errorf("MinPValue setting = %+v", s)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package constants
 
 import (
     "fmt"
+    "io"
     "log"
     "os"
 )
@@ -18,6 +19,11 @@ func NewLogger(detailed bool) *Logger {
     }
 }
 
+// SetOutput sends the log to w.
+func (l *Logger) SetOutput(w io.Writer) {
+    l.log.SetOutput(w)
+}
+
 func (l *Logger) Info(v ...interface{}) {
     if l.detailed {
         l.log.Printf("INFO: %s", fmt.Sprint(v...))
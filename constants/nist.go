@@ -0,0 +1,148 @@
+package constants
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"primer/nist"
+	"primer/rc6"
+	"primer/special"
+	"primer/word"
+)
+
+// runNISTTests runs the NIST SP 800-22 battery on Sequences keystreams of
+// the configured transform keyed with value. With RC6, even sequences use
+// value as P and odd ones as Q; each has its own random key.
+func (g *Generator) runNISTTests(value word.Word) (*NISTReport, error) {
+	start := time.Now()
+	config := g.config.NIST
+	transform := TransformRC6
+	if g.config.Transform == TransformSimple {
+		transform = TransformSimple
+	}
+
+	size := g.config.KeyBytes
+	if transform == TransformSimple {
+		size = int(g.wordSize() / 8)
+	}
+	keys, err := g.drawKeys(nistStream, config.Sequences, size)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := parallelMap(config.Sequences, g.config.ParallelWorkers, func(i int) ([]nist.Result, error) {
+		stream, err := g.keystream(value, transform, keys[i], i%2 == 1, config.StreamBits)
+		if err != nil {
+			return nil, err
+		}
+		return nist.Run(stream), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &NISTReport{
+		Transform:  transform,
+		StreamBits: config.StreamBits,
+		Sequences:  config.Sequences,
+		Alpha:      config.Alpha,
+		Tests:      summarizeNIST(results, config.Alpha, config.MinUniformityKS),
+		Passed:     true,
+	}
+	for _, test := range report.Tests {
+		if !test.Passed {
+			report.Passed = false
+		}
+	}
+	report.Duration = time.Since(start)
+	return report, nil
+}
+
+// summarizeNIST combines the results of every sequence into one NISTTest
+// per statistic, in the order of the battery. A statistic whose
+// Kolmogorov-Smirnov uniformity is below minUniformityKS fails.
+func summarizeNIST(results [][]nist.Result, alpha, minUniformityKS float64) []NISTTest {
+	if len(results) == 0 {
+		return nil
+	}
+	tests := make([]NISTTest, len(results[0]))
+	for i := range tests {
+		t := &tests[i]
+		t.Name = results[0][i].Name
+		var skipped int
+		var reason error
+		for _, sequence := range results {
+			if r := sequence[i]; r.Err != nil {
+				skipped++
+				reason = r.Err
+			} else {
+				t.PValues = append(t.PValues, r.PValue)
+			}
+		}
+
+		if len(t.PValues) == 0 {
+			// Nothing to judge: the test is reported but does not fail
+			t.Passed = true
+			t.Details = reason.Error()
+			continue
+		}
+		t.Proportion, t.MinProportion = nist.Proportion(t.PValues, alpha)
+		t.Uniformity = nist.Uniformity(t.PValues)
+		_, t.UniformityKS = special.KolmogorovSmirnovUniform(t.PValues)
+		t.Passed = t.Proportion >= t.MinProportion &&
+			(len(t.PValues) < nist.MinUniformitySequences || t.Uniformity >= nist.MinUniformity) &&
+			t.UniformityKS >= minUniformityKS
+		if skipped > 0 {
+			t.Details = fmt.Sprintf("not applicable to %d of %d sequences", skipped, len(results))
+		}
+	}
+	return tests
+}
+
+// keystream returns n bits, one per byte, of the transform keyed with
+// constant and key, run in counter mode. asQ substitutes the constant for Q
+// instead of P in RC6.
+func (g *Generator) keystream(constant word.Word, transform string, key []byte, asQ bool, n int) ([]byte, error) {
+	w := g.wordSize()
+	var blockSize int
+	var encrypt func(dst []byte, counter uint64)
+
+	if transform == TransformSimple {
+		k := word.FromBytes(key)
+		blockSize = int(w / 8)
+		encrypt = func(dst []byte, counter uint64) {
+			x := g.rc6Transform(word.FromUint64(counter).Xor(k).Truncate(w), constant)
+			for i := range dst {
+				dst[i] = byte(x.Rsh(uint(i) * 8).Lo)
+			}
+		}
+	} else {
+		p, q, err := rc6.MagicConstants(w)
+		if err != nil {
+			return nil, err
+		}
+		if asQ {
+			q = constant
+		} else {
+			p = constant
+		}
+		cipher, err := rc6.New(key, w, g.config.CipherRounds, p, q)
+		if err != nil {
+			return nil, err
+		}
+		blockSize = cipher.BlockSize()
+		counterBlock := make([]byte, blockSize)
+		encrypt = func(dst []byte, counter uint64) {
+			binary.LittleEndian.PutUint64(counterBlock, counter)
+			cipher.Encrypt(dst, counterBlock)
+		}
+	}
+
+	blocks := (n + 8*blockSize - 1) / (8 * blockSize)
+	data := make([]byte, blocks*blockSize)
+	for i := 0; i < blocks; i++ {
+		encrypt(data[i*blockSize:(i+1)*blockSize], uint64(i))
+	}
+	return nist.Bits(data)[:n], nil
+}
@@ -0,0 +1,155 @@
+package constants
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"primer/nist"
+	"primer/rc6"
+	"primer/special"
+	"primer/word"
+)
+
+func nistConfig(transform string) Config {
+	config := DefaultConfig()
+	config.Seed = "nist"
+	config.Transform = transform
+	config.NIST = NISTConfig{Enabled: true, Sequences: 4, StreamBits: 1 << 16, Alpha: 0.01}
+	return config
+}
+
+func TestRunNISTTests(t *testing.T) {
+	p, _, err := rc6.MagicConstants(32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		transform   string
+		minFailures int
+		maxFailures int
+	}{
+		// Four sequences pass a statistic only if all four do, which a
+		// good generator misses for about one statistic in twenty-five.
+		{TransformRC6, 0, 20},
+		// The word-level transform in counter mode is far from random
+		{TransformSimple, 60, 188},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transform, func(t *testing.T) {
+			report, err := NewGenerator(nistConfig(tt.transform)).runNISTTests(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Transform != tt.transform || report.Sequences != 4 || report.StreamBits != 1<<16 {
+				t.Errorf("report = %s, %d x %d bits", report.Transform, report.Sequences, report.StreamBits)
+			}
+			if len(report.Tests) != 188 {
+				t.Fatalf("len(Tests) = %d, want 188", len(report.Tests))
+			}
+
+			failed := 0
+			for _, test := range report.Tests {
+				if !test.Passed {
+					failed++
+				}
+			}
+			if failed < tt.minFailures || failed > tt.maxFailures {
+				t.Errorf("%d statistics failed, want %d to %d", failed, tt.minFailures, tt.maxFailures)
+			}
+			if report.Passed != (failed == 0) {
+				t.Errorf("Passed = %v with %d failures", report.Passed, failed)
+			}
+
+			again, err := NewGenerator(nistConfig(tt.transform)).runNISTTests(p)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again.Tests, report.Tests) {
+				t.Error("seeded battery is not reproducible")
+			}
+		})
+	}
+}
+
+func TestKeystreamRoles(t *testing.T) {
+	g := NewGenerator(nistConfig(TransformRC6))
+	p, q, _ := rc6.MagicConstants(32)
+	key := make([]byte, 16)
+
+	asP, err := g.keystream(p, TransformRC6, key, false, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(asP) != 1000 {
+		t.Fatalf("len = %d, want 1000", len(asP))
+	}
+	for _, b := range asP {
+		if b > 1 {
+			t.Fatalf("keystream holds %d, want bits", b)
+		}
+	}
+
+	// The standard P substituted for P is the unmodified cipher, which Q
+	// substituted for Q must reproduce.
+	asQ, _ := g.keystream(q, TransformRC6, key, true, 1000)
+	if !reflect.DeepEqual(asP, asQ) {
+		t.Error("standard constants in either role give different keystreams")
+	}
+	other, _ := g.keystream(word.FromUint64(0xB7E15161), TransformRC6, key, false, 1000)
+	if reflect.DeepEqual(asP, other) {
+		t.Error("keystream does not depend on the constant")
+	}
+}
+
+func TestSummarizeNIST(t *testing.T) {
+	notApplicable := errors.New("too few cycles")
+	results := [][]nist.Result{
+		{{Name: "A", PValue: 0.5}, {Name: "B", PValue: 0.001}, {Name: "C", Err: notApplicable}},
+		{{Name: "A", PValue: 0.2}, {Name: "B", PValue: 0.4}, {Name: "C", Err: notApplicable}},
+		{{Name: "A", Err: notApplicable}, {Name: "B", PValue: 0.6}, {Name: "C", Err: notApplicable}},
+	}
+	tests := summarizeNIST(results, 0.01, 0)
+
+	want := []struct {
+		name       string
+		pValues    int
+		proportion float64
+		passed     bool
+		details    string
+	}{
+		{"A", 2, 1, true, "not applicable to 1 of 3 sequences"},
+		{"B", 3, 2.0 / 3, false, ""},
+		{"C", 0, 0, true, "too few cycles"},
+	}
+	for i, w := range want {
+		got := tests[i]
+		if got.Name != w.name || len(got.PValues) != w.pValues || got.Proportion != w.proportion ||
+			got.Passed != w.passed || got.Details != w.details {
+			t.Errorf("test %d = %+v, want %+v", i, got, w)
+		}
+		if len(got.PValues) > 0 {
+			if _, ks := special.KolmogorovSmirnovUniform(got.PValues); got.UniformityKS != ks {
+				t.Errorf("test %d: UniformityKS = %v, want %v", i, got.UniformityKS, ks)
+			}
+		}
+	}
+}
+
+func TestSummarizeNISTUniformityKS(t *testing.T) {
+	// Every sequence passes, but the p-values crowd just above alpha
+	var results [][]nist.Result
+	for i := 0; i < 20; i++ {
+		results = append(results, []nist.Result{{Name: "A", PValue: 0.02 + float64(i)*0.001}})
+	}
+
+	if got := summarizeNIST(results, 0.01, 0)[0]; !got.Passed {
+		t.Errorf("without a Kolmogorov-Smirnov threshold Passed = false, want true: %+v", got)
+	}
+	got := summarizeNIST(results, 0.01, nist.MinUniformity)[0]
+	if got.UniformityKS >= nist.MinUniformity || got.Passed {
+		t.Errorf("UniformityKS = %v, Passed = %v, want below %v and false", got.UniformityKS, got.Passed, nist.MinUniformity)
+	}
+}
@@ -0,0 +1,129 @@
+package constants
+
+import (
+	"fmt"
+	"io"
+)
+
+// Scorer rates an accepted candidate for selection; higher is better.
+type Scorer interface {
+	Score(candidate ConstantCandidate) float64
+}
+
+// Validator decides whether a generated candidate is accepted. It returns
+// the reason for rejecting candidate, or "" to accept it. Reasons are
+// counted in the rejection funnel.
+type Validator interface {
+	Validate(candidate ConstantCandidate) string
+}
+
+// Selector picks n constants from the accepted candidates, which are in
+// index order. The chosen constants must be pairwise sufficiently
+// different; Generate checks this before accepting the selection.
+type Selector interface {
+	Select(candidates []ConstantCandidate, n int) (Selection, error)
+}
+
+// ScorerFunc adapts a function to the Scorer interface.
+type ScorerFunc func(candidate ConstantCandidate) float64
+
+func (f ScorerFunc) Score(candidate ConstantCandidate) float64 { return f(candidate) }
+
+// ValidatorFunc adapts a function to the Validator interface.
+type ValidatorFunc func(candidate ConstantCandidate) string
+
+func (f ValidatorFunc) Validate(candidate ConstantCandidate) string { return f(candidate) }
+
+// Option customizes a Generator created by NewGenerator.
+type Option func(*Generator)
+
+// WithScorer replaces the weighted-sum score used to rank candidates.
+func WithScorer(scorer Scorer) Option {
+	return func(g *Generator) { g.scorer = scorer }
+}
+
+// WithValidator replaces the rules that accept or reject each candidate.
+func WithValidator(validator Validator) Option {
+	return func(g *Generator) { g.validator = validator }
+}
+
+// WithSelector replaces how the constants are chosen from the accepted
+// candidates.
+func WithSelector(selector Selector) Option {
+	return func(g *Generator) { g.selector = selector }
+}
+
+// WithRandomSource replaces the source of randomness, like SetRandomSource.
+func WithRandomSource(random RandomSource) Option {
+	return func(g *Generator) { g.random = random }
+}
+
+// WithCandidateStream streams every candidate to w, like SetCandidateStream.
+func WithCandidateStream(w io.Writer) Option {
+	return func(g *Generator) { g.candidateOutput = w }
+}
+
+// WithLogOutput sends the log to w, like SetLogOutput.
+func WithLogOutput(w io.Writer) Option {
+	return func(g *Generator) { g.SetLogOutput(w) }
+}
+
+// DefaultScorer returns the scorer NewGenerator uses for config: the
+// weighted sum of bit distribution, avalanche and entropy scores.
+func DefaultScorer(config Config) Scorer {
+	return defaultScorer{NewGenerator(config)}
+}
+
+// DefaultValidator returns the validator NewGenerator uses for config. Custom
+// validators can call it to add rules on top of the default ones.
+func DefaultValidator(config Config) Validator {
+	return defaultValidator{NewGenerator(config)}
+}
+
+// DefaultSelector returns the selector NewGenerator uses for config, ranking
+// with scorer, or with DefaultScorer if scorer is nil.
+func DefaultSelector(config Config, scorer Scorer) Selector {
+	g := NewGenerator(config)
+	if scorer != nil {
+		g.scorer = scorer
+	}
+	return defaultSelector{g}
+}
+
+type defaultScorer struct{ g *Generator }
+
+func (s defaultScorer) Score(candidate ConstantCandidate) float64 {
+	return s.g.calculateScore(candidate)
+}
+
+type defaultValidator struct{ g *Generator }
+
+func (v defaultValidator) Validate(candidate ConstantCandidate) string {
+	return v.g.rejectionReason(candidate)
+}
+
+// defaultSelector ranks candidates by the configured SelectionStrategy and
+// picks the best pairwise different set, as selectBestConstants describes.
+type defaultSelector struct{ g *Generator }
+
+func (s defaultSelector) Select(candidates []ConstantCandidate, n int) (Selection, error) {
+	ranked := s.g.rankCandidates(candidates)
+	selected, alternates, err := s.g.selectBestConstants(ranked, n)
+	if err != nil {
+		return Selection{}, err
+	}
+
+	selection := Selection{Constants: selected, Alternates: alternates}
+	if s.g.config.SelectionStrategy == StrategyPareto {
+		selection.ParetoFront = paretoFront(ranked)
+	}
+	return selection, nil
+}
+
+// checkSelection verifies that a selector returned one constant per name.
+func checkSelection(selection Selection, n int) error {
+	if len(selection.Constants) != n {
+		return fmt.Errorf("selector returned %d constants, want %d", len(selection.Constants), n)
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package constants
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithScorer(t *testing.T) {
+	config := testGenerationConfig("primer-options")
+
+	// Prefer the earliest candidates regardless of their scores
+	byIndex := ScorerFunc(func(c ConstantCandidate) float64 { return -float64(c.Index) })
+	result, err := NewGenerator(config, WithScorer(byIndex)).Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	g := NewGenerator(config)
+	first := -1
+	for i := 0; i < config.NumCandidates && first < 0; i++ {
+		c, err := g.generateCandidate(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if g.validateCandidate(c) {
+			first = i
+		}
+	}
+	if result.SelectedP.Index != first {
+		t.Errorf("SelectedP is candidate %d, want the first accepted candidate %d", result.SelectedP.Index, first)
+	}
+	for _, alt := range result.Alternates {
+		if alt.Score != -float64(alt.Candidate.Index) {
+			t.Errorf("alternate %d has score %v from the default scorer", alt.Candidate.Index, alt.Score)
+		}
+	}
+}
+
+func TestWithValidator(t *testing.T) {
+	config := testGenerationConfig("primer-options")
+	defaults := DefaultValidator(config)
+	evenWeight := ValidatorFunc(func(c ConstantCandidate) string {
+		if reason := defaults.Validate(c); reason != "" {
+			return reason
+		}
+		if c.HammingWeight%2 != 0 {
+			return "odd hamming weight"
+		}
+		return ""
+	})
+
+	g := NewGenerator(config, WithValidator(evenWeight))
+	result, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	for _, c := range result.Constants() {
+		if c.Candidate.HammingWeight%2 != 0 {
+			t.Errorf("%s has odd Hamming weight %d", c.Name, c.Candidate.HammingWeight)
+		}
+	}
+
+	found := false
+	for _, stage := range result.Funnel.Stages {
+		if stage.Reason == "odd hamming weight" {
+			found = stage.Rejected > 0
+		}
+	}
+	if !found {
+		t.Errorf("funnel %v has no custom rejections", result.Funnel)
+	}
+	if reason := g.RejectionReason(ConstantCandidate{HammingWeight: 17}); reason == "" {
+		t.Error("RejectionReason() ignores the custom validator")
+	}
+}
+
+// firstCandidates selects the first count candidates, whatever n asks for.
+type firstCandidates struct{ count int }
+
+func (s firstCandidates) Select(candidates []ConstantCandidate, n int) (Selection, error) {
+	return Selection{Constants: candidates[:s.count]}, nil
+}
+
+func TestWithSelector(t *testing.T) {
+	config := testGenerationConfig("primer-options")
+
+	result, err := NewGenerator(config, WithSelector(DefaultSelector(config, nil))).Generate()
+	if err != nil {
+		t.Fatalf("Generate() with DefaultSelector error = %v", err)
+	}
+	want, err := NewGenerator(config).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SelectedP.Value != want.SelectedP.Value || result.SelectedQ.Value != want.SelectedQ.Value {
+		t.Error("DefaultSelector selects differently from the built-in selection")
+	}
+
+	if _, err := NewGenerator(config, WithSelector(firstCandidates{count: 1})).Generate(); err == nil ||
+		!strings.Contains(err.Error(), "selector returned 1 constants") {
+		t.Errorf("Generate() with a short selection error = %v", err)
+	}
+}
+
+func TestDefaultScorer(t *testing.T) {
+	config := DefaultConfig()
+	config.AvalancheTestCases = 50
+	g := NewGenerator(config)
+	c := g.AnalyzeConstant(RC6_P)
+	if got, want := DefaultScorer(config).Score(c), g.calculateScore(c); got != want {
+		t.Errorf("DefaultScorer().Score() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,210 @@
+package constants
+
+import (
+	"math"
+	"math/bits"
+
+	"primer/word"
+)
+
+// pairAnalysisSeed seeds the inputs of the pairwise tests. They are the
+// same for every pair and every run, so that a saved analysis can be
+// re-checked.
+const pairAnalysisSeed = "primer pair analysis"
+
+// Relations between two constants, as recorded in PairAnalysis.Relations.
+const (
+	RelationCorrelated     = "correlated"
+	RelationOutputs        = "correlated outputs"
+	RelationJointAvalanche = "joint avalanche"
+	RelationComplement     = "complement"
+	RelationRotation       = "rotation"
+	RelationXOR            = "xor"
+	RelationAdditive       = "additive"
+	RelationMultiplicative = "multiplicative"
+)
+
+// analyzePair measures how two constants relate. Structural relations are
+// approximate, up to minDifferentBits; the output and avalanche tests use
+// the simple transform, whose multiplication is where two related
+// constants would interact.
+func (g *Generator) analyzePair(a, b word.Word) PairAnalysis {
+	w := g.wordSize()
+	config := g.config.PairAnalysis
+	analysis := PairAnalysis{
+		Correlation:    g.testConstantCorrelation(a, b),
+		JointAvalanche: g.testCombinedAvalancheEffect(a, b),
+		Difference:     a.Sub(b, w),
+	}
+	if b.Lo&1 == 1 {
+		analysis.Ratio = a.Mul(inverseMod2w(b, w), w)
+	}
+
+	inputs := make([]byte, g.config.AvalancheTestCases*int(w/8))
+	g.readPairInputs(inputs)
+	analysis.OutputDifference = float64(g.compareOutputs(inputs, a, b)) / float64(8*len(inputs))
+
+	relate := func(related bool, relation string) {
+		if related {
+			analysis.Relations = append(analysis.Relations, relation)
+		}
+	}
+	relate(config.MaxCorrelation > 0 && math.Abs(analysis.Correlation) > config.MaxCorrelation, RelationCorrelated)
+	relate(config.MaxOutputBias > 0 && math.Abs(analysis.OutputDifference-0.5) > config.MaxOutputBias, RelationOutputs)
+	relate(analysis.JointAvalanche < g.config.MinAvalancheScore, RelationJointAvalanche)
+
+	minDiff := g.minDifferentBits()
+	relate(a.Xor(b).OnesCount() > int(w)-minDiff, RelationComplement)
+	rotated := false
+	for k := uint(1); k < w; k++ {
+		d := a.Xor(b.RotateLeft(k, w)).OnesCount()
+		rotated = rotated || d < minDiff || d > int(w)-minDiff
+	}
+	relate(rotated, RelationRotation)
+	relate(period(a.Xor(b), w) <= w/4, RelationXOR)
+	relate(nearZero(analysis.Difference, w, minDiff), RelationAdditive)
+	multiple := b.Lo&1 == 1 && nearZero(analysis.Ratio, w, minDiff)
+	if a.Lo&1 == 1 {
+		multiple = multiple || nearZero(b.Mul(inverseMod2w(a, w), w), w, minDiff)
+	}
+	relate(multiple, RelationMultiplicative)
+	return analysis
+}
+
+// pairAnalysis returns analyzePair(a, b), computed once per ordered pair.
+// The selection search asks about the same pairs again as it backtracks,
+// and runFinalValidation asks again about the pairs it selected.
+func (g *Generator) pairAnalysis(a, b word.Word) PairAnalysis {
+	key := [2]word.Word{a, b}
+	g.pairsMu.Lock()
+	analysis, ok := g.pairs[key]
+	g.pairsMu.Unlock()
+	if ok {
+		return analysis
+	}
+
+	analysis = g.analyzePair(a, b)
+	g.pairsMu.Lock()
+	if g.pairs == nil {
+		g.pairs = make(map[[2]word.Word]PairAnalysis)
+	}
+	g.pairs[key] = analysis
+	g.pairsMu.Unlock()
+	return analysis
+}
+
+// readPairInputs fills buf from the fixed pair-analysis stream.
+func (g *Generator) readPairInputs(buf []byte) {
+	w := g.wordSize()
+	words := seededWords(pairAnalysisSeed, uint64(w), w, len(buf)/int(w/8))
+	for i, x := range words {
+		for j := 0; j < int(w/8); j++ {
+			buf[i*int(w/8)+j] = byte(x.Rsh(uint(j) * 8).Lo)
+		}
+	}
+}
+
+// nearZero reports whether x or -x mod 2^w is small, at most w/4 bits
+// long, or has fewer than minDiff bits set.
+func nearZero(x word.Word, w uint, minDiff int) bool {
+	negated := word.Word{}.Sub(x, w)
+	return min(x.BitLen(), negated.BitLen()) <= int(w/4) ||
+		min(x.OnesCount(), negated.OnesCount()) < minDiff
+}
+
+// period returns the smallest rotation that leaves x unchanged.
+func period(x word.Word, w uint) uint {
+	for k := uint(1); k < w; k++ {
+		if w%k == 0 && x.RotateLeft(k, w) == x {
+			return k
+		}
+	}
+	return w
+}
+
+// inverseMod2w returns the inverse of the odd x modulo 2^w. Each Newton
+// step doubles the number of correct low bits, starting from the three
+// bits x is its own inverse to.
+func inverseMod2w(x word.Word, w uint) word.Word {
+	two := word.FromUint64(2)
+	y := x
+	for correct := uint(3); correct < w; correct *= 2 {
+		y = y.Mul(two.Sub(x.Mul(y, w), w), w)
+	}
+	return y
+}
+
+// testConstantCorrelation returns the Pearson correlation of the bits of p
+// and q, or 1 if either has all bits equal.
+func (g *Generator) testConstantCorrelation(p, q word.Word) float64 {
+	w := int(g.wordSize())
+	var sum, pSum, qSum float64
+	n := float64(w)
+
+	for i := 0; i < w; i++ {
+		pVal := float64(p.Bit(uint(i)))
+		qVal := float64(q.Bit(uint(i)))
+		sum += pVal * qVal
+		pSum += pVal
+		qSum += qVal
+	}
+
+	// Bits are 0 or 1, so the sums of squares are the sums
+	numerator := sum - (pSum * qSum / n)
+	denominator := (pSum - (pSum * pSum / n)) * (qSum - (qSum * qSum / n))
+
+	if denominator == 0 {
+		return 1.0
+	}
+	return numerator / math.Sqrt(denominator)
+}
+
+// testCombinedAvalancheEffect returns the avalanche of the simple transform
+// keyed with p followed by the one keyed with q. Each test case flips one
+// input bit, cycling through every bit position.
+func (g *Generator) testCombinedAvalancheEffect(p, q word.Word) float64 {
+	var totalChanges int
+	testCases := g.config.AvalancheTestCases
+	w := g.wordSize()
+	inputs := seededWords(pairAnalysisSeed, uint64(w), w, testCases)
+
+	for i, input := range inputs {
+		modified := input.Xor(word.FromUint64(1).Lsh(uint(i)%w, w))
+
+		result1 := g.rc6Transform(g.rc6Transform(input, p), q)
+		result2 := g.rc6Transform(g.rc6Transform(modified, p), q)
+
+		totalChanges += result1.Xor(result2).OnesCount()
+	}
+
+	return float64(totalChanges) / float64(testCases*int(w))
+}
+
+// compareOutputs returns the number of bits in which the encryptions of
+// input under p and under q differ.
+func (g *Generator) compareOutputs(input []byte, p, q word.Word) int {
+	result1 := g.encryptionTest(input, p)
+	result2 := g.encryptionTest(input, q)
+
+	differences := 0
+	for i := 0; i < len(result1); i++ {
+		diff := result1[i] ^ result2[i]
+		differences += bits.OnesCount8(uint8(diff))
+	}
+	return differences
+}
+
+// encryptionTest applies the simple transform keyed with constant to each
+// little-endian word of input.
+func (g *Generator) encryptionTest(input []byte, constant word.Word) []byte {
+	w := g.wordSize()
+	wordBytes := int(w / 8)
+	output := make([]byte, len(input))
+	for i := 0; i+wordBytes <= len(input); i += wordBytes {
+		x := g.rc6Transform(loadWord(input[i:i+wordBytes], w), constant)
+		for j := 0; j < wordBytes; j++ {
+			output[i+j] = byte(x.Rsh(uint(j) * 8).Lo)
+		}
+	}
+	return output
+}
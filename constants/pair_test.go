@@ -0,0 +1,128 @@
+package constants
+
+import (
+	"slices"
+	"testing"
+
+	"primer/word"
+)
+
+func TestAnalyzePair(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	p := RC6_P
+	tests := []struct {
+		name     string
+		b        word.Word
+		relation string
+	}{
+		{"RC6 Q", RC6_Q, ""},
+		{"Golden ratio", word.FromUint64(0x9E3779B9), ""},
+		{"Complement", p.Not(32), RelationComplement},
+		{"Rotation", p.RotateLeft(7, 32), RelationRotation},
+		{"Periodic XOR", p.Xor(word.FromUint64(0x55555555)), RelationXOR},
+		{"Small difference", p.Add(word.FromUint64(123), 32), RelationAdditive},
+		{"Multiple", p.Mul(word.FromUint64(3), 32), RelationMultiplicative},
+		{"Top bit", p.Xor(word.FromUint64(0x80000000)), RelationOutputs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis := g.analyzePair(p, tt.b)
+			if tt.relation == "" {
+				if len(analysis.Relations) != 0 {
+					t.Errorf("Relations = %v, want none (%+v)", analysis.Relations, analysis)
+				}
+				return
+			}
+			if !slices.Contains(analysis.Relations, tt.relation) {
+				t.Errorf("Relations = %v, want %q", analysis.Relations, tt.relation)
+			}
+			if g.areSufficientlyDifferent(ConstantCandidate{Value: p}, ConstantCandidate{Value: tt.b}) {
+				t.Error("related pair accepted as sufficiently different")
+			}
+		})
+	}
+
+	analysis := g.analyzePair(RC6_P, RC6_Q)
+	if analysis.Difference != RC6_P.Sub(RC6_Q, 32) || analysis.Ratio.Mul(RC6_Q, 32) != RC6_P {
+		t.Errorf("Difference 0x%X, Ratio 0x%X", analysis.Difference, analysis.Ratio)
+	}
+	if again := g.analyzePair(RC6_P, RC6_Q); !slices.Equal(again.Relations, analysis.Relations) ||
+		again.OutputDifference != analysis.OutputDifference || again.JointAvalanche != analysis.JointAvalanche {
+		t.Error("pair analysis is not reproducible")
+	}
+
+	// The memoised analysis is computed once per ordered pair
+	for i := 0; i < 2; i++ {
+		if cached := g.pairAnalysis(RC6_P, RC6_Q); !slices.Equal(cached.Relations, analysis.Relations) ||
+			cached.OutputDifference != analysis.OutputDifference {
+			t.Error("memoised pair analysis differs")
+		}
+	}
+	if _, ok := g.pairs[[2]word.Word{RC6_P, RC6_Q}]; !ok {
+		t.Error("pair analysis not memoised")
+	}
+}
+
+func TestInverseMod2w(t *testing.T) {
+	for _, w := range []uint{16, 32, 64, 128} {
+		for _, x := range []word.Word{word.FromUint64(1), word.FromUint64(3), RC6_P, RC6_Q, word.Mask(w)} {
+			x = x.Truncate(w)
+			if got := x.Mul(inverseMod2w(x, w), w); got != word.FromUint64(1) {
+				t.Errorf("w=%d: 0x%X * inverse = 0x%X", w, x, got)
+			}
+		}
+	}
+}
+
+func TestSelectionRejectsRelatedPairs(t *testing.T) {
+	config := DefaultConfig()
+	g := NewGenerator(config)
+	// Five times P passes the Hamming distance and shift rules
+	multiple := RC6_P.Mul(word.FromUint64(5), 32)
+	ranked := []RankedCandidate{
+		{Rank: 1, Candidate: ConstantCandidate{Value: RC6_P}},
+		{Rank: 2, Candidate: ConstantCandidate{Value: multiple}},
+		{Rank: 3, Candidate: ConstantCandidate{Value: RC6_Q}},
+	}
+
+	selected, _, err := g.selectBestConstants(ranked, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selected[1].Value != RC6_Q {
+		t.Errorf("selected 0x%X, want Q over the multiple of P", selected[1].Value)
+	}
+
+	config.PairAnalysis.Enabled = false
+	selected, _, err = NewGenerator(config).selectBestConstants(ranked, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selected[1].Value != multiple {
+		t.Errorf("selected 0x%X without the pair analysis, want the multiple of P", selected[1].Value)
+	}
+}
+
+func TestGenerateRecordsPairAnalysis(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = "pair analysis"
+	config.NumCandidates = 20
+	config.AvalancheTestCases = 100
+	config.ResultsFile = ""
+	config.ConstantNames = []string{"A", "B", "C"}
+
+	result, err := NewGenerator(config).Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pairs [][2]string
+	for _, p := range result.PairAnalysis {
+		pairs = append(pairs, [2]string{p.First, p.Second})
+		if len(p.Relations) != 0 {
+			t.Errorf("%s,%s selected with relations %v", p.First, p.Second, p.Relations)
+		}
+	}
+	if want := [][2]string{{"A", "B"}, {"A", "C"}, {"B", "C"}}; !slices.Equal(pairs, want) {
+		t.Errorf("pairs %v, want %v", pairs, want)
+	}
+}
@@ -0,0 +1,275 @@
+package constants
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Selection strategies.
+const (
+	// StrategyScore ranks candidates by the score of the generator's Scorer.
+	StrategyScore = "score"
+	// StrategyPareto ranks candidates by Pareto front, then by the
+	// configured tie-breakers within each front.
+	StrategyPareto = "pareto"
+)
+
+// Metric names for Pareto selection. Each statistical test is also a
+// metric, under its own name. All metrics are better when higher, so the
+// cryptanalysis metrics are one minus the maximum differential probability
+// and one minus twice the maximum linear bias.
+const (
+	MetricBitDistribution = "BitDistribution"
+	MetricAvalanche       = "Avalanche"
+	MetricEntropy         = "Entropy"
+	MetricDifferential    = "Differential"
+	MetricLinear          = "Linear"
+)
+
+// Tie-breakers that are not metrics: the weighted score and the candidate
+// index, lowest first.
+const (
+	TieBreakScore = "Score"
+	TieBreakIndex = "Index"
+)
+
+// defaultTieBreakers pick the best weighted score within a front.
+var defaultTieBreakers = []string{TieBreakScore}
+
+// tieBreakerNames returns every accepted tie-breaker name.
+func tieBreakerNames() []string {
+	names := []string{MetricBitDistribution, MetricAvalanche, MetricEntropy, MetricDifferential, MetricLinear}
+	names = append(names, registeredTestNames()...)
+	return append(names, TieBreakScore, TieBreakIndex)
+}
+
+// canonicalTieBreaker returns the accepted spelling of name, which is
+// matched case-insensitively.
+func canonicalTieBreaker(name string) (string, bool) {
+	for _, known := range tieBreakerNames() {
+		if strings.EqualFold(known, name) {
+			return known, true
+		}
+	}
+	return "", false
+}
+
+func validateSelectionConfig(config *Config) error {
+	switch config.SelectionStrategy {
+	case "", StrategyScore, StrategyPareto:
+	default:
+		return fmt.Errorf("unknown selection strategy: %q", config.SelectionStrategy)
+	}
+	for _, name := range config.ParetoTieBreakers {
+		if _, ok := canonicalTieBreaker(name); !ok {
+			return fmt.Errorf("unknown Pareto tie-breaker %q (want one of %s)",
+				name, strings.Join(tieBreakerNames(), ", "))
+		}
+	}
+	if config.ParetoTolerance < 0 || config.ParetoTolerance > 1 {
+		return fmt.Errorf("ParetoTolerance must be between 0 and 1")
+	}
+	return nil
+}
+
+// tieBreakers returns the configured tie-breakers in their canonical
+// spelling.
+func (g *Generator) tieBreakers() []string {
+	if len(g.config.ParetoTieBreakers) == 0 {
+		return defaultTieBreakers
+	}
+	names := make([]string, 0, len(g.config.ParetoTieBreakers))
+	for _, name := range g.config.ParetoTieBreakers {
+		if canonical, ok := canonicalTieBreaker(name); ok {
+			names = append(names, canonical)
+		}
+	}
+	return names
+}
+
+// candidateMetrics returns the metrics a candidate is compared on. The
+// cryptanalysis metrics are only present when the analysis was run.
+func candidateMetrics(c ConstantCandidate) map[string]float64 {
+	metrics := map[string]float64{
+		MetricBitDistribution: 1.0 - math.Abs(0.5-c.BitDistribution),
+		MetricAvalanche:       c.AvalancheScore,
+		MetricEntropy:         c.EntropyScore,
+	}
+	if c.MaxDifferentialProbability > 0 {
+		metrics[MetricDifferential] = 1.0 - c.MaxDifferentialProbability
+		metrics[MetricLinear] = 1.0 - 2*c.MaxLinearBias
+	}
+	for _, test := range c.TestResults.StatisticalTests {
+		metrics[test.Name] = test.Score
+	}
+	return metrics
+}
+
+// metricNames returns the union of the metric names of all candidates, in
+// a fixed order. A candidate missing a metric scores zero on it.
+func metricNames(metrics []map[string]float64) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range metrics {
+		for name := range m {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dominates reports whether a is at least as good as b on every metric and
+// better on at least one.
+func dominates(a, b map[string]float64, names []string) bool {
+	better := false
+	for _, name := range names {
+		switch {
+		case a[name] < b[name]:
+			return false
+		case a[name] > b[name]:
+			better = true
+		}
+	}
+	return better
+}
+
+// paretoFronts assigns each candidate its front: 1 for candidates no other
+// candidate dominates, 2 for those only dominated by front 1, and so on.
+func paretoFronts(metrics []map[string]float64) []int {
+	names := metricNames(metrics)
+	n := len(metrics)
+	dominatedBy := make([]int, n)
+	dominating := make([][]int, n)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			switch {
+			case dominates(metrics[i], metrics[j], names):
+				dominating[i] = append(dominating[i], j)
+				dominatedBy[j]++
+			case dominates(metrics[j], metrics[i], names):
+				dominating[j] = append(dominating[j], i)
+				dominatedBy[i]++
+			}
+		}
+	}
+
+	fronts := make([]int, n)
+	var current []int
+	for i := 0; i < n; i++ {
+		if dominatedBy[i] == 0 {
+			current = append(current, i)
+		}
+	}
+	for front := 1; len(current) > 0; front++ {
+		var next []int
+		for _, i := range current {
+			fronts[i] = front
+			for _, j := range dominating[i] {
+				if dominatedBy[j]--; dominatedBy[j] == 0 {
+					next = append(next, j)
+				}
+			}
+		}
+		current = next
+	}
+	return fronts
+}
+
+// toleranceBucket rounds x to a multiple of tolerance, so that tie-breaker
+// values are compared in buckets rather than pairwise. A pairwise tolerance
+// is not transitive and would not give sort a consistent order.
+func toleranceBucket(x, tolerance float64) float64 {
+	if tolerance == 0 {
+		return x
+	}
+	return math.Round(x / tolerance)
+}
+
+// rankPareto orders candidates by Pareto front and, within a front, by the
+// tie-breakers in turn. Tie-breaker values that round to the same multiple
+// of ParetoTolerance count as tied; the candidate index settles any
+// remaining tie. Fronts themselves are computed on the exact metrics.
+func (g *Generator) rankPareto(candidates []ConstantCandidate) []RankedCandidate {
+	metrics := make([]map[string]float64, len(candidates))
+	for i, c := range candidates {
+		metrics[i] = candidateMetrics(c)
+	}
+	fronts := paretoFronts(metrics)
+
+	type entry struct {
+		ranked  RankedCandidate
+		metrics map[string]float64
+	}
+	entries := make([]entry, len(candidates))
+	for i, c := range candidates {
+		entries[i] = entry{
+			ranked:  RankedCandidate{Candidate: c, Score: g.scorer.Score(c), Front: fronts[i]},
+			metrics: metrics[i],
+		}
+	}
+
+	tieBreakers := g.tieBreakers()
+	tolerance := g.config.ParetoTolerance
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.ranked.Front != b.ranked.Front {
+			return a.ranked.Front < b.ranked.Front
+		}
+		for _, name := range tieBreakers {
+			var x, y float64
+			switch name {
+			case TieBreakScore:
+				x, y = a.ranked.Score, b.ranked.Score
+			case TieBreakIndex:
+				return a.ranked.Candidate.Index < b.ranked.Candidate.Index
+			default:
+				x, y = a.metrics[name], b.metrics[name]
+			}
+			if x, y = toleranceBucket(x, tolerance), toleranceBucket(y, tolerance); x != y {
+				return x > y
+			}
+		}
+		return a.ranked.Candidate.Index < b.ranked.Candidate.Index
+	})
+
+	ranked := make([]RankedCandidate, len(entries))
+	for i, e := range entries {
+		ranked[i] = e.ranked
+		ranked[i].Rank = i + 1
+	}
+	return ranked
+}
+
+// paretoFront returns the first front of ranked, in rank order.
+func paretoFront(ranked []RankedCandidate) []ParetoPoint {
+	var front []ParetoPoint
+	for _, r := range ranked {
+		if r.Front != 1 {
+			continue
+		}
+		front = append(front, ParetoPoint{
+			Index:   r.Candidate.Index,
+			Value:   r.Candidate.Value,
+			Score:   r.Score,
+			Metrics: candidateMetrics(r.Candidate),
+		})
+	}
+	return front
+}
+
+// markSelected records on each front point the name it was selected as.
+func markSelected(front []ParetoPoint, selected []NamedConstant) {
+	names := make(map[int]string, len(selected))
+	for _, s := range selected {
+		names[s.Candidate.Index] = s.Name
+	}
+	for i := range front {
+		front[i].Selected = names[front[i].Index]
+	}
+}
@@ -0,0 +1,191 @@
+package constants
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDominates(t *testing.T) {
+	names := []string{"a", "b"}
+	tests := []struct {
+		name string
+		x, y map[string]float64
+		want bool
+	}{
+		{"Better on all", map[string]float64{"a": 2, "b": 2}, map[string]float64{"a": 1, "b": 1}, true},
+		{"Better on one, equal on other", map[string]float64{"a": 2, "b": 1}, map[string]float64{"a": 1, "b": 1}, true},
+		{"Equal", map[string]float64{"a": 1, "b": 1}, map[string]float64{"a": 1, "b": 1}, false},
+		{"Trade-off", map[string]float64{"a": 2, "b": 0}, map[string]float64{"a": 1, "b": 1}, false},
+		{"Worse", map[string]float64{"a": 0, "b": 0}, map[string]float64{"a": 1, "b": 1}, false},
+		{"Missing metric is zero", map[string]float64{"a": 1}, map[string]float64{"a": 1, "b": 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dominates(tt.x, tt.y, names); got != tt.want {
+				t.Errorf("dominates() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParetoFronts(t *testing.T) {
+	metrics := []map[string]float64{
+		{"a": 3, "b": 1}, // front 1
+		{"a": 1, "b": 3}, // front 1
+		{"a": 2, "b": 2}, // front 1
+		{"a": 1, "b": 1}, // dominated by the three above
+		{"a": 2, "b": 1}, // dominated by 0 and 2
+		{"a": 0, "b": 0}, // dominated by everything
+	}
+	want := []int{1, 1, 1, 3, 2, 4}
+	if got := paretoFronts(metrics); !reflect.DeepEqual(got, want) {
+		t.Errorf("paretoFronts() = %v, want %v", got, want)
+	}
+}
+
+func TestRankParetoTieBreakers(t *testing.T) {
+	// Three mutually non-dominated candidates
+	candidates := []ConstantCandidate{
+		{Index: 0, BitDistribution: 0.5, AvalancheScore: 0.48, EntropyScore: 0.95},
+		{Index: 1, BitDistribution: 0.45, AvalancheScore: 0.50, EntropyScore: 0.96},
+		{Index: 2, BitDistribution: 0.47, AvalancheScore: 0.4999, EntropyScore: 0.99},
+		// Dominated by candidate 0
+		{Index: 3, BitDistribution: 0.6, AvalancheScore: 0.47, EntropyScore: 0.94},
+	}
+
+	tests := []struct {
+		name        string
+		tieBreakers []string
+		tolerance   float64
+		want        []int
+	}{
+		{"Avalanche", []string{MetricAvalanche}, 0, []int{1, 2, 0, 3}},
+		{"Bit distribution", []string{"bitdistribution"}, 0, []int{0, 2, 1, 3}},
+		{"Avalanche within tolerance, then entropy", []string{MetricAvalanche, MetricEntropy}, 0.001, []int{2, 1, 0, 3}},
+		{"Index", []string{TieBreakIndex}, 0, []int{0, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.SelectionStrategy = StrategyPareto
+			config.ParetoTieBreakers = tt.tieBreakers
+			config.ParetoTolerance = tt.tolerance
+			g := NewGenerator(config)
+
+			ranked := g.rankCandidates(candidates)
+			var got []int
+			for i, r := range ranked {
+				got = append(got, r.Candidate.Index)
+				if r.Rank != i+1 {
+					t.Errorf("rank %d recorded as %d", i+1, r.Rank)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+			if ranked[len(ranked)-1].Front != 2 {
+				t.Errorf("dominated candidate on front %d, want 2", ranked[len(ranked)-1].Front)
+			}
+		})
+	}
+}
+
+func TestCandidateMetricsCryptanalysis(t *testing.T) {
+	c := ConstantCandidate{BitDistribution: 0.5, AvalancheScore: 0.5, EntropyScore: 1}
+	if _, ok := candidateMetrics(c)[MetricDifferential]; ok {
+		t.Error("Differential metric present without cryptanalysis")
+	}
+
+	c.MaxDifferentialProbability, c.MaxLinearBias = 0.25, 0.125
+	metrics := candidateMetrics(c)
+	if metrics[MetricDifferential] != 0.75 || metrics[MetricLinear] != 0.75 {
+		t.Errorf("Differential = %v, Linear = %v, want 0.75 and 0.75",
+			metrics[MetricDifferential], metrics[MetricLinear])
+	}
+}
+
+func TestToleranceBucket(t *testing.T) {
+	// Pairwise, 0.5 and 0.5016 would both tie with 0.5008 but not with each
+	// other; buckets tie 0.5008 with only one of them.
+	tolerance := 0.001
+	a, b, c := toleranceBucket(0.5, tolerance), toleranceBucket(0.5008, tolerance), toleranceBucket(0.5016, tolerance)
+	if a == b && b == c {
+		t.Errorf("buckets %v, %v, %v all tie", a, b, c)
+	}
+	if toleranceBucket(0.4999, tolerance) != a {
+		t.Errorf("0.4999 and 0.5 in different buckets at tolerance %v", tolerance)
+	}
+}
+
+func TestValidateSelectionConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Config)
+		wantErr bool
+	}{
+		{"Defaults", func(c *Config) {}, false},
+		{"Pareto with test tie-breaker", func(c *Config) {
+			c.SelectionStrategy = StrategyPareto
+			c.ParetoTieBreakers = []string{"Runs Test", "score"}
+		}, false},
+		{"Unknown strategy", func(c *Config) { c.SelectionStrategy = "best" }, true},
+		{"Unknown tie-breaker", func(c *Config) { c.ParetoTieBreakers = []string{"Speed"} }, true},
+		{"Negative tolerance", func(c *Config) { c.ParetoTolerance = -0.1 }, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config)
+			if err := ValidateConfig(&config); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateParetoFront(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = "primer-pareto"
+	config.NumCandidates = 30
+	config.AvalancheTestCases = 50
+	config.DetailedLogging = false
+	config.ResultsFile = ""
+	config.SelectionStrategy = StrategyPareto
+
+	result, err := NewGenerator(config).Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if len(result.ParetoFront) == 0 {
+		t.Fatal("no Pareto front in result")
+	}
+
+	names := metricNames([]map[string]float64{result.ParetoFront[0].Metrics})
+	selected := 0
+	for i, a := range result.ParetoFront {
+		if a.Selected != "" {
+			selected++
+		}
+		for j, b := range result.ParetoFront {
+			if i != j && dominates(b.Metrics, a.Metrics, names) {
+				t.Errorf("front member %d is dominated by %d", a.Index, b.Index)
+			}
+		}
+	}
+	if selected == 0 {
+		t.Error("no selected constant marked on the front")
+	}
+
+	// The score strategy does not export a front
+	config.SelectionStrategy = StrategyScore
+	result, err = NewGenerator(config).Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if result.ParetoFront != nil {
+		t.Error("score strategy exported a Pareto front")
+	}
+}
@@ -0,0 +1,347 @@
+package constants
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"math/bits"
+	"strings"
+
+	"primer/word"
+)
+
+// Prime classes, as set in PrimeClass. Every class but PrimeAny adds a
+// condition on top of p being prime.
+const (
+	// PrimeAny accepts every prime.
+	PrimeAny = "any"
+	// PrimeSafe requires (p-1)/2 to be prime.
+	PrimeSafe = "safe"
+	// PrimeSophieGermain requires 2p+1 to be prime.
+	PrimeSophieGermain = "sophie-germain"
+	// PrimeStrong requires large prime factors r of p-1, s of p+1 and t of
+	// r-1, as in Gordon's construction of strong primes.
+	PrimeStrong = "strong"
+	// PrimeChen requires p+2 to be prime or the product of two primes.
+	PrimeChen = "chen"
+	// PrimeTwin requires p-2 or p+2 to be prime.
+	PrimeTwin = "twin"
+)
+
+var primeClasses = []string{PrimeAny, PrimeSafe, PrimeSophieGermain, PrimeStrong, PrimeChen, PrimeTwin}
+
+// sieveLimit bounds the small primes that candidates are sieved with
+// before any Miller-Rabin test.
+const sieveLimit = 1 << 10
+
+// smallPrimes holds the primes below sieveLimit.
+var smallPrimes = primesBelow(sieveLimit)
+
+// primesBelow returns the primes below n by the sieve of Eratosthenes.
+func primesBelow(n int) []uint64 {
+	composite := make([]bool, n)
+	var primes []uint64
+	for i := 2; i < n; i++ {
+		if composite[i] {
+			continue
+		}
+		primes = append(primes, uint64(i))
+		for j := i * i; j < n; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+func validatePrimeClass(config *Config) error {
+	if config.PrimeClass == "" || config.PrimeClass == PrimeAny {
+		return nil
+	}
+	for _, known := range primeClasses {
+		if config.PrimeClass == known {
+			if !config.RequirePrime {
+				return fmt.Errorf("PrimeClass %q requires RequirePrime", config.PrimeClass)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown PrimeClass %q (want one of %s)",
+		config.PrimeClass, strings.Join(primeClasses, ", "))
+}
+
+// primeClass returns the configured prime class.
+func (g *Generator) primeClass() string {
+	if g.config.PrimeClass != "" {
+		return g.config.PrimeClass
+	}
+	return PrimeAny
+}
+
+// strongPrimeSlack returns k such that the cofactors of a strong prime's
+// large factors stay below 2^k: small enough to be found by trial division
+// and to leave the large factors at least w/2 bits.
+func strongPrimeSlack(w uint) uint {
+	return min(8, w/4)
+}
+
+// factorBound returns the bound below which prime factors count as small
+// when looking for the large prime factor of a number.
+func factorBound(w uint) uint64 {
+	return min(sieveLimit, uint64(1)<<strongPrimeSlack(w))
+}
+
+// isPrimeBig tests n, which may be wider than a word, for primality.
+func (g *Generator) isPrimeBig(n *big.Int) bool {
+	if n.Sign() <= 0 {
+		return false
+	}
+	if n.IsUint64() {
+		return g.isPrime64(n.Uint64())
+	}
+	return n.ProbablyPrime(probablePrimeRounds)
+}
+
+// largePrimeFactor divides the prime factors below factorBound out of n
+// and returns what is left if it is a prime of at least w/2 bits.
+func (g *Generator) largePrimeFactor(n *big.Int, w uint) (*big.Int, bool) {
+	m := new(big.Int).Set(n)
+	if m.Sign() <= 0 {
+		return m, false
+	}
+	q, r := new(big.Int), new(big.Int)
+	for _, p := range smallPrimes {
+		if p >= factorBound(w) {
+			break
+		}
+		divisor := new(big.Int).SetUint64(p)
+		for {
+			q.QuoRem(m, divisor, r)
+			if r.Sign() != 0 {
+				break
+			}
+			m.Set(q)
+		}
+	}
+	return m, m.BitLen() >= int(w/2) && g.isPrimeBig(m)
+}
+
+// primeClassTest checks the condition class adds to primality. The
+// primality of value itself is left to the Miller-Rabin test.
+func (g *Generator) primeClassTest(value word.Word, class string) (bool, string) {
+	w := g.wordSize()
+	p := value.Big()
+	one, two := big.NewInt(1), big.NewInt(2)
+	switch class {
+	case PrimeSafe:
+		q := new(big.Int).Rsh(p, 1)
+		return g.isPrimeBig(q), fmt.Sprintf("(p-1)/2 = 0x%X %s", q, primeOrComposite(g.isPrimeBig(q)))
+	case PrimeSophieGermain:
+		q := new(big.Int).Lsh(p, 1)
+		q.Add(q, one)
+		return g.isPrimeBig(q), fmt.Sprintf("2p+1 = 0x%X %s", q, primeOrComposite(g.isPrimeBig(q)))
+	case PrimeTwin:
+		above := new(big.Int).Add(p, two)
+		if g.isPrimeBig(above) {
+			return true, fmt.Sprintf("p+2 = 0x%X is prime", above)
+		}
+		below := new(big.Int).Sub(p, two)
+		if g.isPrimeBig(below) {
+			return true, fmt.Sprintf("p-2 = 0x%X is prime", below)
+		}
+		return false, "p-2 and p+2 are composite"
+	case PrimeChen:
+		return g.chenTest(new(big.Int).Add(p, two))
+	case PrimeStrong:
+		r, ok := g.largePrimeFactor(new(big.Int).Sub(p, one), w)
+		if !ok {
+			return false, fmt.Sprintf("p-1 has no prime factor of %d bits or more with a %d-smooth cofactor", w/2, factorBound(w))
+		}
+		s, ok := g.largePrimeFactor(new(big.Int).Add(p, one), w)
+		if !ok {
+			return false, fmt.Sprintf("p+1 has no prime factor of %d bits or more with a %d-smooth cofactor", w/2, factorBound(w))
+		}
+		t, ok := g.largePrimeFactor(new(big.Int).Sub(r, one), w)
+		if !ok {
+			return false, fmt.Sprintf("r-1 = 0x%X has no prime factor of %d bits or more with a %d-smooth cofactor",
+				new(big.Int).Sub(r, one), w/2, factorBound(w))
+		}
+		return true, fmt.Sprintf("r = 0x%X divides p-1, s = 0x%X divides p+1, t = 0x%X divides r-1", r, s, t)
+	}
+	return true, "no condition"
+}
+
+// chenTest reports whether n = p+2 is prime or a small prime times a
+// prime. Products of two primes that are both above sieveLimit are not
+// recognised, since finding them means factoring n.
+func (g *Generator) chenTest(n *big.Int) (bool, string) {
+	if g.isPrimeBig(n) {
+		return true, fmt.Sprintf("p+2 = 0x%X is prime", n)
+	}
+	q, r := new(big.Int), new(big.Int)
+	for _, f := range smallPrimes {
+		q.QuoRem(n, new(big.Int).SetUint64(f), r)
+		if r.Sign() == 0 {
+			if g.isPrimeBig(q) {
+				return true, fmt.Sprintf("p+2 = %d × 0x%X", f, q)
+			}
+			return false, fmt.Sprintf("p+2 = %d × 0x%X, which is composite", f, q)
+		}
+	}
+	return false, fmt.Sprintf("p+2 = 0x%X is composite with no factor below %d", n, sieveLimit)
+}
+
+func primeOrComposite(prime bool) string {
+	if prime {
+		return "is prime"
+	}
+	return "is composite"
+}
+
+// sieved reports whether the sieve rules out x or, for class, the number
+// the class requires to be prime, given the residues of x modulo
+// smallPrimes. A twin is only ruled out when both x-2 and x+2 have a small
+// factor, since either may be its twin. Values up to 2·sieveLimit are never
+// sieved, since they or (p-1)/2 may be one of the small primes themselves.
+func sieved(x word.Word, residues []uint64, class string) bool {
+	if x.Hi == 0 && x.Lo <= 2*sieveLimit {
+		return false
+	}
+	// (p-1)/2 must be odd
+	if class == PrimeSafe && x.Lo&3 == 1 {
+		return true
+	}
+	var below, above bool
+	for i, p := range smallPrimes[1:] {
+		r := residues[i+1]
+		switch {
+		case r == 0:
+			return true
+		case class == PrimeSafe && r == 1:
+			return true
+		case class == PrimeSophieGermain && r == (p-1)/2:
+			return true
+		case class == PrimeTwin && r == 2:
+			below = true
+		case class == PrimeTwin && r == p-2:
+			above = true
+		}
+	}
+	return below && above
+}
+
+// residuesOf sets residues to x modulo each of smallPrimes.
+func residuesOf(x word.Word, residues []uint64) {
+	for i, p := range smallPrimes {
+		residues[i] = bits.Rem64(x.Hi, x.Lo, p)
+	}
+}
+
+// randomOdd draws a random odd value of at most n bits, with bit n-1 set
+// if top is true.
+func (g *Generator) randomOdd(rng io.Reader, n uint, top bool) (word.Word, error) {
+	buf := make([]byte, g.wordSize()/8)
+	if _, err := io.ReadFull(rng, buf); err != nil {
+		return word.Word{}, fmt.Errorf("random generation failed: %w", err)
+	}
+	value := word.FromBytes(buf).Truncate(n)
+	if top {
+		value = value.Or(word.FromUint64(1).Lsh(n-1, n))
+	}
+	value.Lo |= 1
+	return value, nil
+}
+
+// searchPrime walks upward from random odd values of n bits until it finds
+// a prime of class, testing only the values that pass the sieve. Every
+// Miller-Rabin test counts as an attempt; the number made is returned with
+// the prime.
+func (g *Generator) searchPrime(rng io.Reader, n uint, top bool, class string, attempts int) (word.Word, int, error) {
+	two := word.FromUint64(2)
+	residues := make([]uint64, len(smallPrimes))
+	tested := 0
+	for tested < attempts {
+		x, err := g.randomOdd(rng, n, top)
+		if err != nil {
+			return word.Word{}, tested, err
+		}
+		residuesOf(x, residues)
+		for tested < attempts {
+			if !sieved(x, residues, class) {
+				tested++
+				if g.isPrime(x) {
+					if passed, _ := g.primeClassTest(x, class); passed {
+						return x, tested, nil
+					}
+				}
+			}
+			// Draw a new start rather than leave n bits
+			next := x.Add(two, n)
+			if next.Cmp(x) < 0 {
+				break
+			}
+			x = next
+			for i, p := range smallPrimes {
+				residues[i] = (residues[i] + 2) % p
+			}
+		}
+	}
+	return word.Word{}, tested, fmt.Errorf("%s prime generation failed after %d attempts", class, attempts)
+}
+
+// generateClassPrime finds a random prime of the configured class. Strong
+// primes are built as p = a·r + 1 with r = c·t + 1, where t is a random
+// prime and a and c are even and below 2^k, so that p-1 and r-1 have large
+// prime factors r and t by construction. Only p+1 is left to chance. The
+// cofactors are tried from the largest down and p must have bit w-1 set,
+// so strong primes are as wide as the word.
+func (g *Generator) generateClassPrime(rng io.Reader) (word.Word, error) {
+	class := g.primeClass()
+	w := g.wordSize()
+	attempts := g.config.MaxPrimeAttempts
+	if class != PrimeStrong {
+		value, _, err := g.searchPrime(rng, w, false, class, attempts)
+		return value, err
+	}
+
+	k := strongPrimeSlack(w)
+	residues := make([]uint64, len(smallPrimes))
+	one := word.FromUint64(1)
+	tested := 0
+	for tested < attempts {
+		t, n, err := g.searchPrime(rng, w-2*k, true, PrimeAny, attempts-tested)
+		tested += n
+		if err != nil {
+			break
+		}
+		// a·c·t < 2^w, so p never wraps; smaller cofactors only shorten it
+		top := uint64(1)<<k - 2
+		for c := top; c >= 2 && tested < attempts; c -= 2 {
+			r := t.Mul(word.FromUint64(c), w).Add(one, w)
+			if r.Mul(word.FromUint64(top), w).Add(one, w).BitLen() < int(w) {
+				break
+			}
+			if residuesOf(r, residues); sieved(r, residues, PrimeAny) {
+				continue
+			}
+			if tested++; !g.isPrime(r) {
+				continue
+			}
+			for a := top; a >= 2 && tested < attempts; a -= 2 {
+				p := r.Mul(word.FromUint64(a), w).Add(one, w)
+				if p.BitLen() < int(w) {
+					break
+				}
+				if residuesOf(p, residues); sieved(p, residues, PrimeAny) {
+					continue
+				}
+				if tested++; !g.isPrime(p) {
+					continue
+				}
+				if _, ok := g.largePrimeFactor(new(big.Int).Add(p.Big(), big.NewInt(1)), w); ok {
+					return p, nil
+				}
+			}
+		}
+	}
+	return word.Word{}, fmt.Errorf("%s prime generation failed after %d attempts", class, attempts)
+}
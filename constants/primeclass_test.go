@@ -0,0 +1,124 @@
+package constants
+
+import (
+	"testing"
+
+	"primer/word"
+)
+
+func TestPrimeClassTest(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	tests := []struct {
+		value uint64
+		class string
+		want  bool
+	}{
+		{23, PrimeSafe, true},
+		{2039, PrimeSafe, true},
+		{29, PrimeSafe, false},
+		{11, PrimeSophieGermain, true},
+		{13, PrimeSophieGermain, false},
+		{29, PrimeTwin, true},
+		{31, PrimeTwin, true},
+		{23, PrimeTwin, false},
+		// 49 = 7 × 7
+		{47, PrimeChen, true},
+		// 45 = 3 × 3 × 5
+		{43, PrimeChen, false},
+		// 2^31 - 1 has only small factors below it
+		{0x7FFFFFFF, PrimeStrong, false},
+		{0x7FFFFFFF, PrimeAny, true},
+	}
+	for _, tt := range tests {
+		if got, details := g.primeClassTest(word.FromUint64(tt.value), tt.class); got != tt.want {
+			t.Errorf("%d %s: got %v, want %v (%s)", tt.value, tt.class, got, tt.want, details)
+		}
+	}
+}
+
+func TestGenerateClassPrime(t *testing.T) {
+	for _, w := range []int{16, 32, 64, 128} {
+		for _, class := range primeClasses {
+			config := DefaultConfig()
+			config.WordSize = w
+			config.Seed = "prime classes"
+			config.PrimeClass = class
+			g := NewGenerator(config)
+
+			value, err := g.generatePrime(g.stream(0))
+			if err != nil {
+				t.Fatalf("w=%d %s: %v", w, class, err)
+			}
+			if value.BitLen() > w || !g.isPrime(value) {
+				t.Errorf("w=%d %s: 0x%X is not a %d-bit prime", w, class, value, w)
+			}
+			if class == PrimeStrong && value.BitLen() != w {
+				t.Errorf("w=%d %s: 0x%X has only %d bits", w, class, value, value.BitLen())
+			}
+			if passed, details := g.primeClassTest(value, class); !passed {
+				t.Errorf("w=%d %s: 0x%X fails its class: %s", w, class, value, details)
+			}
+		}
+	}
+}
+
+func TestSieved(t *testing.T) {
+	residues := make([]uint64, len(smallPrimes))
+	for _, tt := range []struct {
+		value uint64
+		class string
+		want  bool
+	}{
+		{4099, PrimeAny, false},
+		{4097, PrimeAny, true},   // 17 × 241
+		{4099, PrimeTwin, true},  // 4097 = 17 × 241, 4101 = 3 × 1367
+		{2089, PrimeTwin, false}, // 2091 = 3 × 697, but 2087 is prime
+		{2111, PrimeTwin, false}, // 2109 = 3 × 703, but 2113 is prime
+		{4091, PrimeSafe, true},  // 2045 = 5 × 409
+		{5807, PrimeSafe, false},
+		{4099, PrimeSophieGermain, true}, // 8199 = 3 × 2733
+		{4019, PrimeSophieGermain, false},
+	} {
+		x := word.FromUint64(tt.value)
+		residuesOf(x, residues)
+		if got := sieved(x, residues, tt.class); got != tt.want {
+			t.Errorf("sieved(%d, %s) = %v, want %v", tt.value, tt.class, got, tt.want)
+		}
+	}
+}
+
+func TestPrimeClassRecordedAndVerified(t *testing.T) {
+	config := DefaultConfig()
+	config.Seed = "verify"
+	config.AvalancheTestCases = 50
+	config.DetailedLogging = false
+	config.PrimeClass = PrimeSafe
+	g := NewGenerator(config)
+
+	var selected []ConstantCandidate
+	for i := 0; i < 2; i++ {
+		c, err := g.generateCandidate(i)
+		if err != nil {
+			t.Fatalf("generateCandidate(%d): %v", i, err)
+		}
+		tests := c.TestResults.PrimalityTests
+		if len(tests) != 2 || tests[1].Class != PrimeSafe || !tests[1].Passed {
+			t.Fatalf("PrimalityTests = %+v, want a passed safe class test", tests)
+		}
+		c.TestResults.StatisticalTests = g.runAllStatisticalTests(c.Value)
+		selected = append(selected, c)
+	}
+	result := &GenerationResult{SelectedP: selected[0], SelectedQ: selected[1], Config: config}
+	if check := findCheck(t, g.Verify(result), "P", "Prime Class"); !check.Passed {
+		t.Errorf("Prime Class failed: %s", check.Details)
+	}
+
+	// A prime that is not safe
+	result.SelectedP.Value = word.FromUint64(0x7FFFFFFF)
+	if check := findCheck(t, g.Verify(result), "P", "Prime Class"); check.Passed {
+		t.Errorf("Prime Class passed: %s", check.Details)
+	}
+	if check := findCheck(t, NewGenerator(DefaultConfig()).Verify(result), "P", "Prime Class"); check.Passed {
+		t.Errorf("Prime Class passed without PrimeClass configured: %s", check.Details)
+	}
+}
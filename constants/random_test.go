@@ -0,0 +1,141 @@
+package constants
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// First SHA-256 vector of the NIST CAVP HMAC_DRBG tests (no reseed, no
+// prediction resistance, no additional input or personalization).
+func TestHMACDRBGKnownAnswer(t *testing.T) {
+	entropy, _ := hex.DecodeString("ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488")
+	nonce, _ := hex.DecodeString("659ba96c601dc69fc902940805ec0ca8")
+	want, _ := hex.DecodeString("e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89" +
+		"d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc1" +
+		"07694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668" +
+		"961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8")
+
+	d := newHMACDRBG(entropy, nonce, nil)
+	got := make([]byte, len(want))
+	d.Read(got)
+	d.Read(got)
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("HMAC-DRBG output = %x, want %x", got, want)
+	}
+}
+
+func TestSeededSourceStreams(t *testing.T) {
+	read := func(r io.Reader, n int) []byte {
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			t.Fatalf("Read() error = %v", err)
+		}
+		return b
+	}
+
+	source := NewSeededSource("seed")
+	a := read(source.Stream(3), 64)
+	b := read(NewSeededSource("seed").Stream(3), 64)
+	if !bytes.Equal(a, b) {
+		t.Error("same seed and index produced different streams")
+	}
+
+	if bytes.Equal(a, read(source.Stream(4), 64)) {
+		t.Error("different indices produced the same stream")
+	}
+	if bytes.Equal(a, read(NewSeededSource("other").Stream(3), 64)) {
+		t.Error("different seeds produced the same stream")
+	}
+
+	// Reads longer than one DRBG request are split, not truncated
+	long := read(source.Stream(5), 3*hmacDRBGMaxRequest+17)
+	if bytes.Equal(long[:64], long[hmacDRBGMaxRequest:hmacDRBGMaxRequest+64]) {
+		t.Error("DRBG repeated output across requests")
+	}
+}
+
+func TestCryptoSource(t *testing.T) {
+	r := CryptoSource{}.Stream(0)
+	a, b := make([]byte, 32), make([]byte, 32)
+	io.ReadFull(r, a)
+	io.ReadFull(r, b)
+	if bytes.Equal(a, b) {
+		t.Error("crypto source returned identical blocks")
+	}
+}
+
+func TestRandomWord(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	if _, err := g.randomWord(bytes.NewReader([]byte{1, 2})); err == nil {
+		t.Error("randomWord on a short read: no error")
+	}
+
+	rng := NewSeededSource("words").Stream(7)
+	for i, want := range seededWords("words", 7, 32, 3) {
+		got, err := g.randomWord(rng)
+		if err != nil || got != want {
+			t.Errorf("word %d: 0x%X, %v; seededWords gives 0x%X", i, got, err, want)
+		}
+	}
+}
+
+func TestDrawKeys(t *testing.T) {
+	g := NewGenerator(testGenerationConfig("primer-keys"))
+	keys, err := g.drawKeys(nistStream, 3, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := g.drawKeys(nistStream, 3, 16)
+	other, _ := g.drawKeys(keyScheduleStream, 3, 16)
+	for i := range keys {
+		if len(keys[i]) != 16 || !bytes.Equal(keys[i], again[i]) {
+			t.Errorf("key %d = %x, then %x", i, keys[i], again[i])
+		}
+		if bytes.Equal(keys[i], other[i]) {
+			t.Errorf("key %d is the same on both streams", i)
+		}
+	}
+}
+
+func TestParallelMap(t *testing.T) {
+	var mu sync.Mutex
+	running, peak := 0, 0
+	squares, err := parallelMap(20, 3, func(i int) (int, error) {
+		mu.Lock()
+		running++
+		peak = max(peak, running)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return i * i, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, s := range squares {
+		if s != i*i {
+			t.Errorf("result %d = %d, want %d", i, s, i*i)
+		}
+	}
+	if peak > 3 {
+		t.Errorf("%d calls ran at once, want at most 3", peak)
+	}
+
+	_, err = parallelMap(5, 2, func(i int) (int, error) {
+		if i >= 2 {
+			return 0, fmt.Errorf("call %d failed", i)
+		}
+		return i, nil
+	})
+	if err == nil || err.Error() != "call 2 failed" {
+		t.Errorf("error = %v, want the error of call 2", err)
+	}
+}
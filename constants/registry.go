@@ -0,0 +1,205 @@
+package constants
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"primer/word"
+)
+
+// TestEnv is what a statistical test knows about the run: the word size of
+// the value under test and the configured thresholds.
+type TestEnv struct {
+	WordSize   uint
+	Thresholds StatisticalTestsConfig
+
+	g *Generator
+}
+
+// Test is a statistical test that runs on every candidate. Tests are added
+// with RegisterTest and can be switched off by name with
+// RandomnessTests.DisabledTests.
+type Test interface {
+	// Name identifies the test in results, configuration and weights.
+	Name() string
+	// Weight is the test's weight in the aggregate score and the failed
+	// fraction, unless the configuration sets one.
+	Weight() float64
+	// Run tests value. The result's Passed field applies the test's pass
+	// criterion.
+	Run(value word.Word, env TestEnv) StatisticalTest
+}
+
+// StatisticalTestFunc measures value and returns a score between 0 and 1,
+// higher being more random, with details for reports.
+type StatisticalTestFunc func(value word.Word, env TestEnv) StatisticalTest
+
+// NewTest returns a Test that runs fn. If pass is nil the test passes when
+// fn reports it passed; otherwise pass decides on fn's result.
+func NewTest(name string, weight float64, fn StatisticalTestFunc, pass func(StatisticalTest) bool) Test {
+	return funcTest{name: name, weight: weight, fn: fn, pass: pass}
+}
+
+type funcTest struct {
+	name   string
+	weight float64
+	fn     StatisticalTestFunc
+	pass   func(StatisticalTest) bool
+}
+
+func (t funcTest) Name() string    { return t.name }
+func (t funcTest) Weight() float64 { return t.weight }
+
+func (t funcTest) Run(value word.Word, env TestEnv) StatisticalTest {
+	result := t.fn(value, env)
+	result.Name = t.name
+	if t.pass != nil {
+		result.Passed = t.pass(result)
+	}
+	return result
+}
+
+// builtinTest is one of the package's own tests. Their weights come from
+// RandomnessTests, and the extended ones only run with EnableExtendedTests.
+type builtinTest struct {
+	name     string
+	extended bool
+	run      func(g *Generator, value word.Word) StatisticalTest
+}
+
+func (t builtinTest) Name() string    { return t.name }
+func (t builtinTest) Weight() float64 { return 1.0 }
+
+func (t builtinTest) Run(value word.Word, env TestEnv) StatisticalTest {
+	return t.run(env.g, value)
+}
+
+var registry struct {
+	sync.RWMutex
+	tests []Test
+}
+
+func init() {
+	for _, t := range []builtinTest{
+		{bitFrequencyTestName, false, (*Generator).runBitFrequencyTest},
+		{runsTestName, false, (*Generator).runRunsTest},
+		{serialTestName, true, (*Generator).runSerialTest},
+		{autocorrelationTestName, true, (*Generator).runAutoCorrelationTest},
+		{linearComplexityTestName, true, (*Generator).runLinearComplexityTest},
+	} {
+		MustRegisterTest(t)
+	}
+}
+
+// RegisterTest adds t to the tests run on every candidate, after those
+// already registered. Names are unique, compared case-insensitively.
+// Packages usually register their tests from an init function.
+func RegisterTest(t Test) error {
+	name := strings.TrimSpace(t.Name())
+	if name == "" {
+		return fmt.Errorf("statistical test has no name")
+	}
+	if t.Weight() < 0 {
+		return fmt.Errorf("statistical test %q has a negative weight", name)
+	}
+
+	registry.Lock()
+	defer registry.Unlock()
+	for _, existing := range registry.tests {
+		if strings.EqualFold(existing.Name(), name) {
+			return fmt.Errorf("statistical test %q is already registered", name)
+		}
+	}
+	registry.tests = append(registry.tests, t)
+	return nil
+}
+
+// MustRegisterTest is like RegisterTest but panics on error.
+func MustRegisterTest(t Test) {
+	if err := RegisterTest(t); err != nil {
+		panic(err)
+	}
+}
+
+// RegisteredTests returns the registered tests in registration order.
+func RegisteredTests() []Test {
+	registry.RLock()
+	defer registry.RUnlock()
+	return append([]Test(nil), registry.tests...)
+}
+
+// lookupTest finds a registered test by name, case-insensitively.
+func lookupTest(name string) (Test, bool) {
+	for _, t := range RegisteredTests() {
+		if strings.EqualFold(t.Name(), name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// registeredTestNames returns the names of the registered tests.
+func registeredTestNames() []string {
+	tests := RegisteredTests()
+	names := make([]string, len(tests))
+	for i, t := range tests {
+		names[i] = t.Name()
+	}
+	return names
+}
+
+// validateTestNames checks that every name in the enable and disable lists
+// is a registered test.
+func validateTestNames(r *RandomnessTestsConfig) error {
+	for _, list := range []struct {
+		field string
+		names []string
+	}{
+		{"EnabledTests", r.EnabledTests},
+		{"DisabledTests", r.DisabledTests},
+	} {
+		for _, name := range list.names {
+			if _, ok := lookupTest(name); !ok {
+				return fmt.Errorf("RandomnessTests.%s: unknown statistical test %q (registered: %s)",
+					list.field, name, strings.Join(registeredTestNames(), ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// enabledTests returns the registered tests this generator runs: all of
+// them, or those in EnabledTests if it is set, without the DisabledTests
+// and, unless EnableExtendedTests is set, the extended built-in tests.
+func (g *Generator) enabledTests() []Test {
+	settings := g.randomnessTests()
+	listed := func(names []string, t Test) bool {
+		for _, name := range names {
+			if strings.EqualFold(name, t.Name()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var tests []Test
+	for _, t := range RegisteredTests() {
+		if b, ok := t.(builtinTest); ok && b.extended && !settings.EnableExtendedTests {
+			continue
+		}
+		if len(settings.EnabledTests) > 0 && !listed(settings.EnabledTests, t) {
+			continue
+		}
+		if listed(settings.DisabledTests, t) {
+			continue
+		}
+		tests = append(tests, t)
+	}
+	return tests
+}
+
+// testEnv returns the environment passed to each test.
+func (g *Generator) testEnv() TestEnv {
+	return TestEnv{WordSize: g.wordSize(), Thresholds: g.statisticalTests(), g: g}
+}
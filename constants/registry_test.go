@@ -0,0 +1,145 @@
+package constants
+
+import (
+	"math/bits"
+	"reflect"
+	"testing"
+
+	"primer/word"
+)
+
+// registerForTest registers t for the duration of the test.
+func registerForTest(tb testing.TB, t Test) {
+	tb.Helper()
+	if err := RegisterTest(t); err != nil {
+		tb.Fatalf("RegisterTest() error = %v", err)
+	}
+	tb.Cleanup(func() {
+		registry.Lock()
+		defer registry.Unlock()
+		for i, existing := range registry.tests {
+			if existing.Name() == t.Name() {
+				registry.tests = append(registry.tests[:i:i], registry.tests[i+1:]...)
+				return
+			}
+		}
+	})
+}
+
+// lowByteTest passes when the low byte of the value is balanced.
+var lowByteTest = NewTest("Low Byte Balance", 2.5,
+	func(value word.Word, env TestEnv) StatisticalTest {
+		ones := bits.OnesCount8(uint8(value.Lo))
+		return StatisticalTest{Score: 1 - float64(abs(ones-4))/4}
+	},
+	func(result StatisticalTest) bool { return result.Score >= 0.5 },
+)
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func testNames(tests []StatisticalTest) []string {
+	var names []string
+	for _, test := range tests {
+		names = append(names, test.Name)
+	}
+	return names
+}
+
+func TestBuiltinTestsRegistered(t *testing.T) {
+	want := []string{bitFrequencyTestName, runsTestName, serialTestName, autocorrelationTestName, linearComplexityTestName}
+	if got := registeredTestNames(); !reflect.DeepEqual(got, want) {
+		t.Errorf("registered tests = %q, want %q", got, want)
+	}
+}
+
+func TestRegisterTestErrors(t *testing.T) {
+	noop := func(word.Word, TestEnv) StatisticalTest { return StatisticalTest{} }
+	tests := []struct {
+		name string
+		test Test
+	}{
+		{"Empty name", NewTest(" ", 1, noop, nil)},
+		{"Duplicate name", NewTest("runs test", 1, noop, nil)},
+		{"Negative weight", NewTest("Negative", -1, noop, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterTest(tt.test); err == nil {
+				t.Error("RegisterTest() succeeded")
+			}
+		})
+	}
+}
+
+func TestThirdPartyTest(t *testing.T) {
+	registerForTest(t, lowByteTest)
+
+	config := DefaultConfig()
+	g := NewGenerator(config)
+	value := word.FromUint64(0xB7E1510F) // low byte 0x0F: four ones
+	tests := g.runAllStatisticalTests(value)
+
+	want := []string{bitFrequencyTestName, runsTestName, serialTestName, autocorrelationTestName, linearComplexityTestName, "Low Byte Balance"}
+	if got := testNames(tests); !reflect.DeepEqual(got, want) {
+		t.Fatalf("tests = %q, want %q", got, want)
+	}
+	if last := tests[len(tests)-1]; !last.Passed || last.Score != 1 {
+		t.Errorf("Low Byte Balance = %+v, want a pass with score 1", last)
+	}
+	if w := g.statisticalTestWeight("Low Byte Balance"); w != 2.5 {
+		t.Errorf("weight = %v, want 2.5", w)
+	}
+
+	// The pass criterion applies to the measured score
+	if low := g.runAllStatisticalTests(word.FromUint64(0xB7E15100)); low[len(low)-1].Passed {
+		t.Error("Low Byte Balance passed a zero low byte")
+	}
+}
+
+func TestEnableDisableTests(t *testing.T) {
+	registerForTest(t, lowByteTest)
+
+	tests := []struct {
+		name     string
+		modify   func(*RandomnessTestsConfig)
+		want     []string
+		validErr bool
+	}{
+		{"Disable by name", func(r *RandomnessTestsConfig) {
+			r.DisabledTests = []string{"serial test", "Low Byte Balance"}
+		}, []string{bitFrequencyTestName, runsTestName, autocorrelationTestName, linearComplexityTestName}, false},
+		{"Enable only", func(r *RandomnessTestsConfig) {
+			r.EnabledTests = []string{"Low Byte Balance", runsTestName}
+		}, []string{runsTestName, "Low Byte Balance"}, false},
+		{"Extended tests off", func(r *RandomnessTestsConfig) {
+			r.EnableExtendedTests = false
+		}, []string{bitFrequencyTestName, runsTestName, "Low Byte Balance"}, false},
+		{"Unknown name", func(r *RandomnessTestsConfig) {
+			r.DisabledTests = []string{"Spectral Test"}
+		}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			tt.modify(&config.RandomnessTests)
+			if err := ValidateConfig(&config); (err != nil) != tt.validErr {
+				t.Fatalf("ValidateConfig() error = %v, wantErr %v", err, tt.validErr)
+			}
+			if tt.validErr {
+				return
+			}
+
+			got := testNames(NewGenerator(config).runAllStatisticalTests(RC6_P))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tests = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package constants
+
+import (
+	"math"
+	"math/bits"
+
+	"primer/special"
+	"primer/word"
+)
+
+// avalancheMatrices builds the strict avalanche and bit independence
+// matrices of a w-bit function. diffs(i, yield) yields every output
+// difference observed when input bit i was flipped.
+func avalancheMatrices(w uint, diffs func(input uint, yield func(word.Word))) (*SACMatrix, *BICMatrix) {
+	n := int(w)
+	sac := &SACMatrix{Deviations: make([][]float64, n)}
+	bic := &BICMatrix{Correlations: make([][]float64, n)}
+	for j := range bic.Correlations {
+		bic.Correlations[j] = make([]float64, n)
+	}
+
+	var samples []word.Word
+	flips := make([]int, n)
+	columns := make([][]uint64, n)
+	for i := 0; i < n; i++ {
+		samples = samples[:0]
+		diffs(uint(i), func(d word.Word) { samples = append(samples, d) })
+		m := len(samples)
+		sac.Samples += m
+		sac.Deviations[i] = make([]float64, n)
+		if m == 0 {
+			continue
+		}
+
+		// One bitset over the samples per output bit, so that the joint
+		// flips of two output bits are a popcount of their intersection
+		blocks := (m + 63) / 64
+		for j := range columns {
+			flips[j] = 0
+			if cap(columns[j]) < blocks {
+				columns[j] = make([]uint64, blocks)
+			}
+			columns[j] = columns[j][:blocks]
+			clear(columns[j])
+		}
+		for s, d := range samples {
+			for h, v := range [2]uint64{d.Lo, d.Hi} {
+				for ; v != 0; v &= v - 1 {
+					j := 64*h + bits.TrailingZeros64(v)
+					if j < n {
+						flips[j]++
+						columns[j][s/64] |= 1 << uint(s%64)
+					}
+				}
+			}
+		}
+
+		for j := 0; j < n; j++ {
+			deviation := float64(flips[j])/float64(m) - 0.5
+			sac.Deviations[i][j] = deviation
+			sac.MeanDeviation += math.Abs(deviation)
+			if math.Abs(deviation) > sac.MaxDeviation {
+				sac.MaxDeviation = math.Abs(deviation)
+				sac.WorstInput, sac.WorstOutput = i, j
+			}
+			d := float64(2*flips[j] - m)
+			sac.ChiSquare += d * d / float64(m)
+		}
+
+		for j := 0; j < n; j++ {
+			a := float64(flips[j])
+			for k := j + 1; k < n; k++ {
+				b := float64(flips[k])
+				// Output bits that always or never flip have no
+				// correlation; the SAC matrix reports them
+				variance := a * (float64(m) - a) * b * (float64(m) - b)
+				if variance == 0 {
+					continue
+				}
+				both := 0
+				for t, x := range columns[j] {
+					both += bits.OnesCount64(x & columns[k][t])
+				}
+				r := math.Abs(float64(m)*float64(both)-a*b) / math.Sqrt(variance)
+				if r > bic.Correlations[j][k] {
+					bic.Correlations[j][k] = r
+					bic.Correlations[k][j] = r
+				}
+				if r > bic.MaxCorrelation {
+					bic.MaxCorrelation = r
+					bic.WorstInput = i
+					bic.WorstOutputs = [2]int{j, k}
+				}
+			}
+		}
+	}
+
+	sac.MeanDeviation /= float64(n * n)
+	sac.PValue = special.ChiSquareSurvival(sac.ChiSquare, float64(n*n))
+	return sac, bic
+}
+
+// loadWord reads a little-endian w-bit word, the byte order RC6 uses.
+func loadWord(b []byte, w uint) word.Word {
+	var x word.Word
+	for i := int(w/8) - 1; i >= 0; i-- {
+		if i >= 8 {
+			x.Hi = x.Hi<<8 | uint64(b[i])
+		} else {
+			x.Lo = x.Lo<<8 | uint64(b[i])
+		}
+	}
+	return x
+}
+
+// avalancheCriteriaReason returns the rejection reason for the first SAC
+// or BIC limit the full transform misses, or "" if it meets them all. The
+// last avalanche test is the full transform; reduced rounds are not held
+// to the limits.
+func (g *Generator) avalancheCriteriaReason(tests []AvalancheTest) string {
+	if len(tests) == 0 {
+		return ""
+	}
+	full := tests[len(tests)-1]
+	if sac := full.SAC; sac != nil {
+		if limit := g.config.MaxSACDeviation; limit > 0 && sac.MaxDeviation > limit {
+			return RejectSAC
+		}
+		if limit := g.config.MinSACPValue; limit > 0 && sac.PValue < limit {
+			return RejectSAC
+		}
+	}
+	if bic := full.BIC; bic != nil {
+		if limit := g.config.MaxBICCorrelation; limit > 0 && bic.MaxCorrelation > limit {
+			return RejectBIC
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package constants
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"primer/word"
+)
+
+func TestAvalancheMatricesIdentity(t *testing.T) {
+	// Flipping input bit i flips output bit i only
+	sac, bic := avalancheMatrices(16, func(i uint, yield func(word.Word)) {
+		for n := 0; n < 100; n++ {
+			yield(word.FromUint64(1 << i))
+		}
+	})
+
+	if sac.Samples != 1600 {
+		t.Errorf("Samples = %d, want 1600", sac.Samples)
+	}
+	for i, row := range sac.Deviations {
+		for j, d := range row {
+			want := -0.5
+			if i == j {
+				want = 0.5
+			}
+			if d != want {
+				t.Fatalf("Deviations[%d][%d] = %v, want %v", i, j, d, want)
+			}
+		}
+	}
+	if sac.MaxDeviation != 0.5 || sac.MeanDeviation != 0.5 || sac.PValue != 0 {
+		t.Errorf("SAC = %+v", *sac)
+	}
+	// Output bits that never or always flip carry no correlation
+	if bic.MaxCorrelation != 0 {
+		t.Errorf("BIC max correlation = %v, want 0", bic.MaxCorrelation)
+	}
+}
+
+func TestAvalancheMatricesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	sac, bic := avalancheMatrices(32, func(i uint, yield func(word.Word)) {
+		for n := 0; n < 2000; n++ {
+			yield(word.FromUint64(uint64(rng.Uint32())))
+		}
+	})
+
+	// 2000 samples per cell: deviations of about 0.011
+	if sac.MaxDeviation > 0.05 || sac.MeanDeviation > 0.015 {
+		t.Errorf("SAC deviations mean %.4f, max %.4f", sac.MeanDeviation, sac.MaxDeviation)
+	}
+	if sac.PValue < 0.001 {
+		t.Errorf("SAC p-value %v for random differences", sac.PValue)
+	}
+	if bic.MaxCorrelation > 0.12 {
+		t.Errorf("BIC max correlation %.4f for random differences", bic.MaxCorrelation)
+	}
+	j, k := bic.WorstOutputs[0], bic.WorstOutputs[1]
+	if bic.Correlations[j][k] != bic.MaxCorrelation || bic.Correlations[k][j] != bic.MaxCorrelation {
+		t.Errorf("worst pair (%d, %d) does not hold the maximum", j, k)
+	}
+}
+
+func TestAvalancheMatricesCorrelated(t *testing.T) {
+	// Output bits 0 and 1 always flip together, half of the time
+	rng := rand.New(rand.NewSource(2))
+	_, bic := avalancheMatrices(16, func(i uint, yield func(word.Word)) {
+		for n := 0; n < 500; n++ {
+			v := uint64(rng.Uint32()) &^ 3
+			if rng.Intn(2) == 0 {
+				v |= 3
+			}
+			yield(word.FromUint64(v))
+		}
+	})
+	if math.Abs(bic.MaxCorrelation-1) > 1e-12 || bic.WorstOutputs != [2]int{0, 1} {
+		t.Errorf("BIC worst %v at %v, want 1 at [0 1]", bic.MaxCorrelation, bic.WorstOutputs)
+	}
+}
+
+func TestAvalancheTestsMatrices(t *testing.T) {
+	for _, transform := range []string{TransformRC6, TransformSimple} {
+		config := DefaultConfig()
+		config.Transform = transform
+		config.AvalancheTestCases = 200
+		g := NewGenerator(config)
+
+		for _, test := range g.runAvalancheTests(RC6_P, g.stream(0)) {
+			if test.SAC == nil || test.BIC == nil {
+				t.Fatalf("%s: no matrices", transform)
+			}
+			if len(test.SAC.Deviations) != 32 || len(test.SAC.Deviations[31]) != 32 ||
+				len(test.BIC.Correlations) != 32 {
+				t.Errorf("%s: matrices are not 32x32", transform)
+			}
+			// One sample per flip, with the constant as P and as Q
+			if transform == TransformRC6 && test.SAC.Samples != 2*config.AvalancheTestCases {
+				t.Errorf("%s: %d SAC samples, want %d", transform, test.SAC.Samples, 2*config.AvalancheTestCases)
+			}
+			// The matrices break down the same bit changes as Score
+			mean := 0.0
+			for _, row := range test.SAC.Deviations {
+				for _, d := range row {
+					mean += 0.5 + d
+				}
+			}
+			if mean /= 32 * 32; math.Abs(mean-test.Score) > 0.01 {
+				t.Errorf("%s: SAC mean %.4f, score %.4f", transform, mean, test.Score)
+			}
+		}
+	}
+}
+
+func TestLoadWord(t *testing.T) {
+	b := []byte{0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F, 0x10}
+	tests := []struct {
+		w    uint
+		want word.Word
+	}{
+		{16, word.FromUint64(0x0201)},
+		{32, word.FromUint64(0x04030201)},
+		{128, word.Word{Hi: 0x100F0E0D0C0B0A09, Lo: 0x0807060504030201}},
+	}
+	for _, tt := range tests {
+		if got := loadWord(b, tt.w); got != tt.want {
+			t.Errorf("loadWord(w=%d) = %v, want %v", tt.w, got, tt.want)
+		}
+	}
+}
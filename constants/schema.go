@@ -0,0 +1,107 @@
+package constants
+
+import (
+	"reflect"
+
+	"primer/expansion"
+)
+
+// schemaEnums lists the accepted values of the fields that take one of a
+// fixed set, keyed by the field's path in Config. Derivation.Sources comes
+// from the expansion registry.
+var schemaEnums = map[string][]interface{}{
+	"WordSize":          {16, 32, 64, 128},
+	"Transform":         {TransformRC6, TransformSimple},
+	"GenerationMode":    {ModeRandom, ModeDerive},
+	"PrimeClass":        {PrimeAny, PrimeSafe, PrimeSophieGermain, PrimeStrong, PrimeChen, PrimeTwin},
+	"Derivation.Rules":  {RuleWindow, RuleOdd, RuleNextPrime},
+	"SelectionStrategy": {StrategyScore, StrategyPareto},
+	"ValidationCriteria.TestAggregation": {AggregateFraction, AggregateBonferroni, AggregateHolm,
+		AggregateBenjaminiHochberg, AggregateFisher},
+}
+
+// ConfigSchema returns a JSON Schema (draft 2020-12) describing Config, with
+// the values of DefaultConfig as defaults. Keys starting with "//" are
+// accepted anywhere as comments.
+func ConfigSchema() map[string]interface{} {
+	schema := schemaFor(reflect.TypeOf(Config{}), reflect.ValueOf(DefaultConfig()), "")
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "primer configuration"
+	return schema
+}
+
+func schemaFor(t reflect.Type, def reflect.Value, path string) map[string]interface{} {
+	schema := map[string]interface{}{}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			fieldPath := field.Name
+			if path != "" {
+				fieldPath = path + "." + field.Name
+			}
+			properties[field.Name] = schemaFor(field.Type, def.Field(i), fieldPath)
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["patternProperties"] = map[string]interface{}{"^" + commentPrefix: map[string]interface{}{}}
+		schema["additionalProperties"] = false
+		return schema
+	case reflect.Slice:
+		schema["type"] = "array"
+		items := schemaFor(t.Elem(), reflect.Value{}, path)
+		if enum, ok := enumFor(path); ok {
+			items["enum"] = enum
+		}
+		schema["items"] = items
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		schema["type"] = "integer"
+	case reflect.Float64:
+		schema["type"] = "number"
+	case reflect.String:
+		schema["type"] = "string"
+	}
+
+	if t.Kind() != reflect.Slice {
+		if enum, ok := enumFor(path); ok {
+			schema["enum"] = enum
+		}
+	}
+	if def.IsValid() {
+		schema["default"] = def.Interface()
+	}
+	return schema
+}
+
+func enumFor(path string) ([]interface{}, bool) {
+	if path == "Derivation.Sources" {
+		var names []interface{}
+		for _, name := range expansion.Names() {
+			names = append(names, name)
+		}
+		return names, true
+	}
+	if path == "RandomnessTests.EnabledTests" || path == "RandomnessTests.DisabledTests" {
+		var names []interface{}
+		for _, name := range registeredTestNames() {
+			names = append(names, name)
+		}
+		return names, true
+	}
+	if path == "ParetoTieBreakers" {
+		var names []interface{}
+		for _, name := range tieBreakerNames() {
+			names = append(names, name)
+		}
+		return names, true
+	}
+	enum, ok := schemaEnums[path]
+	return enum, ok
+}
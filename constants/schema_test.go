@@ -0,0 +1,65 @@
+package constants
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestConfigSchema(t *testing.T) {
+	schema := ConfigSchema()
+	if _, err := json.Marshal(schema); err != nil {
+		t.Fatalf("schema does not marshal: %v", err)
+	}
+
+	if schema["additionalProperties"] != false {
+		t.Error("unknown top-level keys are allowed")
+	}
+	properties := schema["properties"].(map[string]interface{})
+	configType := reflect.TypeOf(Config{})
+	if len(properties) != configType.NumField() {
+		t.Errorf("schema has %d properties, Config has %d fields", len(properties), configType.NumField())
+	}
+
+	wordSize := properties["WordSize"].(map[string]interface{})
+	if wordSize["type"] != "integer" || wordSize["default"] != 32 {
+		t.Errorf("WordSize schema = %v", wordSize)
+	}
+	if !reflect.DeepEqual(wordSize["enum"], []interface{}{16, 32, 64, 128}) {
+		t.Errorf("WordSize enum = %v", wordSize["enum"])
+	}
+
+	derivation := properties["Derivation"].(map[string]interface{})
+	rules := derivation["properties"].(map[string]interface{})["Rules"].(map[string]interface{})
+	if rules["type"] != "array" || rules["items"].(map[string]interface{})["enum"] == nil {
+		t.Errorf("Derivation.Rules schema = %v", rules)
+	}
+
+	stats := properties["StatisticalTests"].(map[string]interface{})
+	if stats["additionalProperties"] != false {
+		t.Error("unknown StatisticalTests keys are allowed")
+	}
+}
+
+func TestShippedConfigMatchesSchema(t *testing.T) {
+	data, err := os.ReadFile("../config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shipped map[string]interface{}
+	if err := json.Unmarshal(data, &shipped); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := ConfigSchema()["properties"].(map[string]interface{})
+	for key := range shipped {
+		if strings.HasPrefix(key, commentPrefix) {
+			continue
+		}
+		if _, ok := properties[key]; !ok {
+			t.Errorf("config.json key %q is not in the schema", key)
+		}
+	}
+}
@@ -3,44 +3,26 @@ package constants
 import (
 	"fmt"
 	"math"
-	"math/bits"
 	"sync"
-)
-
-// Statistical test thresholds
-const (
-	// P-value thresholds
-	minPValue = 0.01
-	maxPValue = 0.99
-
-	// Entropy thresholds
-	minEntropyScore = 1.0
-	maxEntropyScore = 2.5
 
-	// Frequency test thresholds
-	maxBitFrequencyDeviation = 0.15
-
-	// Runs test thresholds
-	minRunsZScore = -3.0
-	maxRunsZScore = 3.0
-
-	// Serial test thresholds
-	maxSerialCorrelation = 0.5
+	"primer/special"
+	"primer/word"
 )
 
 // calculateEntropy calculates Shannon entropy of bit distribution
-func (g *Generator) calculateEntropy(value uint32) float64 {
+func (g *Generator) calculateEntropy(value word.Word) float64 {
 	// Count frequency of each bit
+	w := g.wordSize()
 	counts := make(map[bool]int)
-	for i := 0; i < 32; i++ {
-		bit := (value & (1 << uint(i))) != 0
+	for i := uint(0); i < w; i++ {
+		bit := value.Bit(i) != 0
 		counts[bit]++
 	}
 
 	// Calculate Shannon entropy
 	entropy := 0.0
 	for _, count := range counts {
-		p := float64(count) / 32.0
+		p := float64(count) / float64(w)
 		if p > 0 {
 			entropy -= p * math.Log2(p)
 		}
@@ -50,33 +32,35 @@ func (g *Generator) calculateEntropy(value uint32) float64 {
 }
 
 // runBitFrequencyTest performs the frequency (monobit) test
-func (g *Generator) runBitFrequencyTest(value uint32) StatisticalTest {
-	ones := 0
-	for i := 0; i < 32; i++ {
-		if value&(1<<uint(i)) != 0 {
-			ones++
-		}
-	}
+func (g *Generator) runBitFrequencyTest(value word.Word) StatisticalTest {
+	w := g.wordSize()
+	ones := value.Truncate(w).OnesCount()
 
-	proportion := float64(ones) / 32.0
+	proportion := float64(ones) / float64(w)
 	deviation := math.Abs(proportion - 0.5)
 
+	// The sum of ±1 bits is approximately normal with variance w
+	sum := float64(2*ones) - float64(w)
+	pValue := special.Erfc(math.Abs(sum) / math.Sqrt(2*float64(w)))
+
 	return StatisticalTest{
-		Name:    "Bit Frequency Test",
+		Name:    bitFrequencyTestName,
 		Score:   1.0 - (deviation * 2), // Normalize to 0-1 scale
-		Passed:  deviation <= maxBitFrequencyDeviation,
-		Details: fmt.Sprintf("Proportion of ones: %.4f (deviation: %.4f)", proportion, deviation),
+		PValue:  pValue,
+		Passed:  pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("Proportion of ones: %.4f (deviation: %.4f, p-value: %.4f)", proportion, deviation, pValue),
 	}
 }
 
 // runRunsTest performs the runs test for randomness
-func (g *Generator) runRunsTest(value uint32) StatisticalTest {
+func (g *Generator) runRunsTest(value word.Word) StatisticalTest {
 	var runs int
-	var currentRun bool = value&1 != 0
+	var currentRun bool = value.Bit(0) != 0
+	w := g.wordSize()
 
 	// Count runs
-	for i := 1; i < 32; i++ {
-		bit := value&(1<<uint(i)) != 0
+	for i := uint(1); i < w; i++ {
+		bit := value.Bit(i) != 0
 		if bit != currentRun {
 			runs++
 			currentRun = bit
@@ -85,76 +69,101 @@ func (g *Generator) runRunsTest(value uint32) StatisticalTest {
 	runs++ // Count the last run
 
 	// Calculate expected runs and variance
-	n := 32
-	n1 := bits.OnesCount32(value)
+	n := int(w)
+	n1 := value.Truncate(w).OnesCount()
 	n0 := n - n1
 	expectedRuns := 1.0 + 2.0*float64(n0)*float64(n1)/float64(n)
 	variance := (expectedRuns - 1.0) * (expectedRuns - 2.0) / float64(n-1)
 
+	// A word of equal bits has one run and no variance: as far from random
+	// as a word gets
+	if variance <= 0 {
+		return StatisticalTest{
+			Name:    runsTestName,
+			Details: fmt.Sprintf("All bits equal (runs: %d)", runs),
+		}
+	}
+
 	// Calculate Z-score
 	zScore := (float64(runs) - expectedRuns) / math.Sqrt(variance)
+	pValue := special.NormalTwoSided(zScore)
 
 	return StatisticalTest{
-		Name:    "Runs Test",
-		Score:   1.0 - math.Abs(zScore/6.0), // Normalize to 0-1 scale
-		Passed:  zScore >= minRunsZScore && zScore <= maxRunsZScore,
-		Details: fmt.Sprintf("Z-score: %.4f (runs: %d, expected: %.2f)", zScore, runs, expectedRuns),
+		Name:    runsTestName,
+		Score:   pValue,
+		PValue:  pValue,
+		Passed:  pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("Z-score: %.4f (runs: %d, expected: %.2f, p-value: %.4f)", zScore, runs, expectedRuns, pValue),
 	}
 }
 
 // runSerialTest performs the serial test for 2-bit patterns
-func (g *Generator) runSerialTest(value uint32) StatisticalTest {
+func (g *Generator) runSerialTest(value word.Word) StatisticalTest {
 	// Count frequencies of 2-bit patterns
+	w := g.wordSize()
 	patterns := make([]int, 4)
-	for i := 0; i < 31; i++ {
-		pattern := (value >> uint(i)) & 0x3
+	for i := uint(0); i < w-1; i++ {
+		pattern := value.Rsh(i).Lo & 0x3
 		patterns[pattern]++
 	}
 
 	// Calculate chi-square statistic
-	expected := float64(31) / 4.0
+	expected := float64(w-1) / 4.0
 	chiSquare := 0.0
 	for _, count := range patterns {
 		chiSquare += math.Pow(float64(count)-expected, 2) / expected
 	}
 
-	// Calculate p-value
-	pValue := 1.0 - math.Exp(-chiSquare/2.0)
+	// Four patterns leave 3 degrees of freedom
+	pValue := special.ChiSquareSurvival(chiSquare, 3)
 
 	return StatisticalTest{
-		Name:    "Serial Test",
-		Score:   1.0 - math.Abs(pValue-0.5)*2, // Normalize to 0-1 scale
-		Passed:  pValue >= minPValue && pValue <= maxPValue,
-		Details: fmt.Sprintf("Chi-square: %.4f (p-value: %.4f)", chiSquare, pValue),
+		Name:    serialTestName,
+		Score:   pValue,
+		PValue:  pValue,
+		Passed:  pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("Chi-square: %.4f (3 degrees of freedom, p-value: %.4f)", chiSquare, pValue),
 	}
 }
 
 // runAutoCorrelationTest performs autocorrelation test
-func (g *Generator) runAutoCorrelationTest(value uint32) StatisticalTest {
+func (g *Generator) runAutoCorrelationTest(value word.Word) StatisticalTest {
 	maxCorrelation := 0.0
+	minPValue := 1.0
+	shifts := 0
 
 	// Test different shift values
-	for shift := 1; shift < 16; shift++ {
+	for shift := 1; shift < int(g.wordSize()/2); shift++ {
 		correlation := g.calculateAutocorrelation(value, shift)
 		maxCorrelation = math.Max(maxCorrelation, math.Abs(correlation))
+
+		// Matches are binomial over total bits, so the correlation times
+		// the square root of total is approximately standard normal
+		total := float64(int(g.wordSize()) - shift)
+		minPValue = math.Min(minPValue, special.NormalTwoSided(correlation*math.Sqrt(total)))
+		shifts++
 	}
 
+	// Šidák correction for the smallest of several p-values
+	pValue := -math.Expm1(float64(shifts) * math.Log1p(-minPValue))
+
 	return StatisticalTest{
-		Name:    "Autocorrelation Test",
+		Name:    autocorrelationTestName,
 		Score:   1.0 - maxCorrelation,
-		Passed:  maxCorrelation <= maxSerialCorrelation,
-		Details: fmt.Sprintf("Maximum correlation: %.4f", maxCorrelation),
+		PValue:  pValue,
+		Passed:  pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("Maximum correlation: %.4f (p-value: %.4f over %d shifts)", maxCorrelation, pValue, shifts),
 	}
 }
 
 // calculateAutocorrelation calculates autocorrelation for a given shift
-func (g *Generator) calculateAutocorrelation(value uint32, shift int) float64 {
+func (g *Generator) calculateAutocorrelation(value word.Word, shift int) float64 {
 	matches := 0
-	total := 32 - shift
+	total := int(g.wordSize()) - shift
 
 	for i := 0; i < total; i++ {
-		bit1 := (value >> uint(i)) & 1
-		bit2 := (value >> uint(i+shift)) & 1
+		bit1 := value.Bit(uint(i))
+		bit2 := value.Bit(uint(i + shift))
 		if bit1 == bit2 {
 			matches++
 		}
@@ -164,49 +173,71 @@ func (g *Generator) calculateAutocorrelation(value uint32, shift int) float64 {
 }
 
 // runLinearComplexityTest estimates the linear complexity
-func (g *Generator) runLinearComplexityTest(value uint32) StatisticalTest {
+func (g *Generator) runLinearComplexityTest(value word.Word) StatisticalTest {
+	w := int(g.wordSize())
 	complexity := g.calculateLinearComplexity(value)
-	expectedComplexity := 16.0 // Half of 32 bits
+	expectedComplexity := float64(w) / 2 // Half of the word size
 
 	deviation := math.Abs(float64(complexity) - expectedComplexity)
 	normalizedScore := 1.0 - (deviation / expectedComplexity)
+	pValue := linearComplexityPValue(complexity, w)
 
 	return StatisticalTest{
-		Name:    "Linear Complexity Test",
+		Name:    linearComplexityTestName,
 		Score:   normalizedScore,
-		Passed:  complexity >= 12, // At least 12 bits of complexity
-		Details: fmt.Sprintf("Linear complexity: %d bits", complexity),
+		PValue:  pValue,
+		Passed:  pValue >= g.statisticalTests().MinPValue,
+		Details: fmt.Sprintf("Linear complexity: %d bits (p-value: %.4f)", complexity, pValue),
+	}
+}
+
+// linearComplexityPValue returns the two-sided p-value of linear
+// complexity l for a random sequence of n bits. Of the 2^n sequences, one
+// has complexity 0 and 2^min(2L-1, 2n-2L) have complexity L.
+func linearComplexityPValue(l, n int) float64 {
+	below, above := 0.0, 0.0
+	for L := 0; L <= n; L++ {
+		p := math.Ldexp(1, -n)
+		if L > 0 {
+			p = math.Ldexp(1, min(2*L-1, 2*n-2*L)-n)
+		}
+		if L <= l {
+			below += p
+		}
+		if L >= l {
+			above += p
+		}
 	}
+	return math.Min(1, 2*math.Min(below, above))
 }
 
 // calculateLinearComplexity implements the Berlekamp-Massey algorithm
-func (g *Generator) calculateLinearComplexity(value uint32) int {
+func (g *Generator) calculateLinearComplexity(value word.Word) int {
 	// Convert to bit sequence
-	sequence := make([]int, 32)
-	for i := 0; i < 32; i++ {
-		if value&(1<<uint(i)) != 0 {
-			sequence[i] = 1
-		}
+	w := int(g.wordSize())
+	sequence := make([]int, w)
+	for i := 0; i < w; i++ {
+		sequence[i] = int(value.Bit(uint(i)))
 	}
 
 	// Berlekamp-Massey algorithm
 	L := 0
 	m := -1
 	d := 0
-	C := make([]int, 32)
-	B := make([]int, 32)
+	C := make([]int, w)
+	B := make([]int, w)
 	C[0] = 1
 	B[0] = 1
 
-	for n := 0; n < 32; n++ {
+	for n := 0; n < w; n++ {
 		d = sequence[n]
 		for i := 1; i <= L; i++ {
 			d ^= C[i] & sequence[n-i]
 		}
 		if d == 1 {
-			T := make([]int, 32)
+			T := make([]int, w)
 			copy(T, C)
-			for i := 0; i < 32-n+m; i++ {
+			for i := 0; i < w-n+m; i++ {
 				C[n-m+i] ^= B[i]
 			}
 			if L <= n/2 {
@@ -220,48 +251,73 @@ func (g *Generator) calculateLinearComplexity(value uint32) int {
 	return L
 }
 
-// runAllStatisticalTests runs all statistical tests on a value
-func (g *Generator) runAllStatisticalTests(value uint32) []StatisticalTest {
-	var mu sync.Mutex
-	var tests []StatisticalTest
+// Names of the statistical tests, as recorded in StatisticalTest.Name.
+const (
+	bitFrequencyTestName     = "Bit Frequency Test"
+	runsTestName             = "Runs Test"
+	serialTestName           = "Serial Test"
+	autocorrelationTestName  = "Autocorrelation Test"
+	linearComplexityTestName = "Linear Complexity Test"
+)
 
-	var wg sync.WaitGroup
-	testFuncs := []struct {
-		name string
-		fn   func(uint32) StatisticalTest
-	}{
-		{"BitFrequency", g.runBitFrequencyTest},
-		{"Runs", g.runRunsTest},
-		{"Serial", g.runSerialTest},
-		{"AutoCorrelation", g.runAutoCorrelationTest},
-		{"LinearComplexity", g.runLinearComplexityTest},
+// statisticalTestWeight returns the configured weight of the named test.
+func (g *Generator) statisticalTestWeight(name string) float64 {
+	weights := g.randomnessTests()
+	switch name {
+	case bitFrequencyTestName:
+		return weights.FrequencyTestWeight
+	case runsTestName:
+		return weights.RunsTestWeight
+	case serialTestName:
+		return weights.SerialTestWeight
+	case autocorrelationTestName:
+		return weights.AutocorrelationWeight
+	case linearComplexityTestName:
+		return weights.LinearComplexityWeight
+	}
+	if t, ok := lookupTest(name); ok {
+		return t.Weight()
 	}
+	return 1.0
+}
 
-	for _, tf := range testFuncs {
+// runAllStatisticalTests runs the enabled registered tests on a value, in
+// registration order.
+func (g *Generator) runAllStatisticalTests(value word.Word) []StatisticalTest {
+	var wg sync.WaitGroup
+	testFuncs := g.enabledTests()
+	env := g.testEnv()
+
+	// Results keep the order of testFuncs regardless of completion order
+	tests := make([]StatisticalTest, len(testFuncs))
+	for i, tf := range testFuncs {
 		wg.Add(1)
-		go func(name string, testFn func(uint32) StatisticalTest) {
+		go func(i int, test Test) {
 			defer wg.Done()
-			result := testFn(value)
-			mu.Lock()
-			tests = append(tests, result)
-			mu.Unlock()
-		}(tf.name, tf.fn)
+			tests[i] = test.Run(value, env)
+		}(i, tf)
 	}
 
 	wg.Wait()
 	return tests
 }
 
-// aggregateTestResults combines all test results into a single score
+// aggregateTestResults combines all test results into a single score,
+// weighted by RandomnessTests
 func (g *Generator) aggregateTestResults(tests []StatisticalTest) float64 {
 	if len(tests) == 0 {
 		return 0.0
 	}
 
-	totalScore := 0.0
+	totalScore, totalWeight := 0.0, 0.0
 	for _, test := range tests {
-		totalScore += test.Score
+		weight := g.statisticalTestWeight(test.Name)
+		totalScore += weight * test.Score
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return 0.0
 	}
 
-	return totalScore / float64(len(tests))
+	return totalScore / totalWeight
 }
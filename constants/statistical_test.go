@@ -3,11 +3,13 @@ package constants
 import (
 	"math"
 	"testing"
+
+	"primer/word"
 )
 
-const (
-	RC6_P uint32 = 0xB7E15163
-	RC6_Q uint32 = 0x9E3779B9
+var (
+	RC6_P = word.FromUint64(0xB7E15163)
+	RC6_Q = word.FromUint64(0x9E3779B9)
 )
 
 func TestRC6Constants(t *testing.T) {
@@ -15,7 +17,7 @@ func TestRC6Constants(t *testing.T) {
 
 	constants := []struct {
 		name              string
-		value             uint32
+		value             word.Word
 		expectedBitDist   float64
 		expectedAvalanche float64
 	}{
@@ -59,9 +61,10 @@ func TestRC6Constants(t *testing.T) {
 
 			// Test entropy with wider acceptable range
 			entropy := g.calculateEntropy(c.value)
-			if entropy < minEntropyScore || entropy > maxEntropyScore {
+			stats := g.config.StatisticalTests
+			if entropy < stats.MinEntropyScore || entropy > stats.MaxEntropyScore {
 				t.Logf("Note: Entropy %.4f outside typical range [%.4f, %.4f] but may be acceptable",
-					entropy, minEntropyScore, maxEntropyScore)
+					entropy, stats.MinEntropyScore, stats.MaxEntropyScore)
 			}
 		})
 	}
@@ -89,31 +92,31 @@ func TestCalculateEntropy(t *testing.T) {
 	g := &Generator{}
 	tests := []struct {
 		name    string
-		value   uint32
+		value   word.Word
 		wantMin float64
 		wantMax float64
 	}{
 		{
 			name:    "Zero value",
-			value:   0,
+			value:   word.FromUint64(0),
 			wantMin: 0,
 			wantMax: 0.1,
 		},
 		{
 			name:    "All ones",
-			value:   0xFFFFFFFF,
+			value:   word.FromUint64(0xFFFFFFFF),
 			wantMin: 0,
 			wantMax: 0.1,
 		},
 		{
 			name:    "Alternating bits",
-			value:   0xAAAAAAAA,
+			value:   word.FromUint64(0xAAAAAAAA),
 			wantMin: 0.9,
 			wantMax: 1.1,
 		},
 		{
 			name:    "Random-like value",
-			value:   0x1B7DE952,
+			value:   word.FromUint64(0x1B7DE952),
 			wantMin: 0.9,
 			wantMax: 1.1,
 		},
@@ -146,25 +149,25 @@ func TestRunBitFrequencyTest(t *testing.T) {
 	g := &Generator{}
 	tests := []struct {
 		name      string
-		value     uint32
+		value     word.Word
 		wantScore float64
 		wantPass  bool
 	}{
 		{
 			name:      "Balanced bits",
-			value:     0xAAAAAAAA,
+			value:     word.FromUint64(0xAAAAAAAA),
 			wantScore: 1.0,
 			wantPass:  true,
 		},
 		{
 			name:      "All zeros",
-			value:     0,
+			value:     word.FromUint64(0),
 			wantScore: 0.0,
 			wantPass:  false,
 		},
 		{
 			name:      "All ones",
-			value:     0xFFFFFFFF,
+			value:     word.FromUint64(0xFFFFFFFF),
 			wantScore: 0.0,
 			wantPass:  false,
 		},
@@ -199,22 +202,22 @@ func TestRunsTest(t *testing.T) {
 	g := &Generator{}
 	tests := []struct {
 		name     string
-		value    uint32
+		value    word.Word
 		wantPass bool
 	}{
 		{
 			name:     "Alternating bits",
-			value:    0xAAAAAAAA,
+			value:    word.FromUint64(0xAAAAAAAA),
 			wantPass: false,
 		},
 		{
 			name:     "All zeros",
-			value:    0,
+			value:    word.FromUint64(0),
 			wantPass: false,
 		},
 		{
 			name:     "All ones",
-			value:    0xFFFFFFFF,
+			value:    word.FromUint64(0xFFFFFFFF),
 			wantPass: false,
 		},
 	}
@@ -229,10 +232,95 @@ func TestRunsTest(t *testing.T) {
 	}
 }
 
+func TestStatisticalTestsWordSizes(t *testing.T) {
+	values := map[int]word.Word{
+		16:  word.FromUint64(0xB7E1),
+		32:  RC6_P,
+		64:  word.FromUint64(0xB7E151628AED2A6B),
+		128: {Hi: 0xB7E151628AED2A6A, Lo: 0xBF7158809CF4F3C7},
+	}
+
+	for w, value := range values {
+		config := DefaultConfig()
+		config.WordSize = w
+		g := NewGenerator(config)
+
+		if got := g.calculateBitDistribution(value); got <= 0.25 || got >= 0.75 {
+			t.Errorf("WordSize %d: bit distribution %.4f", w, got)
+		}
+
+		tests := g.runAllStatisticalTests(value)
+		if len(tests) != 5 {
+			t.Errorf("WordSize %d: got %d statistical tests, want 5", w, len(tests))
+		}
+		for _, test := range tests {
+			if math.IsNaN(test.Score) || math.IsInf(test.Score, 0) {
+				t.Errorf("WordSize %d: %s score %v", w, test.Name, test.Score)
+			}
+			if !(test.PValue >= 0 && test.PValue <= 1) {
+				t.Errorf("WordSize %d: %s p-value %v", w, test.Name, test.PValue)
+			}
+			if test.Passed != (test.PValue >= config.StatisticalTests.MinPValue) {
+				t.Errorf("WordSize %d: %s passed %v with p-value %v", w, test.Name, test.Passed, test.PValue)
+			}
+		}
+
+		// The all-ones word is never balanced, whatever the width
+		ones := word.Mask(uint(w))
+		if g.runBitFrequencyTest(ones).Passed {
+			t.Errorf("WordSize %d: all-ones word passed the frequency test", w)
+		}
+	}
+}
+
+func TestStatisticalPValues(t *testing.T) {
+	g := NewGenerator(DefaultConfig())
+	tests := []struct {
+		name  string
+		test  func(word.Word) StatisticalTest
+		value word.Word
+		want  float64
+	}{
+		// 17 ones of 32: erfc(2/8)
+		{"Frequency RC6 P", g.runBitFrequencyTest, RC6_P, 0.723674},
+		{"Frequency all zeros", g.runBitFrequencyTest, word.FromUint64(0), 0.000000},
+		// 32 runs of 16 ones and 16 zeros: z = 15/sqrt(7.742) = 5.391
+		{"Runs alternating", g.runRunsTest, word.FromUint64(0xAAAAAAAA), 0.000000},
+		{"Runs all ones", g.runRunsTest, word.FromUint64(0xFFFFFFFF), 0.000000},
+		// Pattern counts 0, 15, 16, 0 over 31 positions: chi-square 31.129
+		{"Serial alternating", g.runSerialTest, word.FromUint64(0xAAAAAAAA), 0.000001},
+		{"Linear complexity 0", g.runLinearComplexityTest, word.FromUint64(0), 0.000000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.test(tt.value).PValue; math.Abs(got-tt.want) > 1e-6 {
+				t.Errorf("p-value = %.6f, want %.6f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinearComplexityPValue(t *testing.T) {
+	// Half the sequences of n bits have complexity at most n/2
+	if p := linearComplexityPValue(16, 32); p != 1 {
+		t.Errorf("p-value of L=16 = %v, want 1", p)
+	}
+	// 1 + 2^1 + 2^3 + ... + 2^29 sequences have complexity at most 15
+	if p, want := linearComplexityPValue(15, 32), 2*(1.0/6+math.Ldexp(1, -32)/3); math.Abs(p-want) > 1e-12 {
+		t.Errorf("p-value of L=15 = %v, want %v", p, want)
+	}
+	if p := linearComplexityPValue(1, 32); p > 1e-8 {
+		t.Errorf("p-value of L=1 = %v", p)
+	}
+	if p := linearComplexityPValue(32, 32); p > 1e-8 {
+		t.Errorf("p-value of L=32 = %v", p)
+	}
+}
+
 // Benchmark tests
 func BenchmarkCalculateEntropy(b *testing.B) {
 	g := &Generator{}
-	value := uint32(0x1B7DE952)
+	value := word.FromUint64(0x1B7DE952)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		g.calculateEntropy(value)
@@ -241,7 +329,7 @@ func BenchmarkCalculateEntropy(b *testing.B) {
 
 func BenchmarkRunAllStatisticalTests(b *testing.B) {
 	g := &Generator{}
-	value := uint32(0x1B7DE952)
+	value := word.FromUint64(0x1B7DE952)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		g.runAllStatisticalTests(value)
@@ -0,0 +1,107 @@
+package constants
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// StreamStdout is the StreamFile value that streams candidates to standard
+// output.
+const StreamStdout = "-"
+
+// SetCandidateStream makes Generate write a CandidateRecord for every candidate to w
+// as NDJSON, overriding StreamFile. It must be called before Generate.
+func (g *Generator) SetCandidateStream(w io.Writer) {
+	g.candidateOutput = w
+}
+
+// openStream returns the writer for the candidate stream and a function
+// that closes it, or a nil writer when streaming is off. A resumed run
+// cuts StreamFile back to the length recorded in the checkpoint and
+// continues from there, dropping records written after the last checkpoint
+// that the resumed workers will produce again.
+func (g *Generator) openStream() (io.Writer, func() error, error) {
+	noop := func() error { return nil }
+	switch {
+	case g.candidateOutput != nil:
+		return g.candidateOutput, noop, nil
+	case g.config.StreamFile == "":
+		return nil, noop, nil
+	case g.config.StreamFile == StreamStdout:
+		return os.Stdout, noop, nil
+	}
+
+	f, err := os.OpenFile(g.config.StreamFile, os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("opening candidate stream: %w", err)
+	}
+	offset := g.resumedStreamOffset()
+	if err := resumeStream(f, offset); err != nil {
+		f.Close()
+		return nil, nil, fmt.Errorf("resuming candidate stream %s: %w", g.config.StreamFile, err)
+	}
+	return f, f.Close, nil
+}
+
+// resumeStream truncates f to offset and positions it there. The file must
+// hold at least offset bytes, or records the checkpoint counts are missing.
+func resumeStream(f *os.File, offset int64) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < offset {
+		return fmt.Errorf("file has %d bytes, checkpoint expects at least %d", info.Size(), offset)
+	}
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	_, err = f.Seek(offset, io.SeekStart)
+	return err
+}
+
+// candidateStream writes candidate records as NDJSON and counts the bytes
+// written so checkpoints can record where the stream stands. It is only
+// used from the collector goroutine.
+type candidateStream struct {
+	w       io.Writer
+	written int64
+	err     error
+}
+
+// newCandidateStream returns a stream writing to w, which already holds
+// offset bytes of records from a resumed run.
+func newCandidateStream(w io.Writer, offset int64) *candidateStream {
+	if w == nil {
+		return nil
+	}
+	return &candidateStream{w: w, written: offset}
+}
+
+// offset returns the number of bytes in the stream so far.
+func (s *candidateStream) offset() int64 {
+	if s == nil {
+		return 0
+	}
+	return s.written
+}
+
+// write appends a record to the stream. After the first failure further
+// records are dropped and the error is kept for Generate to log.
+func (s *candidateStream) write(record CandidateRecord) {
+	if s == nil || s.err != nil {
+		return
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		s.err = fmt.Errorf("encoding candidate record: %w", err)
+		return
+	}
+	n, err := s.w.Write(append(data, '\n'))
+	s.written += int64(n)
+	if err != nil {
+		s.err = fmt.Errorf("writing candidate stream: %w", err)
+	}
+}
@@ -0,0 +1,162 @@
+package constants
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readRecords(t *testing.T, data []byte) []CandidateRecord {
+	t.Helper()
+	var records []CandidateRecord
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var record CandidateRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d: %v", len(records)+1, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+func TestCandidateStream(t *testing.T) {
+	config := testGenerationConfig("primer-stream")
+	g := NewGenerator(config)
+	var buf bytes.Buffer
+	g.SetCandidateStream(&buf)
+
+	result, err := g.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	records := readRecords(t, buf.Bytes())
+	if len(records) != config.NumCandidates {
+		t.Fatalf("streamed %d records, want %d", len(records), config.NumCandidates)
+	}
+
+	seen := make(map[int]bool)
+	accepted := make(map[int]bool)
+	for _, r := range records {
+		c := r.Candidate
+		if seen[c.Index] {
+			t.Errorf("candidate %d streamed twice", c.Index)
+		}
+		seen[c.Index] = true
+		if r.Worker < 0 || r.Worker >= config.ParallelWorkers {
+			t.Errorf("candidate %d has worker %d", c.Index, r.Worker)
+		}
+		if len(c.TestResults.AvalancheTests) == 0 || len(c.TestResults.PrimalityTests) == 0 {
+			t.Errorf("candidate %d is missing test results", c.Index)
+		}
+		if r.Accepted != g.Accepts(c) {
+			t.Errorf("candidate %d Accepted = %v, want %v", c.Index, r.Accepted, !r.Accepted)
+		}
+		if r.Accepted {
+			accepted[c.Index] = true
+		}
+	}
+	if !accepted[result.SelectedP.Index] || !accepted[result.SelectedQ.Index] {
+		t.Errorf("selected candidates %d and %d are not streamed as accepted",
+			result.SelectedP.Index, result.SelectedQ.Index)
+	}
+}
+
+func TestCandidateStreamFile(t *testing.T) {
+	config := testGenerationConfig("primer-stream")
+	config.StreamFile = filepath.Join(t.TempDir(), "candidates.ndjson")
+
+	// A stale file from an earlier run is replaced, not appended to
+	if err := os.WriteFile(config.StreamFile, []byte("stale\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewGenerator(config).Generate(); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	data, err := os.ReadFile(config.StreamFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if records := readRecords(t, data); len(records) != config.NumCandidates {
+		t.Errorf("streamed %d records, want %d", len(records), config.NumCandidates)
+	}
+}
+
+func TestCandidateStreamResume(t *testing.T) {
+	config := testGenerationConfig("primer-stream")
+	dir := t.TempDir()
+	config.BatchSize = 2
+	config.CheckpointFile = filepath.Join(dir, "checkpoint.json")
+	config.StreamFile = filepath.Join(dir, "candidates.ndjson")
+
+	interrupted := NewGenerator(config)
+	interrupted.SetRandomSource(&interruptingSource{
+		source: NewSeededSource(config.Seed),
+		at:     11,
+		stop:   interrupted.Cleanup,
+	})
+	if _, err := interrupted.Generate(); err == nil {
+		t.Fatal("interrupted Generate() succeeded, want cancellation error")
+	}
+	cp, err := LoadCheckpoint(config.CheckpointFile)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+
+	// Records written after the last checkpoint, as after a crash, are
+	// produced again by the resumed run and must not be kept twice
+	data, err := os.ReadFile(config.StreamFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(data)) != cp.StreamOffset {
+		t.Fatalf("stream has %d bytes, checkpoint records %d", len(data), cp.StreamOffset)
+	}
+	last := data[bytes.LastIndexByte(data[:len(data)-1], '\n')+1:]
+	if err := os.WriteFile(config.StreamFile, append(data, last...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resumed := NewGenerator(config)
+	resumed.SetRandomSource(&interruptingSource{source: NewSeededSource(config.Seed)})
+	if err := resumed.Resume(cp); err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if _, err := resumed.Generate(); err != nil {
+		t.Fatalf("resumed Generate() error = %v", err)
+	}
+
+	data, err = os.ReadFile(config.StreamFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := readRecords(t, data)
+	seen := make(map[int]bool)
+	for _, r := range records {
+		if seen[r.Candidate.Index] {
+			t.Errorf("candidate %d streamed twice", r.Candidate.Index)
+		}
+		seen[r.Candidate.Index] = true
+	}
+	if len(seen) != config.NumCandidates {
+		t.Errorf("streamed %d distinct candidates, want %d", len(seen), config.NumCandidates)
+	}
+}
+
+func TestCandidateStreamIgnoredByConfigHash(t *testing.T) {
+	config := testGenerationConfig("primer-stream")
+	streamed := config
+	streamed.StreamFile = "candidates.ndjson"
+	if ConfigHash(config) != ConfigHash(streamed) {
+		t.Error("ConfigHash depends on StreamFile")
+	}
+}
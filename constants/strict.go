@@ -0,0 +1,262 @@
+package constants
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// commentPrefix marks object keys that are documentation, such as
+// "// Processing parameters", and are skipped by strict decoding.
+const commentPrefix = "//"
+
+// DecodeError describes a problem at a position in a configuration file.
+type DecodeError struct {
+	File   string
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// decodeStrict decodes the JSON in data into v, reporting every key that
+// does not correspond to a field of v, other than comment keys, along with
+// its line and column in file. It returns the line on which each leaf field
+// was set, keyed by its dotted path.
+func decodeStrict(data []byte, file string, v interface{}) (map[string]int, error) {
+	sd := &strictDecoder{
+		dec:  json.NewDecoder(bytes.NewReader(data)),
+		data: data,
+		file: file,
+		set:  make(map[string]int),
+	}
+	sd.dec.UseNumber()
+	if err := sd.checkFields(reflect.TypeOf(v).Elem(), ""); err != nil {
+		return nil, positionError(err, data, file)
+	}
+	if len(sd.errs) > 0 {
+		return nil, errors.Join(sd.errs...)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, positionError(err, data, file)
+	}
+	return sd.set, nil
+}
+
+type strictDecoder struct {
+	dec  *json.Decoder
+	data []byte
+	file string
+	errs []error
+	set  map[string]int
+}
+
+// checkFields walks the next JSON value against type t, whose dotted path
+// is path, appending a DecodeError for each unknown object key.
+func (sd *strictDecoder) checkFields(t reflect.Type, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	tok, err := sd.dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '[':
+		elem := t
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			elem = t.Elem()
+		}
+		for sd.dec.More() {
+			if err := sd.checkFields(elem, path); err != nil {
+				return err
+			}
+		}
+	case '{':
+		for sd.dec.More() {
+			tok, err := sd.dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			// The offset is just past the key; report the key's start
+			offset := sd.dec.InputOffset() - int64(len(key)) - 2
+			line, column := lineColumn(sd.data, offset)
+
+			fieldType := reflect.Type(nil)
+			fieldPath := ""
+			if t.Kind() == reflect.Struct && !strings.HasPrefix(key, commentPrefix) {
+				field, found := lookupField(t, key)
+				if !found {
+					sd.errs = append(sd.errs, &DecodeError{
+						File:   sd.file,
+						Line:   line,
+						Column: column,
+						Msg:    unknownFieldMessage(t, key),
+					})
+				} else {
+					fieldType = field.Type
+					fieldPath = joinPath(path, field.Name)
+					if field.Type.Kind() != reflect.Struct {
+						sd.set[fieldPath] = line
+					}
+				}
+			}
+
+			if fieldType == nil {
+				if err := skipValue(sd.dec); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := sd.checkFields(fieldType, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Consume the closing delimiter
+	_, err = sd.dec.Token()
+	return err
+}
+
+// joinPath appends name to a dotted field path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// lookupField finds the exported field of t that encoding/json would decode
+// key into: an exact match, or failing that a case-insensitive one.
+func lookupField(t reflect.Type, key string) (reflect.StructField, bool) {
+	if field, ok := t.FieldByName(key); ok && field.IsExported() {
+		return field, true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.IsExported() && strings.EqualFold(field.Name, key) {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func unknownFieldMessage(t reflect.Type, key string) string {
+	msg := fmt.Sprintf("unknown field %q", key)
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		names = append(names, t.Field(i).Name)
+	}
+	if best := closestName(key, names); best != "" {
+		msg += fmt.Sprintf(" (did you mean %q?)", best)
+	}
+	return msg
+}
+
+// closestName returns the name nearest to key by case-insensitive edit
+// distance, or "" if none is within a third of its length.
+func closestName(key string, names []string) string {
+	best, bestDistance := "", len(key)/3+1
+	for _, name := range names {
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// skipValue consumes the next JSON value from dec.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if delim, ok := tok.(json.Delim); ok {
+			switch delim {
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+			}
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// positionError adds the file position to JSON syntax and type errors.
+func positionError(err error, data []byte, file string) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		line, column := lineColumn(data, syntaxErr.Offset)
+		return &DecodeError{File: file, Line: line, Column: column, Msg: syntaxErr.Error()}
+	case errors.As(err, &typeErr):
+		line, column := lineColumn(data, typeErr.Offset)
+		return &DecodeError{
+			File:   file,
+			Line:   line,
+			Column: column,
+			Msg:    fmt.Sprintf("%s: cannot use %s as %s", typeErr.Field, typeErr.Value, typeErr.Type),
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+		line, column := lineColumn(data, int64(len(data)))
+		return &DecodeError{File: file, Line: line, Column: column, Msg: "unexpected end of JSON input"}
+	}
+	return fmt.Errorf("%s: %w", file, err)
+}
+
+// lineColumn converts a byte offset in data to a 1-based line and column.
+func lineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
@@ -0,0 +1,110 @@
+package constants
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDecodeStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr []string
+	}{
+		{
+			name: "Known fields and comment keys",
+			input: `{
+    "// Processing parameters": "Basic generation settings",
+    "NumCandidates": 50,
+    "Derivation": {
+        "// note": "comments nest",
+        "Offsets": [0, 32]
+    }
+}`,
+		},
+		{
+			name: "Misspelled top-level field",
+			input: `{
+    "NumCandidates": 50,
+    "NumCandiates": 60
+}`,
+			wantErr: []string{`config.json:3:5: unknown field "NumCandiates" (did you mean "NumCandidates"?)`},
+		},
+		{
+			name: "Unknown nested fields",
+			input: `{
+    "StatisticalTests": {"MinPValue": 0.01, "MaxPvalues": 0.99},
+    "ValidationCriteria": {
+        "MinHamingWeight": 12
+    },
+    "Colour": "blue"
+}`,
+			wantErr: []string{
+				`config.json:2:45: unknown field "MaxPvalues" (did you mean "MaxPValue"?)`,
+				`config.json:4:9: unknown field "MinHamingWeight" (did you mean "MinHammingWeight"?)`,
+				`config.json:6:5: unknown field "Colour"`,
+			},
+		},
+		{
+			name: "Wrong type",
+			input: `{
+    "NumCandidates": "many"
+}`,
+			wantErr: []string{`config.json:2:`, `NumCandidates: cannot use string as int`},
+		},
+		{
+			name:    "Syntax error",
+			input:   "{\n    \"NumCandidates\": 50,\n}",
+			wantErr: []string{`config.json:2:25:`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			_, err := decodeStrict([]byte(tt.input), "config.json", &config)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("decodeStrict() error = %v", err)
+				}
+				if config.NumCandidates != 50 || len(config.Derivation.Offsets) != 2 {
+					t.Errorf("decoded NumCandidates=%d Offsets=%v", config.NumCandidates, config.Derivation.Offsets)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("decodeStrict() succeeded")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) {
+				t.Errorf("error %v is not a *DecodeError", err)
+			}
+		})
+	}
+}
+
+func TestLineColumn(t *testing.T) {
+	data := []byte("ab\ncd\n\nef")
+	tests := []struct {
+		offset       int64
+		line, column int
+	}{
+		{0, 1, 1},
+		{1, 1, 2},
+		{3, 2, 1},
+		{7, 4, 1},
+		{9, 4, 3},
+	}
+	for _, tt := range tests {
+		line, column := lineColumn(data, tt.offset)
+		if line != tt.line || column != tt.column {
+			t.Errorf("lineColumn(%d) = %d:%d, want %d:%d", tt.offset, line, column, tt.line, tt.column)
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package constants
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseTOML reads the subset of TOML needed for configuration files:
+// [tables], dotted keys, basic and literal strings, numbers, booleans,
+// arrays of scalars (which may span lines) and # comments. Inline tables,
+// arrays of tables, multi-line strings and dates are not supported.
+func parseTOML(data []byte, file string) ([]assignment, error) {
+	var (
+		assignments []assignment
+		table       []string
+	)
+	fail := func(line, column int, format string, args ...interface{}) error {
+		return &DecodeError{File: file, Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNo := i + 1
+		text := strings.TrimSpace(stripComment(lines[i], '#', false))
+		if text == "" {
+			continue
+		}
+		column := strings.Index(lines[i], text) + 1
+
+		if strings.HasPrefix(text, "[[") {
+			return nil, fail(lineNo, column, "arrays of tables are not supported")
+		}
+		if strings.HasPrefix(text, "[") {
+			if !strings.HasSuffix(text, "]") {
+				return nil, fail(lineNo, column, "unterminated table header")
+			}
+			path, err := parseTOMLKey(text[1 : len(text)-1])
+			if err != nil {
+				return nil, fail(lineNo, column, "%v", err)
+			}
+			table = path
+			continue
+		}
+
+		eq := keyValueSeparator(text)
+		if eq < 0 {
+			return nil, fail(lineNo, column, "expected 'key = value'")
+		}
+		key, err := parseTOMLKey(text[:eq])
+		if err != nil {
+			return nil, fail(lineNo, column, "%v", err)
+		}
+		value := strings.TrimSpace(text[eq+1:])
+
+		// Arrays may continue over several lines
+		for strings.HasPrefix(value, "[") && !bracketsBalanced(value) && i+1 < len(lines) {
+			i++
+			value += " " + strings.TrimSpace(stripComment(lines[i], '#', false))
+		}
+
+		a := assignment{
+			path:   append(append([]string(nil), table...), key...),
+			line:   lineNo,
+			column: column,
+		}
+		if strings.HasPrefix(value, "[") {
+			items, err := parseFlowList(strings.TrimSpace(value), parseTOMLScalar)
+			if err != nil {
+				return nil, fail(lineNo, column, "%v", err)
+			}
+			a.value = rawValue{items: items, list: true}
+		} else {
+			item, err := parseTOMLScalar(value)
+			if err != nil {
+				return nil, fail(lineNo, column, "%v", err)
+			}
+			a.value = rawValue{items: []rawScalar{item}}
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, nil
+}
+
+// parseTOMLKey splits a dotted key whose parts are bare or quoted.
+func parseTOMLKey(s string) ([]string, error) {
+	var parts []string
+	s = strings.TrimSpace(s)
+	for s != "" {
+		var part string
+		if s[0] == '"' || s[0] == '\'' {
+			end := closingQuote(s, 0)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted key")
+			}
+			scalar, err := parseTOMLScalar(s[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			part, s = scalar.text, strings.TrimSpace(s[end+1:])
+		} else {
+			end := strings.IndexByte(s, '.')
+			if end < 0 {
+				end = len(s)
+			}
+			part, s = strings.TrimSpace(s[:end]), strings.TrimSpace(s[end:])
+			if part == "" {
+				return nil, fmt.Errorf("empty key")
+			}
+		}
+		parts = append(parts, part)
+
+		if s != "" {
+			if s[0] != '.' {
+				return nil, fmt.Errorf("invalid key")
+			}
+			s = strings.TrimSpace(s[1:])
+			if s == "" {
+				return nil, fmt.Errorf("empty key")
+			}
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return parts, nil
+}
+
+// parseTOMLScalar parses a basic or literal string, number or boolean.
+func parseTOMLScalar(s string) (rawScalar, error) {
+	if s == "" {
+		return rawScalar{}, fmt.Errorf("missing value")
+	}
+	switch s[0] {
+	case '"':
+		if strings.HasPrefix(s, `"""`) {
+			return rawScalar{}, fmt.Errorf("multi-line strings are not supported")
+		}
+		text, err := strconv.Unquote(s)
+		if err != nil {
+			return rawScalar{}, fmt.Errorf("invalid string %s", s)
+		}
+		return rawScalar{text: text, quoted: true}, nil
+	case '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' || strings.HasPrefix(s, "'''") {
+			return rawScalar{}, fmt.Errorf("invalid string %s", s)
+		}
+		return rawScalar{text: s[1 : len(s)-1], quoted: true}, nil
+	case '{':
+		return rawScalar{}, fmt.Errorf("inline tables are not supported")
+	}
+	return rawScalar{text: s}, nil
+}
+
+// keyValueSeparator returns the index of the '=' separating key and value,
+// skipping quoted keys, or -1.
+func keyValueSeparator(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			if end := closingQuote(s, i); end >= 0 {
+				i = end
+			}
+		case '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// bracketsBalanced reports whether every '[' outside strings is closed.
+func bracketsBalanced(s string) bool {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			if end := closingQuote(s, i); end >= 0 {
+				i = end
+			}
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+	}
+	return depth <= 0
+}
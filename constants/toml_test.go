@@ -0,0 +1,84 @@
+package constants
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadTOML(t *testing.T) {
+	input := `# Overrides for CI
+NumCandidates = 2_000
+ParallelWorkers = 4
+Seed = "ci-run" # trailing comment
+ResultsFile = 'C:\results\rc6.json'
+
+[Derivation]
+Sources = ["e", "pi"]
+Offsets = [
+    0,
+    32,
+]
+
+[StatisticalTests]
+MaxRunsZScore = 2.5
+
+[ValidationCriteria]
+MinHammingWeight = 12
+"MaxHammingWeight" = 20
+
+[ScoringWeights]
+Avalanche = 3.0
+`
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	want := DefaultConfig()
+	want.NumCandidates = 2000
+	want.ParallelWorkers = 4
+	want.Seed = "ci-run"
+	want.ResultsFile = `C:\results\rc6.json`
+	want.Derivation.Sources = []string{"e", "pi"}
+	want.Derivation.Offsets = []int{0, 32}
+	want.StatisticalTests.MaxRunsZScore = 2.5
+	want.ValidationCriteria.MinHammingWeight = 12
+	want.ValidationCriteria.MaxHammingWeight = 20
+	want.ScoringWeights.Avalanche = 3.0
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("LoadConfig() = %+v\nwant %+v", config, want)
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"Unknown table", "[Statistics]\nMinPValue = 0.1\n", `config.toml:2:1: unknown field "Statistics"`},
+		{"Dotted unknown key", "StatisticalTests.MinPvalu = 0.1\n", `config.toml:1:1: unknown field "MinPvalu" (did you mean "MinPValue"?)`},
+		{"String for bool", "RequirePrime = \"yes\"\n", `config.toml:1:1: expected bool`},
+		{"Inline table", "Derivation = { Rules = [\"odd\"] }\n", `config.toml:1:1: inline tables are not supported`},
+		{"Array of tables", "[[Derivation]]\n", `config.toml:1:1: arrays of tables are not supported`},
+		{"Missing equals", "[Derivation]\n  Rules\n", `config.toml:2:3: expected 'key = value'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			_, err := decodeAssignments(parseTOML, []byte(tt.input), "config.toml", &config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
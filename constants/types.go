@@ -2,9 +2,13 @@ package constants
 
 import (
     "time"
+
+    "primer/word"
 )
 
 type Config struct {
+    WordSize             int
+    Seed                 string
     NumCandidates        int
     AvalancheTestCases   int
     MinPrimeAttempts     int
@@ -13,62 +17,458 @@ type Config struct {
     MinBitDistribution   float64
     MaxBitDistribution   float64
     MinAvalancheScore    float64
+    MaxSACDeviation      float64
+    MinSACPValue         float64
+    MaxBICCorrelation    float64
     ResultsFile          string
     DetailedLogging      bool
     StatisticalAnalysis  bool
+    Transform            string
+    CipherRounds         int
+    ReducedRounds        int
+    KeyBytes             int
+    GenerationMode       string
+    RequirePrime         bool
+    PrimeClass           string
+    Derivation           DerivationConfig
+    StatisticalTests     StatisticalTestsConfig
+    ValidationCriteria   ValidationCriteria
+    RandomnessTests      RandomnessTestsConfig
+    ScoringWeights       ScoringWeights
+    TimeoutSeconds       int
+    BatchSize            int
+    CheckpointFile       string
+    CheckpointSeconds    int
+    StreamFile           string
+    ConstantNames        []string
+    Alternates           int
+    SelectionStrategy    string
+    ParetoTieBreakers    []string
+    ParetoTolerance      float64
+    NIST                 NISTConfig
+    Cryptanalysis        CryptanalysisConfig
+    KeySchedule          KeyScheduleConfig
+    PairAnalysis         PairAnalysisConfig
+}
+
+// StatisticalTestsConfig holds the thresholds of the statistical tests.
+// MinPValue is the significance level: a test passes when its p-value is at
+// least MinPValue. MaxPValue, MaxBitFrequencyDeviation, MinRunsZScore,
+// MaxRunsZScore, MaxSerialCorrelation and MinLinearComplexity are deprecated;
+// they are still validated so that existing configs load, but no longer
+// decide whether a test passes, and LoadConfigLayers warns when one is
+// changed from its default.
+type StatisticalTestsConfig struct {
+    MinPValue                 float64
+    MaxPValue                 float64
+    MinEntropyScore           float64
+    MaxEntropyScore           float64
+    MaxBitFrequencyDeviation  float64
+    MinRunsZScore             float64
+    MaxRunsZScore             float64
+    MaxSerialCorrelation      float64
+    MinLinearComplexity       int
+}
+
+// ValidationCriteria bounds accepted candidates and selected pairs. A zero
+// field scales with the word size: Hamming weight within [3w/8, 5w/8], P and
+// Q at least 3w/8 bits apart and at least w/8 bits from any shift of each
+// other. TestAggregation names how the statistical tests are combined into
+// one decision; MaxFailedTestFraction only applies to the fraction method.
+type ValidationCriteria struct {
+    MinHammingWeight       int
+    MaxHammingWeight       int
+    MinHammingDistance     int
+    MinDifferentBits       int
+    MaxFailedTestFraction  float64
+    TestAggregation        string
+}
+
+type RandomnessTestsConfig struct {
+    EnableExtendedTests     bool
+    RunsTestWeight          float64
+    FrequencyTestWeight     float64
+    SerialTestWeight        float64
+    AutocorrelationWeight   float64
+    LinearComplexityWeight  float64
+    EnabledTests            []string
+    DisabledTests           []string
+}
+
+// NISTConfig runs the NIST SP 800-22 battery on keystreams of the
+// configured transform keyed with each selected constant. A statistic fails
+// when the Kolmogorov-Smirnov p-value of the uniformity of its p-values is
+// below MinUniformityKS; zero turns that check off.
+type NISTConfig struct {
+    Enabled          bool
+    Sequences        int
+    StreamBits       int
+    Alpha            float64
+    MinUniformityKS  float64
+}
+
+// CryptanalysisConfig measures the differential and linear properties of
+// the configured transform keyed with each candidate, on 8-bit slices. The
+// simple transform is sliced at Bases fixed values of the other input bits;
+// one round of RC6 is sampled over 8·Bases random blocks. A zero limit is
+// off.
+type CryptanalysisConfig struct {
+    Enabled                     bool
+    Bases                       int
+    MaxDifferentialProbability  float64
+    MaxLinearBias               float64
+}
+
+// KeyScheduleConfig analyses the RC6 key schedule keyed with each selected
+// constant: Keys random keys of KeyBytes bytes for the key avalanche, bit
+// balance and related-key tests, and every key of EquivalentKeyBits bits
+// for the equivalent-key search.
+type KeyScheduleConfig struct {
+    Enabled            bool
+    Keys               int
+    EquivalentKeyBits  int
+}
+
+// PairAnalysisConfig checks every pair of selected constants for
+// relations: bit correlation above MaxCorrelation, outputs of the simple
+// transform under the two constants that differ in a fraction of bits more
+// than MaxOutputBias from one half, and the structural relations. A zero
+// limit is off.
+type PairAnalysisConfig struct {
+    Enabled         bool
+    MaxCorrelation  float64
+    MaxOutputBias   float64
+}
+
+type ScoringWeights struct {
+    BitDistribution  float64
+    Avalanche        float64
+    Entropy          float64
+}
+
+type DerivationConfig struct {
+    Sources  []string
+    Rules    []string
+    Offsets  []int
 }
 
 type ConstantCandidate struct {
-    Value           uint32
-    BitDistribution float64
-    AvalancheScore  float64
-    HammingWeight   int
-    EntropyScore    float64
-    TestDuration    time.Duration
-    GenerationTime  time.Time
-    TestResults     TestResults
+    Index                       int
+    Value                       word.Word
+    WordSize                    int
+    BitDistribution             float64
+    AvalancheScore              float64
+    HammingWeight               int
+    EntropyScore                float64
+    // MaxDifferentialProbability and MaxLinearBias are the worst
+    // differential and linear approximation of the configured transform
+    // keyed with the candidate, zero unless Cryptanalysis is enabled.
+    MaxDifferentialProbability  float64
+    MaxLinearBias               float64
+    TestDuration                time.Duration
+    GenerationTime              time.Time
+    TestResults                 TestResults
+    Derivation                  *Derivation
+}
+
+type Derivation struct {
+    Source    string
+    Rule      string
+    Offset    int
+    Formula   string
 }
 
 type TestResults struct {
     PrimalityTests     []PrimalityTest
     AvalancheTests     []AvalancheTest
     StatisticalTests   []StatisticalTest
+    Aggregate          *TestAggregate
     WeakKeyTests       []WeakKeyTest
+    KeyScheduleTests   []KeyScheduleTest
+    NIST               *NISTReport
 }
 
 type PrimalityTest struct {
     Passed    bool
     Duration  time.Duration
     Method    string
+    Class     string
     Details   string
 }
 
+// AvalancheTest is the avalanche of one transform keyed with a candidate.
+// SAC and BIC break Score down by input and output bit position.
 type AvalancheTest struct {
+    Transform string
+    Rounds    int
     Score     float64
     Changes   int
     Total     int
+    SAC       *SACMatrix
+    BIC       *BICMatrix
     Duration  time.Duration
 }
 
+// SACMatrix is the strict avalanche criterion over Samples output
+// differences: Deviations[i][j] is how far the probability that output bit
+// j flips when input bit i is flipped lies from the ideal 0.5. ChiSquare
+// tests every cell against 0.5, with w² degrees of freedom.
+type SACMatrix struct {
+    Deviations     [][]float64
+    Samples        int
+    MeanDeviation  float64
+    MaxDeviation   float64
+    WorstInput     int
+    WorstOutput    int
+    ChiSquare      float64
+    PValue         float64
+}
+
+// BICMatrix is the bit independence criterion: Correlations[j][k] is the
+// largest absolute correlation, over all input bits, between the flips of
+// output bits j and k. Independent bits have correlations near zero.
+type BICMatrix struct {
+    Correlations    [][]float64
+    MaxCorrelation  float64
+    WorstInput      int
+    WorstOutputs    [2]int
+}
+
+// StatisticalTest is the outcome of one statistical test. Score ranks
+// candidates, higher being more random-looking; PValue is the probability
+// of a result at least as extreme from a random word, and is zero for
+// registered tests that do not compute one.
 type StatisticalTest struct {
     Name      string
     Score     float64
+    PValue    float64
+    Passed    bool
+    Details   string
+}
+
+// TestAggregate is the decision over all statistical tests of a candidate
+// by the aggregation Method at significance level Alpha. PValue is the
+// combined or multiplicity-adjusted p-value of the suite, zero for the
+// fraction method. Rejected lists the tests that failed or, for the
+// correction procedures, were rejected after correction.
+type TestAggregate struct {
+    Method    string
+    Alpha     float64
+    PValue    float64
+    Rejected  []string
     Passed    bool
     Details   string
 }
 
+// NISTReport is the outcome of the NIST SP 800-22 battery over Sequences
+// keystreams of StreamBits bits each.
+type NISTReport struct {
+    Transform   string
+    StreamBits  int
+    Sequences   int
+    Alpha       float64
+    Tests       []NISTTest
+    Passed      bool
+    Duration    time.Duration
+}
+
+// NISTTest is one statistic of the battery over every sequence: its
+// p-values, the proportion of sequences that passed against the lowest
+// acceptable proportion, and the uniformity of the p-values. Uniformity
+// is the ten-bin chi-square of SP 800-22 and only counts towards Passed
+// from nist.MinUniformitySequences sequences. UniformityKS is the
+// Kolmogorov-Smirnov p-value of the same hypothesis on the unbinned
+// p-values, which counts towards Passed at any number of sequences when
+// NISTConfig.MinUniformityKS is set.
+type NISTTest struct {
+    Name           string
+    PValues        []float64
+    Proportion     float64
+    MinProportion  float64
+    Uniformity     float64
+    UniformityKS   float64
+    Passed         bool
+    Details        string
+}
+
 type WeakKeyTest struct {
     Passed    bool
     Pattern   string
     Details   string
 }
 
+// KeyScheduleTest is the outcome of one test of the RC6 key schedule with
+// the candidate as P and as Q. PValue is zero for the equivalent-key
+// search, which passes only when no two keys share a schedule.
+type KeyScheduleTest struct {
+    Name      string
+    Score     float64
+    PValue    float64
+    Passed    bool
+    Details   string
+}
+
+type ExplainOptions struct {
+    MaxOffset  int
+    MaxDelta   int
+}
+
+type ProvenanceMatch struct {
+    Source      string
+    Rule        string
+    Offset      int
+    Transform   string
+    Formula     string
+    Complexity  float64
+}
+
+// Explanation is the outcome of Explain. SearchSpace is the number of
+// derivations tried, each naming one value. ChanceMatch is the chance that
+// a random value has a derivation no longer than Best.
+type Explanation struct {
+    Value        word.Word
+    WordSize     int
+    Best         *ProvenanceMatch
+    Matches      []ProvenanceMatch
+    Rigidity     float64
+    ChanceMatch  float64
+    SearchSpace  int
+    Analysis     ConstantCandidate
+}
+
 type GenerationResult struct {
+    // SelectedP and SelectedQ are the first two selected constants, kept
+    // for readers of results that predate Selected.
     SelectedP        ConstantCandidate
     SelectedQ        ConstantCandidate
+    Selected         []NamedConstant
+    Alternates       []RankedCandidate
+    ParetoFront      []ParetoPoint
     TotalCandidates  int
     Duration         time.Duration
     StartTime        time.Time
     EndTime          time.Time
     Config           Config
-}
\ No newline at end of file
+    Funnel           RejectionFunnel
+    PairAnalysis     []PairAnalysis
+}
+
+// PairAnalysis relates two selected constants, First and Second.
+// Correlation is the correlation of their bits, OutputDifference the
+// fraction of output bits that differ when the same inputs go through the
+// simple transform under each, and JointAvalanche the avalanche of the two
+// transforms in sequence. Difference is First - Second mod 2^w and Ratio is
+// First * Second^-1 mod 2^w, zero when Second is even. Relations lists the
+// relations found; selected constants have none.
+type PairAnalysis struct {
+    First             string
+    Second            string
+    Correlation       float64
+    OutputDifference  float64
+    JointAvalanche    float64
+    Difference        word.Word
+    Ratio             word.Word
+    Relations         []string
+}
+
+// NamedConstant is a selected constant and the name it was selected for.
+type NamedConstant struct {
+    Name       string
+    Candidate  ConstantCandidate
+}
+
+// RankedCandidate is an accepted candidate with its selection rank and
+// score, starting from rank 1. Front is its Pareto front under the pareto
+// strategy and zero otherwise.
+type RankedCandidate struct {
+    Rank       int
+    Score      float64
+    Front      int
+    Candidate  ConstantCandidate
+}
+
+// Selection is what a Selector chose: one constant per name, in name order,
+// ranked runners-up, and the Pareto front when the selector computes one.
+type Selection struct {
+    Constants    []ConstantCandidate
+    Alternates   []RankedCandidate
+    ParetoFront  []ParetoPoint
+}
+
+// ParetoPoint is a candidate on the Pareto front with the metrics it was
+// compared on. Selected holds the name it was selected as, if any.
+type ParetoPoint struct {
+    Index     int
+    Value     word.Word
+    Score     float64
+    Metrics   map[string]float64
+    Selected  string
+}
+
+// RejectionFunnel counts how many candidates each validation criterion
+// rejected, in the order the criteria are applied.
+type RejectionFunnel struct {
+    Generated  int
+    Accepted   int
+    Stages     []FunnelStage
+    Workers    []WorkerFunnel
+}
+
+// FunnelStage is one criterion of the funnel: how many candidates it
+// rejected and how many remained after it.
+type FunnelStage struct {
+    Reason     string
+    Rejected   int
+    Remaining  int
+}
+
+// WorkerFunnel holds the rejection counts of a single worker.
+type WorkerFunnel struct {
+    Worker     int
+    Generated  int
+    Accepted   int
+    Rejected   map[string]int
+}
+
+// CandidateRecord is one line of the NDJSON candidate stream.
+type CandidateRecord struct {
+    Worker     int
+    Accepted   bool
+    Rejection  string
+    Candidate  ConstantCandidate
+}
+
+type VerificationCheck struct {
+    Constant  string
+    Name      string
+    Passed    bool
+    Details   string
+}
+
+type Verification struct {
+    Passed  bool
+    Checks  []VerificationCheck
+}
+
+type Checkpoint struct {
+    Version       int
+    ConfigHash    string
+    Random        RandomState
+    Workers       []WorkerProgress
+    Funnel        []WorkerFunnel
+    Candidates    []ConstantCandidate
+    // StreamOffset is the length in bytes of the candidate stream when
+    // the checkpoint was saved; a resumed run truncates StreamFile to it.
+    StreamOffset  int64
+    SavedAt       time.Time
+}
+
+type WorkerProgress struct {
+    Worker     int
+    NextIndex  int
+}
+
+type RandomState struct {
+    Source      string
+    SeedDigest  string
+}
@@ -0,0 +1,227 @@
+package constants
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+// verifyTolerance is the largest difference allowed between a recorded and
+// a recomputed score.
+const verifyTolerance = 1e-9
+
+// LoadResult reads a GenerationResult saved as JSON.
+func LoadResult(path string) (*GenerationResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading results: %w", err)
+	}
+
+	var result GenerationResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("parsing results %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// Verify re-checks a saved result: primality, the candidate validation
+// rules and the statistical tests are recomputed from the selected values
+// rather than trusted from the file. The generator should be created from
+// result.Config. When the result was generated from a seed, each selected
+// candidate is also regenerated from its index and must reproduce exactly.
+func (g *Generator) Verify(result *GenerationResult) Verification {
+	v := Verification{Passed: true}
+	add := func(constant, name string, passed bool, format string, args ...interface{}) {
+		v.Checks = append(v.Checks, VerificationCheck{
+			Constant: constant,
+			Name:     name,
+			Passed:   passed,
+			Details:  fmt.Sprintf(format, args...),
+		})
+		if !passed {
+			v.Passed = false
+		}
+	}
+
+	named := result.Constants()
+	if len(result.Selected) >= 2 {
+		consistent := result.SelectedP.Value == named[0].Candidate.Value && result.SelectedQ.Value == named[1].Candidate.Value
+		add("P,Q", "Consistency", consistent, "SelectedP and SelectedQ are %s and %s (0x%X, 0x%X)",
+			named[0].Name, named[1].Name, result.SelectedP.Value, result.SelectedQ.Value)
+	}
+	selected := make([]ConstantCandidate, len(named))
+	names := make([]string, len(named))
+	for i, c := range named {
+		g.verifyConstant(c.Name, c.Candidate, add)
+		selected[i], names[i] = c.Candidate, c.Name
+	}
+
+	err := g.validateSelectedConstants(selected)
+	add(strings.Join(names, ","), "Selection", err == nil, "%s", errorDetails(err, "selected constants meet the selection rules"))
+	recorded := make(map[[2]string]PairAnalysis, len(result.PairAnalysis))
+	for _, p := range result.PairAnalysis {
+		recorded[[2]string{p.First, p.Second}] = p
+	}
+	for i, a := range named {
+		for _, b := range named[i+1:] {
+			add(a.Name+","+b.Name, "Distinct", g.areSufficientlyDifferent(a.Candidate, b.Candidate),
+				"Hamming distance %d", a.Candidate.Value.Xor(b.Candidate.Value).OnesCount())
+			if g.config.PairAnalysis.Enabled {
+				g.verifyPairAnalysis(a, b, recorded, add)
+			}
+		}
+	}
+
+	return v
+}
+
+// verifyPairAnalysis recomputes the analysis of a pair of constants, which
+// must find no relation and match the recorded one. Results saved before
+// the pair analysis existed have none to compare.
+func (g *Generator) verifyPairAnalysis(a, b NamedConstant, recorded map[[2]string]PairAnalysis, add verifyFunc) {
+	analysis := g.analyzePair(a.Candidate.Value, b.Candidate.Value)
+	matches := true
+	if r, ok := recorded[[2]string{a.Name, b.Name}]; ok {
+		matches = analysis.Difference == r.Difference && analysis.Ratio == r.Ratio &&
+			math.Abs(analysis.Correlation-r.Correlation) <= verifyTolerance &&
+			math.Abs(analysis.OutputDifference-r.OutputDifference) <= verifyTolerance &&
+			math.Abs(analysis.JointAvalanche-r.JointAvalanche) <= verifyTolerance
+	}
+	relations := "none"
+	if len(analysis.Relations) > 0 {
+		relations = strings.Join(analysis.Relations, ", ")
+	}
+	add(a.Name+","+b.Name, "Pair Analysis", len(analysis.Relations) == 0 && matches,
+		"relations: %s; correlation %.4f, output difference %.4f, joint avalanche %.4f (matches recorded: %v)",
+		relations, analysis.Correlation, analysis.OutputDifference, analysis.JointAvalanche, matches)
+}
+
+type verifyFunc func(constant, name string, passed bool, format string, args ...interface{})
+
+func (g *Generator) verifyConstant(name string, c ConstantCandidate, add verifyFunc) {
+	w := g.wordSize()
+	add(name, "Word Size", c.WordSize == int(w) && c.Value.BitLen() <= int(w),
+		"recorded %d bits, configured %d bits, value uses %d bits", c.WordSize, w, c.Value.BitLen())
+
+	// Primality must match both the configuration and what the file claims
+	prime := g.isPrime(c.Value)
+	claimed := len(c.TestResults.PrimalityTests) > 0
+	var classTest *PrimalityTest
+	for i, test := range c.TestResults.PrimalityTests {
+		if test.Class != "" {
+			classTest = &c.TestResults.PrimalityTests[i]
+			continue
+		}
+		claimed = claimed && test.Passed
+	}
+	primeOK := (!g.config.RequirePrime || prime) &&
+		(len(c.TestResults.PrimalityTests) == 0 || claimed == prime)
+	add(name, "Primality", primeOK, "prime: %v, recorded: %v, required: %v", prime, claimed, g.config.RequirePrime)
+
+	// The prime class is re-checked when configured or recorded
+	if class := g.primeClass(); class != PrimeAny || classTest != nil {
+		if class == PrimeAny {
+			class = classTest.Class
+		}
+		passed, details := g.primeClassTest(c.Value, class)
+		matches := classTest == nil || (classTest.Class == class && classTest.Passed == passed)
+		add(name, "Prime Class", (passed || g.primeClass() == PrimeAny) && matches,
+			"%s: %s (matches recorded: %v)", class, details, matches)
+	}
+
+	// Scores that depend only on the value must match exactly
+	analysis := g.evaluateCandidate(c.Value, g.stream(analysisStream))
+	scoresOK := analysis.HammingWeight == c.HammingWeight &&
+		math.Abs(analysis.BitDistribution-c.BitDistribution) <= verifyTolerance &&
+		math.Abs(analysis.EntropyScore-c.EntropyScore) <= verifyTolerance
+	add(name, "Recorded Scores", scoresOK,
+		"bit distribution %.4f, Hamming weight %d, entropy %.4f (recorded %.4f, %d, %.4f)",
+		analysis.BitDistribution, analysis.HammingWeight, analysis.EntropyScore,
+		c.BitDistribution, c.HammingWeight, c.EntropyScore)
+	if g.config.Cryptanalysis.Enabled {
+		add(name, "Cryptanalysis",
+			math.Abs(analysis.MaxDifferentialProbability-c.MaxDifferentialProbability) <= verifyTolerance &&
+				math.Abs(analysis.MaxLinearBias-c.MaxLinearBias) <= verifyTolerance,
+			"max differential probability %.4f, max linear bias %.4f (recorded %.4f, %.4f)",
+			analysis.MaxDifferentialProbability, analysis.MaxLinearBias,
+			c.MaxDifferentialProbability, c.MaxLinearBias)
+	}
+
+	g.verifyStatisticalTests(name, c, add)
+
+	// With a seed the candidate can be regenerated, so the avalanche scores
+	// must be reproduced; otherwise they are re-measured
+	if g.config.Seed != "" {
+		regenerated, err := g.generateCandidate(c.Index)
+		switch {
+		case err != nil:
+			add(name, "Reproduction", false, "regenerating candidate %d: %v", c.Index, err)
+		case regenerated.Value != c.Value:
+			add(name, "Reproduction", false, "candidate %d regenerates as 0x%X", c.Index, regenerated.Value)
+		default:
+			add(name, "Reproduction", math.Abs(regenerated.AvalancheScore-c.AvalancheScore) <= verifyTolerance,
+				"candidate %d avalanche %.4f (recorded %.4f)", c.Index, regenerated.AvalancheScore, c.AvalancheScore)
+			analysis = regenerated
+		}
+	}
+	add(name, "Avalanche", analysis.AvalancheScore >= g.config.MinAvalancheScore,
+		"score %.4f, minimum %.4f", analysis.AvalancheScore, g.config.MinAvalancheScore)
+
+	reason := g.validator.Validate(analysis)
+	details := "accepted by the candidate rules"
+	if reason != "" {
+		details = "rejected by the candidate rules: " + reason
+	}
+	add(name, "Validation", reason == "", "%s", details)
+}
+
+// verifyStatisticalTests recomputes the statistical tests and compares them
+// with the recorded ones by name.
+func (g *Generator) verifyStatisticalTests(name string, c ConstantCandidate, add verifyFunc) {
+	tests := g.runAllStatisticalTests(c.Value)
+	recorded := make(map[string]StatisticalTest, len(c.TestResults.StatisticalTests))
+	for _, test := range c.TestResults.StatisticalTests {
+		recorded[test.Name] = test
+	}
+
+	for _, test := range tests {
+		saved, ok := recorded[test.Name]
+		if !ok {
+			add(name, test.Name, test.Passed, "score %.4f (not recorded)", test.Score)
+			continue
+		}
+		add(name, test.Name,
+			saved.Passed == test.Passed && math.Abs(saved.Score-test.Score) <= verifyTolerance &&
+				math.Abs(saved.PValue-test.PValue) <= verifyTolerance,
+			"score %.4f, p-value %.4f, passed %v (recorded %.4f, %.4f, %v)",
+			test.Score, test.PValue, test.Passed, saved.Score, saved.PValue, saved.Passed)
+	}
+
+	aggregate := g.aggregateSignificance(tests)
+	passed := aggregate.Passed
+	if saved := c.TestResults.Aggregate; saved != nil {
+		passed = passed && saved.Method == aggregate.Method && saved.Passed == aggregate.Passed
+	}
+	add(name, "Statistical Threshold", passed,
+		"%d of %d tests passed, %s: %s", countPassed(tests), len(tests), aggregate.Method, aggregate.Details)
+}
+
+func countPassed(tests []StatisticalTest) int {
+	passed := 0
+	for _, test := range tests {
+		if test.Passed {
+			passed++
+		}
+	}
+	return passed
+}
+
+// errorDetails returns err's message, or ok when err is nil.
+func errorDetails(err error, ok string) string {
+	if err != nil {
+		return err.Error()
+	}
+	return ok
+}
@@ -0,0 +1,133 @@
+package constants
+
+import (
+	"path/filepath"
+	"testing"
+
+	"primer/word"
+)
+
+// verifiedResult builds a result from the first two candidates of a seeded
+// run, the way processResults records them.
+func verifiedResult(t *testing.T) (*Generator, *GenerationResult) {
+	t.Helper()
+	config := DefaultConfig()
+	config.Seed = "verify"
+	config.AvalancheTestCases = 50
+	config.DetailedLogging = false
+	g := NewGenerator(config)
+
+	var selected []ConstantCandidate
+	for i := 0; i < 2; i++ {
+		c, err := g.generateCandidate(i)
+		if err != nil {
+			t.Fatalf("generateCandidate(%d): %v", i, err)
+		}
+		c.TestResults.StatisticalTests = g.runAllStatisticalTests(c.Value)
+		selected = append(selected, c)
+	}
+	return g, &GenerationResult{
+		SelectedP: selected[0],
+		SelectedQ: selected[1],
+		Config:    config,
+	}
+}
+
+func findCheck(t *testing.T, v Verification, constant, name string) VerificationCheck {
+	t.Helper()
+	for _, check := range v.Checks {
+		if check.Constant == constant && check.Name == name {
+			return check
+		}
+	}
+	t.Fatalf("no %s check for %s", name, constant)
+	return VerificationCheck{}
+}
+
+func TestVerify(t *testing.T) {
+	g, result := verifiedResult(t)
+	v := g.Verify(result)
+
+	for _, constant := range []string{"P", "Q"} {
+		for _, name := range []string{"Word Size", "Primality", "Recorded Scores", "Reproduction", "Avalanche", "Bit Frequency Test", "Statistical Threshold"} {
+			if check := findCheck(t, v, constant, name); !check.Passed {
+				t.Errorf("%s %s failed: %s", constant, name, check.Details)
+			}
+		}
+	}
+	if check := findCheck(t, v, "P,Q", "Distinct"); !check.Passed {
+		t.Errorf("Distinct failed: %s", check.Details)
+	}
+}
+
+func TestVerifyDetectsTampering(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(*GenerationResult)
+		check  string
+	}{
+		{
+			name: "Composite value",
+			tamper: func(r *GenerationResult) {
+				r.SelectedP.Value = r.SelectedP.Value.Add(word.FromUint64(1), 32)
+			},
+			check: "Primality",
+		},
+		{
+			name: "Edited statistical score",
+			tamper: func(r *GenerationResult) {
+				r.SelectedP.TestResults.StatisticalTests[1].Score += 0.1
+			},
+			check: "Runs Test",
+		},
+		{
+			name: "Edited avalanche score",
+			tamper: func(r *GenerationResult) {
+				r.SelectedP.AvalancheScore += 0.01
+			},
+			check: "Reproduction",
+		},
+		{
+			name: "Edited Hamming weight",
+			tamper: func(r *GenerationResult) {
+				r.SelectedP.HammingWeight++
+			},
+			check: "Recorded Scores",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, result := verifiedResult(t)
+			tt.tamper(result)
+			v := g.Verify(result)
+			if v.Passed {
+				t.Error("tampered result verified")
+			}
+			if check := findCheck(t, v, "P", tt.check); check.Passed {
+				t.Errorf("%s check passed: %s", tt.check, check.Details)
+			}
+		})
+	}
+}
+
+func TestLoadResult(t *testing.T) {
+	g, result := verifiedResult(t)
+	g.config.ResultsFile = filepath.Join(t.TempDir(), "results.json")
+	if err := g.saveResults(result); err != nil {
+		t.Fatalf("saveResults: %v", err)
+	}
+
+	loaded, err := LoadResult(g.config.ResultsFile)
+	if err != nil {
+		t.Fatalf("LoadResult: %v", err)
+	}
+	if loaded.SelectedP.Value != result.SelectedP.Value || loaded.SelectedQ.Value != result.SelectedQ.Value {
+		t.Errorf("loaded constants 0x%X, 0x%X, want 0x%X, 0x%X",
+			loaded.SelectedP.Value, loaded.SelectedQ.Value, result.SelectedP.Value, result.SelectedQ.Value)
+	}
+
+	if _, err := LoadResult(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("LoadResult succeeded on a missing file")
+	}
+}
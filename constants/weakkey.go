@@ -0,0 +1,431 @@
+package constants
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"primer/expansion"
+	"primer/rc6"
+	"primer/word"
+)
+
+// Names of the weak-key tests, as recorded in WeakKeyTest.Pattern.
+const (
+	weakLowHammingWeight = "Low Hamming Weight"
+	weakSimpleBitPattern = "Simple Bit Pattern"
+	weakMultiplicative   = "Low Multiplicative Order"
+	weakFixedPoints      = "Fixed Points"
+	weakShortCycles      = "Short Cycles"
+	weakRotational       = "Rotational Symmetry"
+	weakPalindrome       = "Palindrome"
+	weakLongRuns         = "Long Runs"
+	weakNibbleRepetition = "Nibble Repetition"
+	weakByteRepetition   = "Byte Repetition"
+	weakNearPowerOfTwo   = "Near Power of Two"
+	weakMersenneFermat   = "Mersenne/Fermat Form"
+	weakSmallMultiple    = "Small Multiple"
+)
+
+// weakKeyAlpha bounds the chance that a random word fails one of the
+// weak-key tests that count bits: long runs and repetition.
+const weakKeyAlpha = 1e-3
+
+// runWeakKeyTests checks value for structure that makes a poor constant.
+// Every test is named by its Pattern, and a failed test rejects the
+// candidate as "weak key: <pattern>".
+func (g *Generator) runWeakKeyTests(value word.Word) []WeakKeyTest {
+	var tests []WeakKeyTest
+	checks := []struct {
+		pattern string
+		check   func(word.Word) (bool, string)
+	}{
+		{weakLowHammingWeight, g.lowHammingWeightTest},
+		{weakSimpleBitPattern, g.simpleBitPatternTest},
+		{weakMultiplicative, g.multiplicativeOrderTest},
+		{weakFixedPoints, g.fixedPointTest},
+		{weakShortCycles, g.shortCycleTest},
+		{weakRotational, g.rotationalSymmetryTest},
+		{weakPalindrome, g.palindromeTest},
+		{weakLongRuns, g.longRunTest},
+		{weakNibbleRepetition, func(x word.Word) (bool, string) { return g.repetitionTest(x, 4) }},
+		{weakByteRepetition, func(x word.Word) (bool, string) { return g.repetitionTest(x, 8) }},
+		{weakNearPowerOfTwo, g.nearPowerOfTwoTest},
+		{weakMersenneFermat, g.mersenneFermatTest},
+		{weakSmallMultiple, g.smallMultipleTest},
+	}
+	for _, c := range checks {
+		passed, details := c.check(value)
+		tests = append(tests, WeakKeyTest{Pattern: c.pattern, Passed: passed, Details: details})
+	}
+	return tests
+}
+
+// lowHammingWeightTest fails on a Hamming weight that a random word has or
+// undercuts with probability below weakKeyAlpha. The bound is independent
+// of ValidationCriteria: with the default Hamming bounds the Hamming-weight
+// criterion rejects these values first, but analyze and relaxed bounds
+// still rely on this test.
+func (g *Generator) lowHammingWeightTest(value word.Word) (bool, string) {
+	w := int(g.wordSize())
+	weight := value.OnesCount()
+	// P(X <= weight) = P(X >= w - weight) for fair bits
+	p := binomialTail(w, w-weight, 0.5)
+	return p >= weakKeyAlpha, fmt.Sprintf("Hamming weight %d of %d (probability %.2g)", weight, w, p)
+}
+
+// simpleBitPatternTest fails if value repeats one of a few simple byte
+// patterns over the whole word. The patterns come in complementary pairs.
+func (g *Generator) simpleBitPatternTest(value word.Word) (bool, string) {
+	patterns := []byte{
+		0xAA, // alternating bits
+		0x55, // alternating bits
+		0x33, // repeating pairs
+		0xCC, // repeating pairs
+		0x0F, // repeating quads
+		0xF0, // repeating quads
+	}
+
+	w := g.wordSize()
+	for _, b := range patterns {
+		pattern := repeatByte(b, w)
+		if value == pattern {
+			return false, fmt.Sprintf("repeats byte 0x%02X", b)
+		}
+	}
+	return true, fmt.Sprintf("none of %d repeated byte patterns", len(patterns))
+}
+
+// repeatByte fills a w-bit word with copies of b.
+func repeatByte(b byte, w uint) word.Word {
+	var x word.Word
+	for i := uint(0); i < w/8; i++ {
+		x = x.Lsh(8, w).Or(word.FromUint64(uint64(b)))
+	}
+	return x
+}
+
+// multiplicativeOrderTest requires an odd value to have multiplicative
+// order at least 2^(w/2) mod 2^w. The order is a power of two, at most
+// 2^(w-2), and is low exactly when the value is close to 1 or -1 in its
+// low bits. Even values have no order.
+func (g *Generator) multiplicativeOrderTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	if value.Lo&1 == 0 {
+		return true, "even, not invertible mod 2^w"
+	}
+	one := word.FromUint64(1)
+	k := uint(0)
+	for x := value; x != one; x = x.Mul(x, w) {
+		k++
+	}
+	return k >= w/2, fmt.Sprintf("order 2^%d mod 2^%d", k, w)
+}
+
+// fixedPointTest looks for fixed points of the configured transform keyed
+// with value. For the simple transform the inputs are the nonzero words of
+// at most two set bits and the all-ones word; zero is a fixed point for
+// every constant.
+func (g *Generator) fixedPointTest(value word.Word) (bool, string) {
+	if g.config.Transform != TransformSimple {
+		return g.cipherFixedPointTest(value)
+	}
+	w := g.wordSize()
+	inputs := []word.Word{word.Mask(w)}
+	for i := uint(0); i < w; i++ {
+		bit := word.FromUint64(1).Lsh(i, w)
+		inputs = append(inputs, bit)
+		for j := i + 1; j < w; j++ {
+			inputs = append(inputs, bit.Or(word.FromUint64(1).Lsh(j, w)))
+		}
+	}
+	for _, x := range inputs {
+		if g.rc6Transform(x, value) == x {
+			return false, fmt.Sprintf("fixed point 0x%X", x)
+		}
+	}
+	return true, fmt.Sprintf("no fixed point among %d sparse inputs", len(inputs))
+}
+
+// shortCycleTest iterates the configured transform keyed with value from a
+// few sparse and patterned starting points and fails if it enters a cycle
+// of at most 4w steps. A random permutation of w-bit words puts a given
+// word on a cycle that short with probability 4w/2^w.
+func (g *Generator) shortCycleTest(value word.Word) (bool, string) {
+	if g.config.Transform != TransformSimple {
+		return g.cipherCycleTest(value)
+	}
+	w := g.wordSize()
+	limit := 4 * int(w)
+	starts := []word.Word{word.FromUint64(1), word.FromUint64(1).Lsh(w-1, w), repeatByte(0x55, w)}
+	for _, start := range starts {
+		seen := map[word.Word]int{start: 0}
+		x := start
+		for step := 1; step <= limit; step++ {
+			x = g.rc6Transform(x, value)
+			if first, ok := seen[x]; ok {
+				return false, fmt.Sprintf("cycle of length %d from 0x%X", step-first, start)
+			}
+			seen[x] = step
+		}
+	}
+	return true, fmt.Sprintf("no cycle within %d steps from %d starting words", limit, len(starts))
+}
+
+// roleCipher is RC6 keyed with a candidate in one of its roles.
+type roleCipher struct {
+	role   string
+	cipher *rc6.Cipher
+}
+
+// roleCiphers returns RC6-w/rounds/b under the all-zero key with value as P
+// (and the standard Q) and as Q (and the standard P). The key is fixed so
+// that the weak-key tests and the cryptanalysis are reproducible.
+func (g *Generator) roleCiphers(value word.Word, rounds int) ([]roleCipher, error) {
+	w := g.wordSize()
+	p, q, err := rc6.MagicConstants(w)
+	if err != nil {
+		return nil, err
+	}
+	key := make([]byte, g.config.KeyBytes)
+	var ciphers []roleCipher
+	for _, role := range []struct {
+		name string
+		p, q word.Word
+	}{{"P", value, q}, {"Q", p, value}} {
+		cipher, err := rc6.New(key, w, rounds, role.p, role.q)
+		if err != nil {
+			return nil, err
+		}
+		ciphers = append(ciphers, roleCipher{role.name, cipher})
+	}
+	return ciphers, nil
+}
+
+// encryptBlock encrypts the four words of a block.
+func encryptBlock(c *rc6.Cipher, x [4]word.Word) [4]word.Word {
+	a, b, cc, d := c.EncryptWords(x[0], x[1], x[2], x[3])
+	return [4]word.Word{a, b, cc, d}
+}
+
+func formatBlock(x [4]word.Word) string {
+	return fmt.Sprintf("(0x%X, 0x%X, 0x%X, 0x%X)", x[0], x[1], x[2], x[3])
+}
+
+// cipherFixedPointTest looks for fixed points of RC6 keyed with value among
+// the zero block, the all-ones block and the blocks of one set bit. Blocks
+// are four words wide, so pairs of set bits would take 8w² encryptions per
+// candidate.
+func (g *Generator) cipherFixedPointTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	ciphers, err := g.roleCiphers(value, g.config.CipherRounds)
+	if err != nil {
+		return false, err.Error()
+	}
+	ones := word.Mask(w)
+	inputs := [][4]word.Word{{}, {ones, ones, ones, ones}}
+	for i := uint(0); i < 4*w; i++ {
+		var x [4]word.Word
+		x[i/w] = word.FromUint64(1).Lsh(i%w, w)
+		inputs = append(inputs, x)
+	}
+	for _, c := range ciphers {
+		for _, x := range inputs {
+			if encryptBlock(c.cipher, x) == x {
+				return false, fmt.Sprintf("fixed point %s with the constant as %s", formatBlock(x), c.role)
+			}
+		}
+	}
+	return true, fmt.Sprintf("no fixed point among %d sparse blocks with the constant as P or Q", len(inputs))
+}
+
+// cipherCycleTest iterates RC6 keyed with value from the zero block, a
+// single set bit and a repeated 0x55 pattern, and fails on a cycle of at
+// most 4w steps.
+func (g *Generator) cipherCycleTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	ciphers, err := g.roleCiphers(value, g.config.CipherRounds)
+	if err != nil {
+		return false, err.Error()
+	}
+	limit := 4 * int(w)
+	pattern := repeatByte(0x55, w)
+	starts := [][4]word.Word{{}, {word.FromUint64(1)}, {pattern, pattern, pattern, pattern}}
+	for _, c := range ciphers {
+		for _, start := range starts {
+			seen := map[[4]word.Word]int{start: 0}
+			x := start
+			for step := 1; step <= limit; step++ {
+				x = encryptBlock(c.cipher, x)
+				if first, ok := seen[x]; ok {
+					return false, fmt.Sprintf("cycle of length %d from %s with the constant as %s",
+						step-first, formatBlock(start), c.role)
+				}
+				seen[x] = step
+			}
+		}
+	}
+	return true, fmt.Sprintf("no cycle within %d steps from %d starting blocks with the constant as P or Q", limit, len(starts))
+}
+
+// rotationalSymmetryTest fails if value equals one of its own rotations.
+func (g *Generator) rotationalSymmetryTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	if k := period(value, w); k < w {
+		return false, fmt.Sprintf("equal to its rotation by %d bits", k)
+	}
+	return true, ""
+}
+
+// palindromeTest fails if value reads the same with its bits or its bytes
+// in reverse order.
+func (g *Generator) palindromeTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	var bitsReversed, bytesReversed word.Word
+	for i := uint(0); i < w; i++ {
+		if value.Bit(i) == 1 {
+			bitsReversed = bitsReversed.Or(word.FromUint64(1).Lsh(w-1-i, w))
+		}
+	}
+	for i := uint(0); i < w/8; i++ {
+		b := word.FromUint64(value.Rsh(8*i).Lo & 0xFF)
+		bytesReversed = bytesReversed.Or(b.Lsh(w-8-8*i, w))
+	}
+	switch value {
+	case bitsReversed:
+		return false, "bit palindrome"
+	case bytesReversed:
+		return false, "byte palindrome"
+	}
+	return true, ""
+}
+
+// longRunTest fails on a run of identical bits that a random word has with
+// probability below weakKeyAlpha: a run of at least L bits starts at one of
+// w positions with probability 2^(1-L).
+func (g *Generator) longRunTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	limit := int(math.Ceil(math.Log2(float64(w)/weakKeyAlpha))) + 1
+	longest, run := 1, 1
+	for i := uint(1); i < w; i++ {
+		if value.Bit(i) == value.Bit(i-1) {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+	}
+	return longest < limit, fmt.Sprintf("longest run %d bits (limit %d)", longest, limit)
+}
+
+// repetitionTest counts adjacent equal groups of size bits and fails if a
+// random word has that many with probability below weakKeyAlpha.
+func (g *Generator) repetitionTest(value word.Word, size uint) (bool, string) {
+	w := g.wordSize()
+	groups := int(w / size)
+	mask := uint64(1)<<size - 1
+	equal := 0
+	for i := 1; i < groups; i++ {
+		a := value.Rsh(uint(i)*size).Lo & mask
+		b := value.Rsh(uint(i-1)*size).Lo & mask
+		if a == b {
+			equal++
+		}
+	}
+	p := binomialTail(groups-1, equal, math.Ldexp(1, -int(size)))
+	return p >= weakKeyAlpha, fmt.Sprintf("%d of %d adjacent %d-bit groups equal (probability %.2g)", equal, groups-1, size, p)
+}
+
+// binomialTail returns P(X >= k) for X binomial with n trials of
+// probability p.
+func binomialTail(n, k int, p float64) float64 {
+	tail := 0.0
+	for i := k; i <= n; i++ {
+		lc, _ := math.Lgamma(float64(n + 1))
+		li, _ := math.Lgamma(float64(i + 1))
+		lr, _ := math.Lgamma(float64(n - i + 1))
+		tail += math.Exp(lc - li - lr + float64(i)*math.Log(p) + float64(n-i)*math.Log1p(-p))
+	}
+	return math.Min(tail, 1)
+}
+
+// nearPowerOfTwoTest fails if value lies within 2^(w/4) of a power of two,
+// including 2^w.
+func (g *Generator) nearPowerOfTwoTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	for k := uint(0); k <= w; k++ {
+		// 2^w wraps to zero, which keeps the distance within w bits
+		power := word.FromUint64(1).Lsh(k, w)
+		if k == w {
+			power = word.Word{}
+		}
+		below, above := value.Sub(power, w), power.Sub(value, w)
+		if min(below.BitLen(), above.BitLen()) <= int(w/4) {
+			return false, fmt.Sprintf("within 2^%d of 2^%d", w/4, k)
+		}
+	}
+	return true, ""
+}
+
+// mersenneFermatTest fails if value is a sum or difference of at most two
+// powers of two, the shape of 2^k - 1 and 2^k + 1. It counts the nonzero
+// digits of the non-adjacent form, the signed binary representation with
+// the fewest of them.
+func (g *Generator) mersenneFermatTest(value word.Word) (bool, string) {
+	var terms []string
+	x := value.Big()
+	four := big.NewInt(4)
+	for i := 0; x.Sign() != 0; i++ {
+		if x.Bit(0) == 1 {
+			if new(big.Int).Mod(x, four).Int64() == 1 {
+				x.Sub(x, big.NewInt(1))
+				terms = append(terms, fmt.Sprintf("+ 2^%d", i))
+			} else {
+				x.Add(x, big.NewInt(1))
+				terms = append(terms, fmt.Sprintf("- 2^%d", i))
+			}
+		}
+		x.Rsh(x, 1)
+	}
+	if len(terms) <= 2 {
+		// Most significant term first
+		for i, j := 0, len(terms)-1; i < j; i, j = i+1, j-1 {
+			terms[i], terms[j] = terms[j], terms[i]
+		}
+		return false, "= " + strings.TrimPrefix(strings.Join(terms, " "), "+ ")
+	}
+	return true, fmt.Sprintf("%d signed binary digits", len(terms))
+}
+
+// smallMultipleTest fails if value is m times a well-known constant mod 2^w
+// with m other than 1 small or of low weight: the published Pw and Qw and
+// the odd windows of the expansions derive mode starts from.
+func (g *Generator) smallMultipleTest(value word.Word) (bool, string) {
+	w := g.wordSize()
+	type known struct {
+		name  string
+		value word.Word
+	}
+	var constants []known
+	if p, q, err := rc6.MagicConstants(w); err == nil {
+		constants = append(constants, known{fmt.Sprintf("P%d", w), p}, known{fmt.Sprintf("Q%d", w), q})
+	}
+	for _, c := range expansion.All() {
+		v := word.FromBig(c.Window(0, w))
+		v.Lo |= 1
+		constants = append(constants, known{c.Name, v})
+	}
+
+	one := word.FromUint64(1)
+	for _, c := range constants {
+		m := value.Mul(inverseMod2w(c.value, w), w)
+		if m != one && nearZero(m, w, g.minDifferentBits()) {
+			if negated := (word.Word{}).Sub(m, w); negated.BitLen() < m.BitLen() {
+				return false, fmt.Sprintf("-0x%X times %s", negated, c.name)
+			}
+			return false, fmt.Sprintf("0x%X times %s", m, c.name)
+		}
+	}
+	return true, fmt.Sprintf("checked against %d known constants", len(constants))
+}
@@ -0,0 +1,123 @@
+package constants
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"primer/word"
+)
+
+func TestWeakKeyTests(t *testing.T) {
+	config := DefaultConfig()
+	config.Transform = TransformSimple
+	g := NewGenerator(config)
+	tests := []struct {
+		name    string
+		value   word.Word
+		pattern string
+		details string
+	}{
+		{"RC6 P", RC6_P, "", ""},
+		{"RC6 Q", RC6_Q, "", ""},
+		{"Golden ratio", word.FromUint64(0x9E3779B9), "", ""},
+		{"Low weight", word.FromUint64(0x10204081), weakLowHammingWeight, "Hamming weight 5 of 32"},
+		{"Simple pattern", word.FromUint64(0xCCCCCCCC), weakSimpleBitPattern, "repeats byte 0xCC"},
+		{"Low order", word.FromUint64(0xB7E20001), weakMultiplicative, "order 2^15"},
+		{"Fixed point", word.FromUint64(1), weakFixedPoints, "fixed point"},
+		// With 1 the transform is a rotation by 8 bits
+		{"Short cycle", word.FromUint64(1), weakShortCycles, "cycle of length 4"},
+		{"Rotation", word.FromUint64(0x12341234), weakRotational, "rotation by 16 bits"},
+		{"Bit palindrome", word.FromUint64(0x8C3A5C31), weakPalindrome, "bit palindrome"},
+		{"Byte palindrome", word.FromUint64(0x3A5C5C3A), weakPalindrome, "byte palindrome"},
+		{"Long run", word.FromUint64(0xB0000163), weakLongRuns, "longest run 19 bits"},
+		{"Nibbles", word.FromUint64(0x11111234), weakNibbleRepetition, "4 of 7"},
+		{"Bytes", word.FromUint64(0xABABAB12), weakByteRepetition, "2 of 3"},
+		{"Near a power of two", word.FromUint64(0x80000035), weakNearPowerOfTwo, "of 2^31"},
+		{"Mersenne form", word.FromUint64(0x7FFFFF80), weakMersenneFermat, "= 2^31 - 2^7"},
+		{"Fermat form", word.FromUint64(0x40002000), weakMersenneFermat, "= 2^30 + 2^13"},
+		{"Multiple", RC6_Q.Mul(word.FromUint64(3), 32), weakSmallMultiple, "0x3 times Q32"},
+		{"Negation", word.Word{}.Sub(RC6_P, 32), weakSmallMultiple, "-0x1 times P32"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := g.runWeakKeyTests(tt.value)
+			if len(results) != 13 {
+				t.Fatalf("%d weak-key tests, want 13", len(results))
+			}
+			for _, r := range results {
+				if r.Pattern != tt.pattern {
+					// Constructed examples may have other weaknesses too
+					if tt.pattern == "" && !r.Passed {
+						t.Errorf("%s failed: %s", r.Pattern, r.Details)
+					}
+					continue
+				}
+				if r.Passed {
+					t.Errorf("%s passed: %s", r.Pattern, r.Details)
+				}
+				if !strings.Contains(r.Details, tt.details) {
+					t.Errorf("%s details %q, want %q", r.Pattern, r.Details, tt.details)
+				}
+			}
+		})
+	}
+}
+
+func TestWeakKeyTestsCipher(t *testing.T) {
+	// With RC6 the fixed-point and cycle tests run on the cipher, where the
+	// multiplier 1 is no weaker than any other
+	g := NewGenerator(DefaultConfig())
+	for _, value := range []word.Word{RC6_P, word.FromUint64(1)} {
+		for _, r := range g.runWeakKeyTests(value) {
+			if r.Pattern != weakFixedPoints && r.Pattern != weakShortCycles {
+				continue
+			}
+			if !r.Passed {
+				t.Errorf("0x%X: %s failed: %s", value, r.Pattern, r.Details)
+			}
+			if !strings.Contains(r.Details, "with the constant as P or Q") {
+				t.Errorf("0x%X: %s details %q do not name the cipher roles", value, r.Pattern, r.Details)
+			}
+		}
+	}
+}
+
+func TestWeakKeyTestsPassDerivedConstants(t *testing.T) {
+	for _, w := range []int{32, 64, 128} {
+		config := DefaultConfig()
+		config.WordSize = w
+		config.GenerationMode = ModeDerive
+		g := NewGenerator(config)
+		for _, spec := range g.derivationSpecs() {
+			value, spec, err := g.deriveValue(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, r := range g.runWeakKeyTests(value) {
+				if !r.Passed {
+					t.Errorf("w=%d: %s fails %s: %s", w, spec.Formula, r.Pattern, r.Details)
+				}
+			}
+		}
+	}
+}
+
+func TestBinomialTail(t *testing.T) {
+	tests := []struct {
+		n, k int
+		p    float64
+		want float64
+	}{
+		{3, 0, 0.3, 1},
+		{2, 2, 0.5, 0.25},
+		{4, 1, 0.5, 15.0 / 16},
+		{7, 4, 1.0 / 16, 0.000458},
+	}
+	for _, tt := range tests {
+		if got := binomialTail(tt.n, tt.k, tt.p); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("binomialTail(%d, %d, %v) = %.6f, want %.6f", tt.n, tt.k, tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package constants
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML reads the subset of YAML needed for configuration files: nested
+// block mappings, block and flow lists of scalars, plain and quoted
+// scalars, and # comments. Anchors, multi-line scalars and multiple
+// documents are not supported.
+func parseYAML(data []byte, file string) ([]assignment, error) {
+	type frame struct {
+		indent int
+		path   []string
+	}
+	var (
+		assignments []assignment
+		stack       []frame
+		pending     *assignment // a key whose value is the following block
+		pendingAt   int         // indentation of the pending key
+	)
+	fail := func(line, column int, format string, args ...interface{}) error {
+		return &DecodeError{File: file, Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+	}
+
+	for i, text := range strings.Split(string(data), "\n") {
+		lineNo := i + 1
+		text = strings.TrimRight(stripComment(text, '#', true), " \r")
+		content := strings.TrimLeft(text, " ")
+		if content == "" || (len(stack) == 0 && pending == nil && content == "---") {
+			continue
+		}
+		if strings.HasPrefix(content, "\t") {
+			return nil, fail(lineNo, 1, "tabs are not allowed for indentation")
+		}
+		indent := len(text) - len(content)
+
+		// List items belong to the pending key
+		if content == "-" || strings.HasPrefix(content, "- ") {
+			if pending == nil || indent < pendingAt {
+				return nil, fail(lineNo, indent+1, "list item outside a list")
+			}
+			item, err := parseYAMLScalar(strings.TrimSpace(content[1:]))
+			if err != nil {
+				return nil, fail(lineNo, indent+3, "%v", err)
+			}
+			pending.value.list = true
+			pending.value.items = append(pending.value.items, item)
+			continue
+		}
+
+		if pending != nil {
+			switch {
+			case pending.value.list:
+				assignments = append(assignments, *pending)
+			case indent > pendingAt:
+				stack = append(stack, frame{indent: pendingAt, path: pending.path})
+			default:
+				return nil, fail(pending.line, pending.column, "missing value for %s", strings.Join(pending.path, "."))
+			}
+			pending = nil
+		}
+
+		for len(stack) > 0 && indent <= stack[len(stack)-1].indent {
+			stack = stack[:len(stack)-1]
+		}
+		var prefix []string
+		if len(stack) > 0 {
+			prefix = stack[len(stack)-1].path
+		}
+
+		key, rest, err := splitYAMLKey(content)
+		if err != nil {
+			return nil, fail(lineNo, indent+1, "%v", err)
+		}
+		a := assignment{
+			path:   append(append([]string(nil), prefix...), key),
+			line:   lineNo,
+			column: indent + 1,
+		}
+		if rest == "" {
+			pending, pendingAt = &a, indent
+			continue
+		}
+
+		if strings.HasPrefix(rest, "[") {
+			items, err := parseFlowList(rest, parseYAMLScalar)
+			if err != nil {
+				return nil, fail(lineNo, indent+1, "%v", err)
+			}
+			a.value = rawValue{items: items, list: true}
+		} else {
+			item, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, fail(lineNo, indent+1, "%v", err)
+			}
+			a.value = rawValue{items: []rawScalar{item}}
+		}
+		assignments = append(assignments, a)
+	}
+
+	if pending != nil {
+		if !pending.value.list {
+			return nil, fail(pending.line, pending.column, "missing value for %s", strings.Join(pending.path, "."))
+		}
+		assignments = append(assignments, *pending)
+	}
+	return assignments, nil
+}
+
+// splitYAMLKey splits "key: value" into its key and the trimmed value.
+func splitYAMLKey(content string) (string, string, error) {
+	if content[0] == '"' || content[0] == '\'' {
+		end := closingQuote(content, 0)
+		if end < 0 {
+			return "", "", fmt.Errorf("unterminated quoted key")
+		}
+		key, err := parseYAMLScalar(content[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		rest := strings.TrimSpace(content[end+1:])
+		if !strings.HasPrefix(rest, ":") {
+			return "", "", fmt.Errorf("expected ':' after key")
+		}
+		return key.text, strings.TrimSpace(rest[1:]), nil
+	}
+
+	for i := 0; i < len(content); i++ {
+		if content[i] == ':' && (i+1 == len(content) || content[i+1] == ' ') {
+			return strings.TrimSpace(content[:i]), strings.TrimSpace(content[i+1:]), nil
+		}
+	}
+	return "", "", fmt.Errorf("expected 'key: value'")
+}
+
+// parseYAMLScalar parses a plain, single-quoted or double-quoted scalar.
+func parseYAMLScalar(s string) (rawScalar, error) {
+	if s == "" {
+		return rawScalar{}, fmt.Errorf("missing value")
+	}
+	switch s[0] {
+	case '"':
+		text, err := strconv.Unquote(s)
+		if err != nil {
+			return rawScalar{}, fmt.Errorf("invalid quoted string %s", s)
+		}
+		return rawScalar{text: text, quoted: true}, nil
+	case '\'':
+		if len(s) < 2 || s[len(s)-1] != '\'' {
+			return rawScalar{}, fmt.Errorf("invalid quoted string %s", s)
+		}
+		return rawScalar{text: strings.ReplaceAll(s[1:len(s)-1], "''", "'"), quoted: true}, nil
+	case '{', '&', '*', '|', '>':
+		return rawScalar{}, fmt.Errorf("unsupported YAML value %s", s)
+	}
+	return rawScalar{text: s}, nil
+}
+
+// parseFlowList parses "[a, b, c]" using parse for each item.
+func parseFlowList(s string, parse func(string) (rawScalar, error)) ([]rawScalar, error) {
+	if !strings.HasSuffix(s, "]") {
+		return nil, fmt.Errorf("unterminated list")
+	}
+	inner := strings.TrimSpace(s[1 : len(s)-1])
+	items := []rawScalar{}
+	if inner == "" {
+		return items, nil
+	}
+
+	start := 0
+	for i := 0; i <= len(inner); i++ {
+		if i < len(inner) && (inner[i] == '"' || inner[i] == '\'') {
+			end := closingQuote(inner, i)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string in list")
+			}
+			i = end
+			continue
+		}
+		if i == len(inner) || inner[i] == ',' {
+			part := strings.TrimSpace(inner[start:i])
+			start = i + 1
+			// Allow a trailing comma
+			if part == "" && i == len(inner) {
+				break
+			}
+			item, err := parse(part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+// closingQuote returns the index of the quote closing the string that
+// starts at s[start], or -1.
+func closingQuote(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote:
+			// '' is an escaped quote inside single quotes
+			if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a comment started by marker outside quotes. When
+// spaced is set, as in YAML, the marker must start the line or follow
+// whitespace.
+func stripComment(line string, marker byte, spaced bool) string {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '"', '\'':
+			if end := closingQuote(line, i); end >= 0 {
+				i = end
+			}
+		case marker:
+			if !spaced || i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
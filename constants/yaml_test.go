@@ -0,0 +1,184 @@
+package constants
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const shippedYAML = `# Same settings as config.json
+WordSize: 32
+NumCandidates: 1000
+AvalancheTestCases: 10000
+MinPrimeAttempts: 100
+MaxPrimeAttempts: 10000
+ParallelWorkers: 8
+
+MinBitDistribution: 0.45
+MaxBitDistribution: 0.55
+MinAvalancheScore: 0.49
+
+MaxSACDeviation: 0
+MinSACPValue: 0
+MaxBICCorrelation: 0
+
+Transform: rc6
+CipherRounds: 20
+ReducedRounds: 4
+KeyBytes: 16
+
+GenerationMode: "random"
+RequirePrime: true
+PrimeClass: any
+Derivation:
+  Sources: [e, phi, pi, sqrt2, sqrt3, sqrt5, ln2]
+  Rules:
+    - odd
+    - 'nextprime'
+  Offsets: [0, 32, 64]
+
+StatisticalTests:
+  MinPValue: 0.01
+  MinEntropyScore: 0.95
+  MaxEntropyScore: 1.0
+
+ValidationCriteria:
+  MinHammingWeight: 0
+  MaxHammingWeight: 0
+  MinHammingDistance: 0
+  MinDifferentBits: 0
+  MaxFailedTestFraction: 0.2
+  TestAggregation: fraction
+
+ResultsFile: rc6_constants.json   # trailing comment
+DetailedLogging: true
+StatisticalAnalysis: true
+
+BatchSize: 100
+TimeoutSeconds: 3600
+
+"// Advanced options": Fine-tuning parameters
+RandomnessTests:
+  EnableExtendedTests: true
+  RunsTestWeight: 1.0
+  FrequencyTestWeight: 1.0
+  SerialTestWeight: 1.0
+  AutocorrelationWeight: 1.0
+  LinearComplexityWeight: 1.0
+
+ScoringWeights:
+  BitDistribution: 1.0
+  Avalanche: 2.0
+  Entropy: 0.75
+
+# Constants to select, pairwise different, and runners-up to report
+ConstantNames: [P, Q]
+Alternates: 5
+
+SelectionStrategy: score
+ParetoTieBreakers: [Score]
+ParetoTolerance: 0
+
+Cryptanalysis:
+  Enabled: true
+  Bases: 1
+  MaxDifferentialProbability: 0
+  MaxLinearBias: 0
+
+KeySchedule:
+  Enabled: true
+  Keys: 32
+  EquivalentKeyBits: 12
+
+PairAnalysis:
+  Enabled: true
+  MaxCorrelation: 0.75
+  MaxOutputBias: 0.25
+
+NIST:
+  Enabled: false
+  Sequences: 10
+  StreamBits: 1048576
+  Alpha: 0.01
+  MinUniformityKS: 0.0001
+`
+
+func TestLoadYAMLMatchesJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(shippedYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fromYAML, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig(yaml) error = %v", err)
+	}
+	fromJSON, err := LoadConfig(filepath.Join("..", "config.json"))
+	if err != nil {
+		t.Fatalf("LoadConfig(json) error = %v", err)
+	}
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("YAML config differs from JSON:\n%+v\n%+v", fromYAML, fromJSON)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{"Unknown nested key", "Derivation:\n  Offset: [0]\n", `config.yaml:2:3: unknown field "Offset" (did you mean "Offsets"?)`},
+		{"Quoted number", "NumCandidates: \"10\"\n", `config.yaml:1:1: expected int, got string "10"`},
+		{"Scalar for list", "Derivation:\n  Rules: odd\n", `config.yaml:2:3: expected a list`},
+		{"Missing value", "NumCandidates:\nWordSize: 32\n", `config.yaml:1:1: missing value for NumCandidates`},
+		{"Stray list item", "- odd\n", `config.yaml:1:1: list item outside a list`},
+		{"Tab indentation", "Derivation:\n\tRules: [odd]\n", `config.yaml:2:1: tabs are not allowed`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			_, err := decodeAssignments(parseYAML, []byte(tt.input), "config.yaml", &config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseYAMLValues(t *testing.T) {
+	input := `
+Seed: 'it''s # not a comment'
+ResultsFile: "out/results #1.json"
+Derivation:
+  Sources:
+  - e
+  - pi
+  Offsets: []
+StatisticalTests:
+  MinRunsZScore: -2.5
+`
+	config := DefaultConfig()
+	lines, err := decodeAssignments(parseYAML, []byte(input), "config.yaml", &config)
+	if err != nil {
+		t.Fatalf("decodeAssignments() error = %v", err)
+	}
+	if config.Seed != "it's # not a comment" {
+		t.Errorf("Seed = %q", config.Seed)
+	}
+	if config.ResultsFile != "out/results #1.json" {
+		t.Errorf("ResultsFile = %q", config.ResultsFile)
+	}
+	if !reflect.DeepEqual(config.Derivation.Sources, []string{"e", "pi"}) || len(config.Derivation.Offsets) != 0 {
+		t.Errorf("Derivation = %+v", config.Derivation)
+	}
+	if config.StatisticalTests.MinRunsZScore != -2.5 {
+		t.Errorf("MinRunsZScore = %v", config.StatisticalTests.MinRunsZScore)
+	}
+	if lines["Derivation.Sources"] != 5 || lines["StatisticalTests.MinRunsZScore"] != 10 {
+		t.Errorf("lines = %v", lines)
+	}
+}
@@ -0,0 +1,176 @@
+// Package cryptanalysis computes the differential and linear properties of
+// 8-bit functions: the difference distribution table (DDT), the linear
+// approximation table (LAT), and their largest entries. Wider functions are
+// studied through 8-bit slices: one byte of the input varies while the
+// rest is held fixed, and one byte of the output is observed. A Sample
+// combines slices at many fixed values into an estimate for the byte with
+// the rest of the input random.
+package cryptanalysis
+
+import (
+	"math/bits"
+
+	"primer/word"
+)
+
+// SBox is an 8-bit function given by its table.
+type SBox [256]byte
+
+// Table is a DDT or LAT, indexed by input difference or mask, then output
+// difference or mask.
+type Table [256][256]int
+
+// Slice returns the 8-bit function that maps x to byte out of f(y), where
+// y is the w-bit base with byte in replaced by x. Bytes are numbered from
+// the least significant.
+func Slice(f func(word.Word) word.Word, w uint, base word.Word, in, out uint) *SBox {
+	var s SBox
+	clearByte := word.FromUint64(0xFF).Lsh(8*in, w).Not(w)
+	for x := range s {
+		y := base.And(clearByte).Or(word.FromUint64(uint64(x)).Lsh(8*in, w))
+		s[x] = byte(f(y).Rsh(8 * out).Lo)
+	}
+	return &s
+}
+
+// DDT returns the difference distribution table of s: entry [a][b] counts
+// the inputs x with s(x) ^ s(x^a) = b.
+func DDT(s *SBox) *Table {
+	var t Table
+	for a := range t {
+		for x := range s {
+			t[a][s[x]^s[x^a]]++
+		}
+	}
+	return &t
+}
+
+// LAT returns the linear approximation table of s: entry [a][b] is the
+// number of inputs x with a·x = b·s(x), minus 128.
+func LAT(s *SBox) *Table {
+	var t Table
+	var spectrum [256]int
+	for b := range t {
+		// The Walsh-Hadamard transform of (-1)^(b·s(x)) gives twice the
+		// entries of column b
+		for x := range spectrum {
+			spectrum[x] = 1 - 2*(bits.OnesCount8(byte(b)&s[x])&1)
+		}
+		walsh(&spectrum)
+		for a := range t {
+			t[a][b] = spectrum[a] / 2
+		}
+	}
+	return &t
+}
+
+// walsh replaces v with its Walsh-Hadamard transform: entry a becomes the
+// sum of v[x] (-1)^(a·x).
+func walsh(v *[256]int) {
+	for h := 1; h < len(v); h *= 2 {
+		for i := 0; i < len(v); i += 2 * h {
+			for j := i; j < i+h; j++ {
+				u, w := v[j], v[j+h]
+				v[j], v[j+h] = u+w, u-w
+			}
+		}
+	}
+}
+
+// Sample accumulates slices of one function taken at different bases. A
+// single slice fixes every other input bit; the sample estimates the
+// differential and linear properties of the byte-to-byte relation with
+// those bits random.
+type Sample struct {
+	ddt    Table
+	joint  Table // joint[x][y] counts the slices that map x to y
+	slices int
+}
+
+// Add adds the slice s to the sample.
+func (m *Sample) Add(s *SBox) {
+	for a := range m.ddt {
+		for x := range s {
+			m.ddt[a][s[x]^s[x^a]]++
+		}
+	}
+	for x, y := range s {
+		m.joint[x][y]++
+	}
+	m.slices++
+}
+
+// DDT returns the sum of the difference distribution tables of the slices.
+func (m *Sample) DDT() *Table {
+	t := m.ddt
+	return &t
+}
+
+// LAT returns the sum of the linear approximation tables of the slices.
+func (m *Sample) LAT() *Table {
+	// Entry [a][b] is half the sum of joint[x][y] (-1)^(a·x ^ b·y): a
+	// Walsh-Hadamard transform over y, then over x
+	t := m.joint
+	for x := range t {
+		walsh(&t[x])
+	}
+	var column [256]int
+	for b := range column {
+		for x := range t {
+			column[x] = t[x][b]
+		}
+		walsh(&column)
+		for a := range t {
+			t[a][b] = column[a] / 2
+		}
+	}
+	return &t
+}
+
+// MaxDifferential returns the largest probability, over the inputs of
+// every slice together, of a differential with a nonzero input difference,
+// and that differential.
+func (m *Sample) MaxDifferential() (probability float64, a, b byte) {
+	p, a, b := MaxDifferential(m.DDT())
+	return p / float64(m.slices), a, b
+}
+
+// MaxLinearBias returns the largest bias, over the inputs of every slice
+// together, of a linear approximation with a nonzero output mask, and its
+// masks.
+func (m *Sample) MaxLinearBias() (bias float64, a, b byte) {
+	bias, a, b = MaxLinearBias(m.LAT())
+	return bias / float64(m.slices), a, b
+}
+
+// MaxDifferential returns the largest probability of a differential with a
+// nonzero input difference, and that differential.
+func MaxDifferential(ddt *Table) (probability float64, a, b byte) {
+	best := -1
+	for i := 1; i < 256; i++ {
+		for j, count := range ddt[i] {
+			if count > best {
+				best, a, b = count, byte(i), byte(j)
+			}
+		}
+	}
+	return float64(best) / 256, a, b
+}
+
+// MaxLinearBias returns the largest bias |P(a·x = b·s(x)) - 1/2| of a
+// linear approximation with a nonzero output mask, and its masks.
+func MaxLinearBias(lat *Table) (bias float64, a, b byte) {
+	best := -1
+	for i := range lat {
+		for j := 1; j < 256; j++ {
+			c := lat[i][j]
+			if c < 0 {
+				c = -c
+			}
+			if c > best {
+				best, a, b = c, byte(i), byte(j)
+			}
+		}
+	}
+	return float64(best) / 256, a, b
+}
@@ -0,0 +1,147 @@
+package cryptanalysis
+
+import (
+	"math/bits"
+	"testing"
+
+	"primer/word"
+)
+
+// aesSBox computes the AES S-box: inversion in GF(2^8) followed by the
+// affine map.
+func aesSBox() *SBox {
+	mul := func(a, b byte) byte {
+		var p byte
+		for ; b != 0; b >>= 1 {
+			if b&1 != 0 {
+				p ^= a
+			}
+			a = a<<1 ^ (a>>7)*0x1B
+		}
+		return p
+	}
+	var s SBox
+	for x := range s {
+		inv := byte(0)
+		for y := 1; y < 256 && x != 0; y++ {
+			if mul(byte(x), byte(y)) == 1 {
+				inv = byte(y)
+				break
+			}
+		}
+		s[x] = inv ^ bits.RotateLeft8(inv, 1) ^ bits.RotateLeft8(inv, 2) ^
+			bits.RotateLeft8(inv, 3) ^ bits.RotateLeft8(inv, 4) ^ 0x63
+	}
+	return &s
+}
+
+func TestTables(t *testing.T) {
+	var identity SBox
+	for x := range identity {
+		identity[x] = byte(x)
+	}
+	aes := aesSBox()
+	if aes[0x00] != 0x63 || aes[0x53] != 0xED {
+		t.Fatalf("AES S-box: S(00) = %02x, S(53) = %02x", aes[0x00], aes[0x53])
+	}
+
+	tests := []struct {
+		name     string
+		s        *SBox
+		wantDP   float64
+		wantBias float64
+	}{
+		{"Identity", &identity, 1, 0.5},
+		// Differential uniformity 4 and nonlinearity 112
+		{"AES", aes, 4.0 / 256, 16.0 / 256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ddt, lat := DDT(tt.s), LAT(tt.s)
+			for a := range ddt {
+				sum := 0
+				for _, count := range ddt[a] {
+					sum += count
+				}
+				if sum != 256 {
+					t.Fatalf("DDT row %d sums to %d", a, sum)
+				}
+			}
+			if ddt[0][0] != 256 || lat[0][0] != 128 {
+				t.Errorf("DDT[0][0] = %d, LAT[0][0] = %d", ddt[0][0], lat[0][0])
+			}
+			if dp, _, _ := MaxDifferential(ddt); dp != tt.wantDP {
+				t.Errorf("MaxDifferential = %v, want %v", dp, tt.wantDP)
+			}
+			if bias, _, _ := MaxLinearBias(lat); bias != tt.wantBias {
+				t.Errorf("MaxLinearBias = %v, want %v", bias, tt.wantBias)
+			}
+		})
+	}
+}
+
+func TestLATMatchesDefinition(t *testing.T) {
+	s := aesSBox()
+	lat := LAT(s)
+	for _, m := range [][2]int{{1, 1}, {0x53, 0xCA}, {0xFF, 0x80}} {
+		count := 0
+		for x := range s {
+			if bits.OnesCount8(byte(x)&byte(m[0]))&1 == bits.OnesCount8(s[x]&byte(m[1]))&1 {
+				count++
+			}
+		}
+		if lat[m[0]][m[1]] != count-128 {
+			t.Errorf("LAT[%02x][%02x] = %d, want %d", m[0], m[1], lat[m[0]][m[1]], count-128)
+		}
+	}
+}
+
+func TestSlice(t *testing.T) {
+	rotate := func(x word.Word) word.Word { return x.RotateLeft(8, 32) }
+	base := word.FromUint64(0x11223344)
+
+	// Byte 1 of the input lands in byte 2 of the output
+	s := Slice(rotate, 32, base, 1, 2)
+	for x := range s {
+		if s[x] != byte(x) {
+			t.Fatalf("slice(%02x) = %02x, want the identity", x, s[x])
+		}
+	}
+	// Byte 0 of the output is byte 3 of the base whatever x is
+	s = Slice(rotate, 32, base, 1, 0)
+	for x := range s {
+		if s[x] != 0x11 {
+			t.Fatalf("slice(%02x) = %02x, want 11", x, s[x])
+		}
+	}
+}
+
+func TestSample(t *testing.T) {
+	aes := aesSBox()
+	var single Sample
+	single.Add(aes)
+	if *single.DDT() != *DDT(aes) || *single.LAT() != *LAT(aes) {
+		t.Fatal("tables of a one-slice sample differ from those of the slice")
+	}
+
+	// Complementing the output keeps every difference but flips the
+	// approximations with an odd output mask, which cancel out between the
+	// two slices
+	var identity, complement SBox
+	for x := range identity {
+		identity[x] = byte(x)
+		complement[x] = ^byte(x)
+	}
+	var sample Sample
+	sample.Add(&identity)
+	sample.Add(&complement)
+	if p, a, b := sample.MaxDifferential(); p != 1 || a != b {
+		t.Errorf("MaxDifferential() = %v, %02x, %02x, want 1 along the diagonal", p, a, b)
+	}
+	if bias, _, b := sample.MaxLinearBias(); bias != 0.5 || bits.OnesCount8(b)&1 != 0 {
+		t.Errorf("MaxLinearBias() = %v with output mask %02x, want 0.5 with an even mask", bias, b)
+	}
+	if lat := sample.LAT(); lat[1][1] != 0 || lat[3][3] != 256 {
+		t.Errorf("LAT[1][1] = %d, LAT[3][3] = %d, want 0 and 256", lat[1][1], lat[3][3])
+	}
+}
@@ -0,0 +1,250 @@
+// Package expansion computes binary expansions of well-known irrational
+// constants to arbitrary precision with math/big. It is the basis of the
+// nothing-up-my-sleeve derivation mode and of the provenance auditor.
+package expansion
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// guardBits are computed beyond the requested precision so that series
+// truncation error never reaches the returned bits.
+const guardBits = 64
+
+// Constant is a well-known irrational constant.
+type Constant struct {
+	// Name identifies the constant in configuration files, e.g. "phi".
+	Name string
+	// Symbol is used in derivation formulas, e.g. "φ".
+	Symbol string
+	// Description is a human-readable definition.
+	Description string
+
+	fixed func(prec uint) *big.Int
+	cache *fixedCache
+}
+
+// fixedCache keeps the most precise expansion computed so far.
+type fixedCache struct {
+	mu    sync.Mutex
+	prec  uint
+	value *big.Int
+}
+
+var registry = map[string]Constant{}
+
+func register(name, symbol, description string, fixed func(prec uint) *big.Int) {
+	registry[name] = Constant{
+		Name:        name,
+		Symbol:      symbol,
+		Description: description,
+		fixed:       fixed,
+		cache:       &fixedCache{},
+	}
+}
+
+func init() {
+	register("e", "e", "Euler's number", fixedE)
+	register("phi", "φ", "golden ratio (1+√5)/2", fixedPhi)
+	register("pi", "π", "ratio of circumference to diameter", fixedPi)
+	register("sqrt2", "√2", "square root of 2", fixedSqrt(2))
+	register("sqrt3", "√3", "square root of 3", fixedSqrt(3))
+	register("sqrt5", "√5", "square root of 5", fixedSqrt(5))
+	register("sqrt7", "√7", "square root of 7", fixedSqrt(7))
+	register("cbrt2", "∛2", "cube root of 2", fixedCbrt(2))
+	register("cbrt3", "∛3", "cube root of 3", fixedCbrt(3))
+	register("ln2", "ln2", "natural logarithm of 2", fixedLn2)
+	register("ln3", "ln3", "natural logarithm of 3", fixedLn3)
+	register("ln10", "ln10", "natural logarithm of 10", fixedLn10)
+}
+
+// Lookup returns the constant with the given name.
+func Lookup(name string) (Constant, error) {
+	c, ok := registry[name]
+	if !ok {
+		return Constant{}, fmt.Errorf("unknown constant %q (known: %v)", name, Names())
+	}
+	return c, nil
+}
+
+// Names returns the names of all known constants in sorted order.
+func Names() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// All returns every known constant, sorted by name.
+func All() []Constant {
+	var all []Constant
+	for _, name := range Names() {
+		all = append(all, registry[name])
+	}
+	return all
+}
+
+// Fixed returns floor(x * 2^fracBits), i.e. the integer part of x followed
+// by fracBits bits of its binary expansion.
+func (c Constant) Fixed(fracBits uint) *big.Int {
+	c.cache.mu.Lock()
+	defer c.cache.mu.Unlock()
+
+	if c.cache.value == nil || c.cache.prec < fracBits {
+		// Round the precision up so that nearby requests share one expansion
+		prec := (fracBits + 255) &^ 255
+		v := c.fixed(prec + guardBits)
+		c.cache.value = v.Rsh(v, guardBits)
+		c.cache.prec = prec
+	}
+	return new(big.Int).Rsh(c.cache.value, c.cache.prec-fracBits)
+}
+
+// IntegerPart returns floor(x).
+func (c Constant) IntegerPart() int64 {
+	return c.Fixed(0).Int64()
+}
+
+// Window returns floor(frac(x) * 2^(offset+width)) mod 2^width: the width
+// bits of the fractional part of x that start offset bits after the binary
+// point.
+func (c Constant) Window(offset, width uint) *big.Int {
+	v := c.Fixed(offset + width)
+	mask := new(big.Int).Lsh(big.NewInt(1), width)
+	mask.Sub(mask, big.NewInt(1))
+	return v.And(v, mask)
+}
+
+// Float returns x rounded to prec bits of mantissa.
+func (c Constant) Float(prec uint) *big.Float {
+	f := new(big.Float).SetPrec(prec).SetInt(c.Fixed(prec))
+	return f.SetMantExp(f, -int(prec))
+}
+
+// FractionSymbol returns the fractional part of x as it appears in
+// formulas, e.g. "(e-2)" or "ln2".
+func (c Constant) FractionSymbol() string {
+	if n := c.IntegerPart(); n != 0 {
+		return fmt.Sprintf("(%s-%d)", c.Symbol, n)
+	}
+	return c.Symbol
+}
+
+// one returns 2^prec, the fixed-point representation of 1.
+func one(prec uint) *big.Int {
+	return new(big.Int).Lsh(big.NewInt(1), prec)
+}
+
+// fixedE sums 1/k! for k >= 0.
+func fixedE(prec uint) *big.Int {
+	sum := new(big.Int)
+	term := one(prec)
+	for k := int64(1); term.Sign() > 0; k++ {
+		sum.Add(sum, term)
+		term.Quo(term, big.NewInt(k))
+	}
+	return sum
+}
+
+// fixedArctanInv returns arctan(1/x) with Gregory's series.
+func fixedArctanInv(x int64, prec uint) *big.Int {
+	sum := new(big.Int)
+	x2 := big.NewInt(x * x)
+	power := new(big.Int).Quo(one(prec), big.NewInt(x)) // 1/x^(2k+1)
+	term := new(big.Int)
+	for k := int64(0); power.Sign() > 0; k++ {
+		term.Quo(power, big.NewInt(2*k+1))
+		if k%2 == 0 {
+			sum.Add(sum, term)
+		} else {
+			sum.Sub(sum, term)
+		}
+		power.Quo(power, x2)
+	}
+	return sum
+}
+
+// fixedArctanhInv returns atanh(1/x) = sum 1/((2k+1) x^(2k+1)).
+func fixedArctanhInv(x int64, prec uint) *big.Int {
+	sum := new(big.Int)
+	x2 := big.NewInt(x * x)
+	power := new(big.Int).Quo(one(prec), big.NewInt(x))
+	term := new(big.Int)
+	for k := int64(0); power.Sign() > 0; k++ {
+		term.Quo(power, big.NewInt(2*k+1))
+		sum.Add(sum, term)
+		power.Quo(power, x2)
+	}
+	return sum
+}
+
+// fixedPi uses Machin's formula pi = 16 arctan(1/5) - 4 arctan(1/239).
+func fixedPi(prec uint) *big.Int {
+	a := fixedArctanInv(5, prec)
+	b := fixedArctanInv(239, prec)
+	a.Lsh(a, 4)
+	b.Lsh(b, 2)
+	return a.Sub(a, b)
+}
+
+func fixedSqrt(n int64) func(prec uint) *big.Int {
+	return func(prec uint) *big.Int {
+		v := new(big.Int).Lsh(big.NewInt(n), 2*prec)
+		return v.Sqrt(v)
+	}
+}
+
+// fixedCbrt computes floor(cbrt(n * 2^(3 prec))) with Newton's method.
+func fixedCbrt(n int64) func(prec uint) *big.Int {
+	return func(prec uint) *big.Int {
+		target := new(big.Int).Lsh(big.NewInt(n), 3*prec)
+
+		// Start above the root so the iteration decreases monotonically
+		x := new(big.Int).Lsh(big.NewInt(1), uint(target.BitLen()/3+1))
+		three := big.NewInt(3)
+		for {
+			// y = (2x + target/x^2) / 3
+			y := new(big.Int).Mul(x, x)
+			y.Quo(target, y)
+			y.Add(y, new(big.Int).Lsh(x, 1))
+			y.Quo(y, three)
+			if y.Cmp(x) >= 0 {
+				return x
+			}
+			x = y
+		}
+	}
+}
+
+// fixedPhi returns (1 + sqrt 5) / 2.
+func fixedPhi(prec uint) *big.Int {
+	v := fixedSqrt(5)(prec)
+	v.Add(v, one(prec))
+	return v.Rsh(v, 1)
+}
+
+// fixedLn2 uses ln 2 = 2 atanh(1/3).
+func fixedLn2(prec uint) *big.Int {
+	v := fixedArctanhInv(3, prec)
+	return v.Lsh(v, 1)
+}
+
+// fixedLn3 uses ln 3 = ln 2 + ln(3/2) = ln 2 + 2 atanh(1/5).
+func fixedLn3(prec uint) *big.Int {
+	v := fixedArctanhInv(5, prec)
+	v.Lsh(v, 1)
+	return v.Add(v, fixedLn2(prec))
+}
+
+// fixedLn10 uses ln 10 = 3 ln 2 + ln(5/4) = 3 ln 2 + 2 atanh(1/9).
+func fixedLn10(prec uint) *big.Int {
+	v := fixedArctanhInv(9, prec)
+	v.Lsh(v, 1)
+	ln2 := fixedLn2(prec)
+	return v.Add(v, ln2.Mul(ln2, big.NewInt(3)))
+}
@@ -0,0 +1,112 @@
+package expansion
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// Published hexadecimal expansions of the fractional parts. The square and
+// cube roots also appear as the SHA-2 initial values and round constants.
+func TestKnownExpansions(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"e", "B7E151628AED2A6ABF7158809CF4F3C7"},
+		{"phi", "9E3779B97F4A7C15F39CC0605CEDC834"},
+		{"pi", "243F6A8885A308D313198A2E03707344"},
+		{"sqrt2", "6A09E667F3BCC908"},
+		{"sqrt3", "BB67AE8584CAA73B"},
+		{"sqrt5", "3C6EF372FE94F82B"},
+		{"sqrt7", "A54FF53A5F1D36F1"},
+		{"cbrt2", "428A2F98D728AE22"},
+		{"cbrt3", "7137449123EF65CD"},
+		{"ln2", "B17217F7D1CF79ABC9E3B39803F2F6AF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Lookup(tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			width := uint(len(tt.want) * 4)
+			got := fmt.Sprintf("%0*X", len(tt.want), c.Window(0, width))
+			if got != tt.want {
+				t.Errorf("Window(0, %d) = %s, want %s", width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesFloat64(t *testing.T) {
+	want := map[string]float64{
+		"e":     math.E,
+		"phi":   math.Phi,
+		"pi":    math.Pi,
+		"sqrt2": math.Sqrt2,
+		"sqrt3": math.Sqrt(3),
+		"sqrt5": math.Sqrt(5),
+		"sqrt7": math.Sqrt(7),
+		"cbrt2": math.Cbrt(2),
+		"cbrt3": math.Cbrt(3),
+		"ln2":   math.Ln2,
+		"ln3":   math.Log(3),
+		"ln10":  math.Ln10,
+	}
+
+	for _, c := range All() {
+		w, ok := want[c.Name]
+		if !ok {
+			t.Errorf("no float64 reference for %s", c.Name)
+			continue
+		}
+		got, _ := c.Float(64).Float64()
+		if math.Abs(got-w) > 1e-15*w {
+			t.Errorf("%s = %.17g, want %.17g", c.Name, got, w)
+		}
+	}
+}
+
+func TestWindowOffsets(t *testing.T) {
+	c, _ := Lookup("pi")
+
+	// Consecutive windows concatenate to the wider window
+	wide := c.Window(0, 64)
+	hi := c.Window(0, 32)
+	lo := c.Window(32, 32)
+	if hi.Uint64()<<32|lo.Uint64() != wide.Uint64() {
+		t.Errorf("windows %X and %X do not concatenate to %X", hi, lo, wide)
+	}
+
+	// Deep windows are consistent with a fresh, more precise expansion
+	deep := c.Window(1000, 32)
+	again := c.Fixed(2048)
+	again.Rsh(again, 2048-1032)
+	if deep.Uint64() != again.Uint64()&0xFFFFFFFF {
+		t.Errorf("Window(1000, 32) = %X, want %X", deep, again.Uint64()&0xFFFFFFFF)
+	}
+}
+
+func TestFractionSymbol(t *testing.T) {
+	tests := map[string]string{
+		"e":    "(e-2)",
+		"phi":  "(φ-1)",
+		"pi":   "(π-3)",
+		"ln2":  "ln2",
+		"ln10": "(ln10-2)",
+	}
+	for name, want := range tests {
+		c, _ := Lookup(name)
+		if got := c.FractionSymbol(); got != want {
+			t.Errorf("%s: FractionSymbol() = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestLookupUnknown(t *testing.T) {
+	if _, err := Lookup("tau"); err == nil {
+		t.Error("Lookup(tau) expected error")
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+    "primer/constants"
+    "primer/word"
+    "encoding/json"
+    "fmt"
+    "os"
+)
+
+const explainUsage = `Usage: primer explain [flags] <value>
+
+Searches the binary expansions of e, φ, π, √2, √3, ln 2 and similar
+constants, under Odd(), NextPrime, window offsets, small additive offsets,
+rotations and complements, for a derivation of <value>. Reports the
+simplest derivation found and a rigidity score.
+
+Flags:
+`
+
+func runExplain(args []string) {
+    fs := newFlagSet("explain", explainUsage)
+    wordSize := fs.Int("w", 0, "Word size in bits (default: smallest of 16, 32, 64, 128 that fits)")
+    maxOffset := fs.Int("max-offset", constants.DefaultExplainMaxOffset, "Largest bit offset into each expansion")
+    maxDelta := fs.Int("max-delta", constants.DefaultExplainMaxDelta, "Largest additive offset from a window")
+    format := fs.String("format", "text", "Output format (text, json)")
+    all := fs.Bool("all", false, "List every derivation found, not just the best few")
+    verbose := fs.Bool("verbose", false, "Include the full constant analysis")
+    fs.Parse(args)
+
+    if fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    value, err := word.Parse(fs.Arg(0))
+    if err != nil {
+        fmt.Printf("Error parsing value: %v\n", err)
+        os.Exit(1)
+    }
+
+    config := constants.DefaultConfig()
+    config.DetailedLogging = false
+    config.WordSize = *wordSize
+    if config.WordSize == 0 {
+        config.WordSize = constants.InferWordSize(value)
+    }
+    if err := constants.ValidateConfig(&config); err != nil {
+        fmt.Printf("Error: %v\n", err)
+        os.Exit(1)
+    }
+    if value.BitLen() > config.WordSize {
+        fmt.Printf("Error: 0x%X does not fit in %d bits\n", value, config.WordSize)
+        os.Exit(1)
+    }
+
+    generator := constants.NewGenerator(config)
+    explanation := generator.Explain(value, constants.ExplainOptions{
+        MaxOffset: *maxOffset,
+        MaxDelta:  *maxDelta,
+    })
+
+    if *format == "json" {
+        output, err := json.MarshalIndent(explanation, "", "  ")
+        if err != nil {
+            fmt.Printf("Error generating JSON output: %v\n", err)
+            os.Exit(1)
+        }
+        fmt.Println(string(output))
+        return
+    }
+
+    printExplanation(explanation, *all, *verbose)
+}
+
+func printExplanation(e constants.Explanation, all, verbose bool) {
+    fmt.Printf("Value: %s (%d-bit word)\n", e.Value.Hex(uint(e.WordSize)), e.WordSize)
+    fmt.Printf("Derivations searched: %d\n", e.SearchSpace)
+
+    if e.Best == nil {
+        fmt.Printf("\nNo derivation found. Rigidity: 0.0000\n")
+    } else {
+        fmt.Printf("\nSimplest derivation: %s\n", e.Best.Formula)
+        fmt.Printf("  Source: %s, rule: %s, offset: %d", e.Best.Source, e.Best.Rule, e.Best.Offset)
+        if e.Best.Transform != "" {
+            fmt.Printf(", transform: %s", e.Best.Transform)
+        }
+        fmt.Printf("\n  Description length: %.2f bits\n", e.Best.Complexity)
+        fmt.Printf("Rigidity: %.4f\n", e.Rigidity)
+        fmt.Printf("Chance of a match this good for a random value: %.3g\n", e.ChanceMatch)
+
+        others := e.Matches[1:]
+        if !all && len(others) > 5 {
+            others = others[:5]
+        }
+        if len(others) > 0 {
+            fmt.Printf("\nOther derivations:\n")
+            for _, m := range others {
+                fmt.Printf("  %-48s %.2f bits\n", m.Formula, m.Complexity)
+            }
+            if hidden := len(e.Matches) - 1 - len(others); hidden > 0 {
+                fmt.Printf("  ... %d more (use -all)\n", hidden)
+            }
+        }
+    }
+
+    fmt.Printf("\nConstant Analysis:\n")
+    if verbose {
+        printConstantAnalysis(os.Stdout, e.Analysis)
+        return
+    }
+    fmt.Printf("  Bit Distribution: %.4f\n", e.Analysis.BitDistribution)
+    fmt.Printf("  Hamming Weight: %d\n", e.Analysis.HammingWeight)
+    fmt.Printf("  Avalanche Score: %.4f\n", e.Analysis.AvalancheScore)
+    for _, test := range e.Analysis.TestResults.WeakKeyTests {
+        fmt.Printf("  Weak key check %s: %v\n", test.Pattern, test.Passed)
+    }
+}
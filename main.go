@@ -4,9 +4,13 @@ import (
     "primer/constants"
     "flag"
     "fmt"
+    "io"
     "os"
+    "os/signal"
     "encoding/json"
+    "sort"
     "strings"
+    "text/tabwriter"
 )
 
 type OutputFormat string
@@ -25,36 +29,199 @@ type Options struct {
     OutputFile   string
     QuickTest    bool
     CompareWith  string
+    Seed         string
+    Mode         string
+    Overrides    settingsFlag
+    Checkpoint   string
+    Resume       string
+    Stream       string
+    Out          io.Writer
+}
+
+// output returns where the report goes, standard output unless set.
+func (o Options) output() io.Writer {
+    if o.Out == nil {
+        return os.Stdout
+    }
+    return o.Out
+}
+
+type command struct {
+    name    string
+    summary string
+    run     func(args []string)
+}
+
+var commands = []command{
+    {"generate", "Generate and select new P and Q constants", runGenerate},
+    {"analyze", "Score a single constant through every test", runAnalyze},
+    {"verify", "Re-check a saved result", runVerify},
+    {"compare", "Compare saved results side by side", runCompare},
+    {"report", "Render a saved result", runReport},
+    {"explain", "Trace a constant back to an irrational expansion", runExplain},
+    {"config", "Print the configuration schema or validate config files", runConfig},
 }
 
 func main() {
-    opts := parseFlags()
+    args := os.Args[1:]
+
+    // Without a subcommand the flags belong to generate
+    if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+        if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+            usage()
+            return
+        }
+        runGenerate(args)
+        return
+    }
+
+    if args[0] == "help" {
+        if len(args) > 1 {
+            if cmd := findCommand(args[1]); cmd != nil {
+                cmd.run([]string{"-h"})
+                return
+            }
+        }
+        usage()
+        return
+    }
+
+    cmd := findCommand(args[0])
+    if cmd == nil {
+        fmt.Fprintf(os.Stderr, "Unknown command: %s\n\n", args[0])
+        usage()
+        os.Exit(2)
+    }
+    cmd.run(args[1:])
+}
+
+func findCommand(name string) *command {
+    for i := range commands {
+        if commands[i].name == name {
+            return &commands[i]
+        }
+    }
+    return nil
+}
+
+func usage() {
+    fmt.Fprintf(os.Stderr, "Usage: primer <command> [flags] [arguments]\n\nCommands:\n")
+    for _, cmd := range commands {
+        fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.summary)
+    }
+    fmt.Fprintf(os.Stderr, "\nRun 'primer <command> -h' for the flags of a command. Without a\ncommand, primer runs generate.\n")
+}
+
+// newFlagSet returns a flag set whose help text starts with usage.
+func newFlagSet(name, usage string) *flag.FlagSet {
+    fs := flag.NewFlagSet(name, flag.ExitOnError)
+    fs.Usage = func() {
+        fmt.Fprint(fs.Output(), usage)
+        fs.PrintDefaults()
+    }
+    return fs
+}
 
-    // Load configuration
-    config, err := constants.LoadConfig(opts.ConfigPath)
+const generateUsage = `Usage: primer generate [flags]
+
+Generates candidate constants, scores them and selects the best P and Q.
+Settings come from the defaults, then the -config file (JSON, YAML or TOML),
+then PRIMER_* environment variables, then -set flags.
+
+Flags:
+`
+
+func runGenerate(args []string) {
+    opts := parseFlags(args)
+
+    // -quick goes first so that -set still overrides it
+    if opts.QuickTest {
+        opts.Overrides = append(settingsFlag{"NumCandidates=10", "AvalancheTestCases=100"}, opts.Overrides...)
+    }
+
+    // Load configuration: defaults, file, PRIMER_* environment, -quick, -set
+    config, _, err := constants.LoadConfigLayers(constants.ConfigLayers{
+        Path:      opts.ConfigPath,
+        Env:       os.Environ(),
+        Warn:      warnConfig,
+        Overrides: opts.Overrides,
+    })
     if err != nil {
         fmt.Printf("Error loading configuration: %v\n", err)
         os.Exit(1)
     }
 
-    // Apply quick test modifications if requested
+    if opts.Stream != "" {
+        config.StreamFile = opts.Stream
+    }
+
+    // Streaming to stdout keeps stdout for NDJSON; the report and the log go to stderr
+    opts.Out = os.Stdout
+    if config.StreamFile == constants.StreamStdout {
+        opts.Out = os.Stderr
+    }
+    out := opts.Out
+
+    // A seed on the command line overrides the configuration file
+    if opts.Seed != "" {
+        config.Seed = opts.Seed
+    }
+    if opts.Mode != "" {
+        config.GenerationMode = opts.Mode
+    }
+    if opts.BatchSize != 0 {
+        config.BatchSize = opts.BatchSize
+    }
+    if config.Seed != "" {
+        fmt.Fprintf(out, "Using deterministic HMAC-DRBG seeded with %q\n", config.Seed)
+    }
+
     if opts.QuickTest {
-        config.NumCandidates = 10
-        config.AvalancheTestCases = 100
-        fmt.Println("Running in quick test mode with reduced parameters")
+        fmt.Fprintln(out, "Running in quick test mode with reduced parameters")
+    }
+
+    // A resumed run keeps checkpointing to the file it resumed from
+    if opts.Checkpoint != "" {
+        config.CheckpointFile = opts.Checkpoint
+    } else if opts.Resume != "" && config.CheckpointFile == "" {
+        config.CheckpointFile = opts.Resume
     }
 
     // Create generator
-    generator := constants.NewGenerator(config)
+    generator := constants.NewGenerator(config, constants.WithLogOutput(out))
+
+    if opts.Resume != "" {
+        checkpoint, err := constants.LoadCheckpoint(opts.Resume)
+        if err != nil {
+            fmt.Fprintf(out, "Error loading checkpoint: %v\n", err)
+            os.Exit(1)
+        }
+        if err := generator.Resume(checkpoint); err != nil {
+            fmt.Fprintf(out, "Error resuming from %s: %v\n", opts.Resume, err)
+            os.Exit(1)
+        }
+        fmt.Fprintf(out, "Resuming from %s with %d accepted candidates\n", opts.Resume, len(checkpoint.Candidates))
+    }
+
+    // Stop cleanly on interrupt so the final checkpoint is written
+    interrupt := make(chan os.Signal, 1)
+    signal.Notify(interrupt, os.Interrupt)
+    go func() {
+        <-interrupt
+        generator.Cleanup()
+    }()
 
     // Start timing
     // start := time.Now()
-    fmt.Println("Starting RC6 constant generation and analysis...")
+    fmt.Fprintln(out, "Starting RC6 constant generation and analysis...")
 
     // Generate constants
     result, err := generator.Generate()
     if err != nil {
-        fmt.Printf("Error generating constants: %v\n", err)
+        fmt.Fprintf(out, "Error generating constants: %v\n", err)
+        if _, statErr := os.Stat(config.CheckpointFile); config.CheckpointFile != "" && statErr == nil {
+            fmt.Fprintf(out, "Progress saved; continue with -resume %s\n", config.CheckpointFile)
+        }
         os.Exit(1)
     }
 
@@ -63,22 +230,29 @@ func main() {
 
     // Compare with existing constants if requested
     if opts.CompareWith != "" {
-        compareWithExisting(result, opts.CompareWith)
+        compareWithExisting(out, result, opts.CompareWith)
     }
 }
 
-func parseFlags() Options {
+func parseFlags(args []string) Options {
     opts := Options{}
-
-    flag.StringVar(&opts.ConfigPath, "config", "", "Path to configuration file")
-    flag.Var((*outputFormatFlag)(&opts.OutputFormat), "format", "Output format (text, json, csv)")
-    flag.BoolVar(&opts.Verbose, "verbose", false, "Enable verbose output")
-    flag.IntVar(&opts.BatchSize, "batch", 100, "Batch size for processing")
-    flag.StringVar(&opts.OutputFile, "output", "", "Output file path")
-    flag.BoolVar(&opts.QuickTest, "quick", false, "Run quick test with reduced parameters")
-    flag.StringVar(&opts.CompareWith, "compare", "", "Compare with existing constants file")
-
-    flag.Parse()
+    fs := newFlagSet("generate", generateUsage)
+
+    fs.StringVar(&opts.ConfigPath, "config", "", "Path to configuration file")
+    fs.Var((*outputFormatFlag)(&opts.OutputFormat), "format", "Output format (text, json, csv)")
+    fs.BoolVar(&opts.Verbose, "verbose", false, "Enable verbose output")
+    fs.IntVar(&opts.BatchSize, "batch", 0, "Consecutive candidates per worker batch (default: from config)")
+    fs.StringVar(&opts.OutputFile, "output", "", "Output file path")
+    fs.BoolVar(&opts.QuickTest, "quick", false, "Run quick test with reduced parameters (-set still overrides them)")
+    fs.StringVar(&opts.CompareWith, "compare", "", "Compare with existing constants file")
+    fs.StringVar(&opts.Seed, "seed", "", "Seed for reproducible generation (default: crypto/rand)")
+    fs.StringVar(&opts.Mode, "mode", "", "Generation mode (random, derive)")
+    fs.Var(&opts.Overrides, "set", "Override a setting as Key=Value (repeatable, dotted keys for sections)")
+    fs.StringVar(&opts.Checkpoint, "checkpoint", "", "Periodically save progress to this file (default: from config)")
+    fs.StringVar(&opts.Resume, "resume", "", "Continue an interrupted run from this checkpoint file")
+    fs.StringVar(&opts.Stream, "stream", "", "Write every candidate as NDJSON to this file, or - for stdout (default: from config)")
+
+    fs.Parse(args)
 
     // Set defaults
     if opts.OutputFormat == "" {
@@ -100,56 +274,178 @@ func outputResults(result *constants.GenerationResult, opts Options) {
 }
 
 func outputText(result *constants.GenerationResult, opts Options) {
-    fmt.Printf("\nGeneration completed in %v\n", result.Duration)
-    fmt.Printf("\nSelected Constants:\n")
-    fmt.Printf("P: 0x%X\n", result.SelectedP.Value)
-    fmt.Printf("Q: 0x%X\n", result.SelectedQ.Value)
+    out := opts.output()
+    fmt.Fprintf(out, "\nGeneration completed in %v\n", result.Duration)
+    fmt.Fprintf(out, "\nSelected Constants (%d-bit words):\n", result.Config.WordSize)
+    for _, c := range result.Constants() {
+        fmt.Fprintf(out, "%s: 0x%X%s\n", c.Name, c.Candidate.Value, formatDerivation(c.Candidate))
+    }
+
+    if len(result.Alternates) > 0 {
+        fmt.Fprintf(out, "\nAlternates:\n")
+        for _, alt := range result.Alternates {
+            front := ""
+            if alt.Front > 0 {
+                front = fmt.Sprintf(" front %d", alt.Front)
+            }
+            fmt.Fprintf(out, "  #%d 0x%X score %.4f%s%s\n", alt.Rank, alt.Candidate.Value, alt.Score, front, formatDerivation(alt.Candidate))
+        }
+    }
+
+    if len(result.PairAnalysis) > 0 {
+        fmt.Fprintf(out, "\nPair Analysis:\n")
+        for _, p := range result.PairAnalysis {
+            fmt.Fprintf(out, "  %s,%s: correlation %.4f, output difference %.4f, joint avalanche %.4f\n",
+                p.First, p.Second, p.Correlation, p.OutputDifference, p.JointAvalanche)
+            fmt.Fprintf(out, "    %s-%s = 0x%X, %s/%s = 0x%X\n", p.First, p.Second, p.Difference, p.First, p.Second, p.Ratio)
+        }
+    }
+
+    if len(result.ParetoFront) > 0 {
+        fmt.Fprintf(out, "\nPareto Front: %d of %d candidates (tie-breakers: %s)\n",
+            len(result.ParetoFront), result.TotalCandidates, strings.Join(result.Config.ParetoTieBreakers, ", "))
+        if opts.Verbose {
+            printParetoFront(out, result.ParetoFront)
+        }
+    }
 
     if opts.Verbose {
-        fmt.Printf("\nDetailed Analysis:\n")
-        fmt.Printf("P Constant:\n")
-        printConstantAnalysis(result.SelectedP)
-        fmt.Printf("\nQ Constant:\n")
-        printConstantAnalysis(result.SelectedQ)
+        fmt.Fprintf(out, "\nDetailed Analysis:\n")
+        for i, c := range result.Constants() {
+            if i > 0 {
+                fmt.Fprintln(out)
+            }
+            fmt.Fprintf(out, "%s Constant:\n", c.Name)
+            printConstantAnalysis(out, c.Candidate)
+        }
     }
 
-    fmt.Printf("\nStatistical Test Results:\n")
-    printStatisticalSummary(result)
+    fmt.Fprintf(out, "\nStatistical Test Results:\n")
+    printStatisticalSummary(out, result)
 
     if opts.OutputFile != "" {
-        fmt.Printf("\nDetailed results saved to: %s\n", opts.OutputFile)
+        fmt.Fprintf(out, "\nDetailed results saved to: %s\n", opts.OutputFile)
     }
 }
 
-func printConstantAnalysis(c constants.ConstantCandidate) {
-    fmt.Printf("  Value: 0x%X\n", c.Value)
-    fmt.Printf("  Bit Distribution: %.4f\n", c.BitDistribution)
-    fmt.Printf("  Avalanche Score: %.4f\n", c.AvalancheScore)
-    fmt.Printf("  Entropy Score: %.4f\n", c.EntropyScore)
-    fmt.Printf("  Hamming Weight: %d\n", c.HammingWeight)
+func printParetoFront(w io.Writer, front []constants.ParetoPoint) {
+    var names []string
+    for name := range front[0].Metrics {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+
+    tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+    fmt.Fprintf(tw, "  Value\tScore\t%s\tSelected\n", strings.Join(names, "\t"))
+    for _, p := range front {
+        cells := make([]string, len(names))
+        for i, name := range names {
+            cells[i] = fmt.Sprintf("%.4f", p.Metrics[name])
+        }
+        fmt.Fprintf(tw, "  0x%X\t%.4f\t%s\t%s\n", p.Value, p.Score, strings.Join(cells, "\t"), p.Selected)
+    }
+    tw.Flush()
+}
+
+func formatDerivation(c constants.ConstantCandidate) string {
+    if c.Derivation == nil {
+        return ""
+    }
+    return " = " + c.Derivation.Formula
+}
+
+func printConstantAnalysis(w io.Writer, c constants.ConstantCandidate) {
+    fmt.Fprintf(w, "  Value: 0x%X\n", c.Value)
+    fmt.Fprintf(w, "  Word Size: %d bits\n", c.WordSize)
+    if c.Derivation != nil {
+        fmt.Fprintf(w, "  Derivation: %s\n", c.Derivation.Formula)
+    }
+    fmt.Fprintf(w, "  Bit Distribution: %.4f\n", c.BitDistribution)
+    fmt.Fprintf(w, "  Avalanche Score: %.4f\n", c.AvalancheScore)
+    fmt.Fprintf(w, "  Entropy Score: %.4f\n", c.EntropyScore)
+    fmt.Fprintf(w, "  Hamming Weight: %d\n", c.HammingWeight)
+    if c.MaxDifferentialProbability > 0 {
+        fmt.Fprintf(w, "  Max Differential Probability: %.4f\n", c.MaxDifferentialProbability)
+        fmt.Fprintf(w, "  Max Linear Bias: %.4f\n", c.MaxLinearBias)
+    }
     
     if len(c.TestResults.StatisticalTests) > 0 {
-        fmt.Printf("  Statistical Tests:\n")
+        fmt.Fprintf(w, "  Statistical Tests:\n")
         for _, test := range c.TestResults.StatisticalTests {
-            fmt.Printf("    %s: %.4f (%v)\n", test.Name, test.Score, test.Passed)
+            fmt.Fprintf(w, "    %s: %.4f, p-value %.4f (%v)\n", test.Name, test.Score, test.PValue, test.Passed)
             if test.Details != "" {
-                fmt.Printf("      %s\n", test.Details)
+                fmt.Fprintf(w, "      %s\n", test.Details)
             }
         }
     }
+    if a := c.TestResults.Aggregate; a != nil {
+        fmt.Fprintf(w, "  Aggregate (%s, alpha %.4g): %v, %s\n", a.Method, a.Alpha, a.Passed, a.Details)
+        if len(a.Rejected) > 0 {
+            fmt.Fprintf(w, "    Rejected: %s\n", strings.Join(a.Rejected, ", "))
+        }
+    }
+
+    if len(c.TestResults.KeyScheduleTests) > 0 {
+        fmt.Fprintf(w, "  Key Schedule Tests:\n")
+        for _, test := range c.TestResults.KeyScheduleTests {
+            fmt.Fprintf(w, "    %s: %.4f (%v)\n", test.Name, test.Score, test.Passed)
+            fmt.Fprintf(w, "      %s\n", test.Details)
+        }
+    }
+
+    if r := c.TestResults.NIST; r != nil {
+        printNISTReport(w, r)
+    }
+}
+
+func printNISTReport(w io.Writer, r *constants.NISTReport) {
+    passed := 0
+    for _, test := range r.Tests {
+        if test.Passed {
+            passed++
+        }
+    }
+    fmt.Fprintf(w, "  NIST SP 800-22 (%s, %d sequences of %d bits, alpha %.4g): %d of %d statistics passed\n",
+        r.Transform, r.Sequences, r.StreamBits, r.Alpha, passed, len(r.Tests))
+    for _, test := range r.Tests {
+        if !test.Passed {
+            fmt.Fprintf(w, "    FAILED %s: proportion %.4f (min %.4f), uniformity %.6f (Kolmogorov-Smirnov %.6f)\n",
+                test.Name, test.Proportion, test.MinProportion, test.Uniformity, test.UniformityKS)
+        }
+    }
 }
 
-func printStatisticalSummary(result *constants.GenerationResult) {
-    fmt.Printf("\nOverall Statistical Analysis:\n")
-    fmt.Printf("Total Candidates Tested: %d\n", result.TotalCandidates)
-    fmt.Printf("Generation Time: %v\n", result.Duration)
+func printStatisticalSummary(w io.Writer, result *constants.GenerationResult) {
+    fmt.Fprintf(w, "\nOverall Statistical Analysis:\n")
+    fmt.Fprintf(w, "Total Candidates Tested: %d\n", result.TotalCandidates)
+    fmt.Fprintf(w, "Generation Time: %v\n", result.Duration)
+    printFunnel(w, result.Funnel)
     
     // Calculate and display overall scores
-    pScore := calculateOverallScore(result.SelectedP)
-    qScore := calculateOverallScore(result.SelectedQ)
-    
-    fmt.Printf("P Constant Overall Score: %.4f\n", pScore)
-    fmt.Printf("Q Constant Overall Score: %.4f\n", qScore)
+    for _, c := range result.Constants() {
+        fmt.Fprintf(w, "%s Constant Overall Score: %.4f\n", c.Name, calculateOverallScore(c.Candidate))
+    }
+}
+
+func printFunnel(w io.Writer, f constants.RejectionFunnel) {
+    if f.Generated == 0 {
+        return
+    }
+    fmt.Fprintf(w, "\nRejection Funnel:\n")
+    fmt.Fprintf(w, "  %-36s %9s %9s\n", "Criterion", "Rejected", "Remaining")
+    fmt.Fprintf(w, "  %-36s %9s %9d\n", "generated", "", f.Generated)
+    for _, stage := range f.Stages {
+        fmt.Fprintf(w, "  %-36s %9d %9d\n", stage.Reason, stage.Rejected, stage.Remaining)
+    }
+    fmt.Fprintf(w, "  %-36s %9s %9d\n", "accepted", "", f.Accepted)
+
+    if len(f.Workers) > 1 {
+        fmt.Fprintf(w, "Per worker (generated/accepted):")
+        for _, worker := range f.Workers {
+            fmt.Fprintf(w, " %d:%d/%d", worker.Worker, worker.Generated, worker.Accepted)
+        }
+        fmt.Fprintln(w)
+    }
 }
 
 func calculateOverallScore(c constants.ConstantCandidate) float64 {
@@ -178,86 +474,96 @@ func calculateOverallScore(c constants.ConstantCandidate) float64 {
 }
 
 func outputJSON(result *constants.GenerationResult, opts Options) {
+    out := opts.output()
     output, err := json.MarshalIndent(result, "", "  ")
     if err != nil {
-        fmt.Printf("Error generating JSON output: %v\n", err)
+        fmt.Fprintf(out, "Error generating JSON output: %v\n", err)
         return
     }
 
     if opts.OutputFile != "" {
         err = os.WriteFile(opts.OutputFile, output, 0644)
         if err != nil {
-            fmt.Printf("Error writing to output file: %v\n", err)
+            fmt.Fprintf(out, "Error writing to output file: %v\n", err)
             return
         }
     } else {
-        fmt.Println(string(output))
+        fmt.Fprintln(out, string(output))
     }
 }
 
 func outputCSV(result *constants.GenerationResult, opts Options) {
+    out := opts.output()
     var builder strings.Builder
 
     // Write header
-    builder.WriteString("Constant,Value,BitDistribution,AvalancheScore,EntropyScore,HammingWeight\n")
-
-    // Write P constant
-    builder.WriteString(fmt.Sprintf("P,0x%X,%.4f,%.4f,%.4f,%d\n",
-        result.SelectedP.Value,
-        result.SelectedP.BitDistribution,
-        result.SelectedP.AvalancheScore,
-        result.SelectedP.EntropyScore,
-        result.SelectedP.HammingWeight))
-
-    // Write Q constant
-    builder.WriteString(fmt.Sprintf("Q,0x%X,%.4f,%.4f,%.4f,%d\n",
-        result.SelectedQ.Value,
-        result.SelectedQ.BitDistribution,
-        result.SelectedQ.AvalancheScore,
-        result.SelectedQ.EntropyScore,
-        result.SelectedQ.HammingWeight))
+    builder.WriteString("Constant,Value,WordSize,BitDistribution,AvalancheScore,EntropyScore,HammingWeight,Derivation\n")
+
+    // Write one row per selected constant
+    for _, c := range result.Constants() {
+        builder.WriteString(fmt.Sprintf("%s,0x%X,%d,%.4f,%.4f,%.4f,%d,%s\n",
+            c.Name,
+            c.Candidate.Value,
+            c.Candidate.WordSize,
+            c.Candidate.BitDistribution,
+            c.Candidate.AvalancheScore,
+            c.Candidate.EntropyScore,
+            c.Candidate.HammingWeight,
+            csvDerivation(c.Candidate)))
+    }
 
     output := builder.String()
     if opts.OutputFile != "" {
         err := os.WriteFile(opts.OutputFile, []byte(output), 0644)
         if err != nil {
-            fmt.Printf("Error writing to output file: %v\n", err)
+            fmt.Fprintf(out, "Error writing to output file: %v\n", err)
             return
         }
     } else {
-        fmt.Print(output)
+        fmt.Fprint(out, output)
     }
 }
 
-func compareWithExisting(result *constants.GenerationResult, comparePath string) {
-    fmt.Println("\nComparing with existing constants:")
-    
-    // Read existing constants file
-    data, err := os.ReadFile(comparePath)
+func csvDerivation(c constants.ConstantCandidate) string {
+    if c.Derivation == nil {
+        return ""
+    }
+    return `"` + strings.ReplaceAll(c.Derivation.Formula, `"`, `""`) + `"`
+}
+
+func compareWithExisting(w io.Writer, result *constants.GenerationResult, comparePath string) {
+    fmt.Fprintln(w, "\nComparing with existing constants:")
+
+    existing, err := constants.LoadResult(comparePath)
     if err != nil {
-        fmt.Printf("Error reading comparison file: %v\n", err)
+        fmt.Fprintf(w, "Error reading comparison file: %v\n", err)
         return
     }
 
-    var existing constants.GenerationResult
-    if err := json.Unmarshal(data, &existing); err != nil {
-        fmt.Printf("Error parsing comparison file: %v\n", err)
-        return
+    printComparison(w, []resultSummary{
+        summarizeResult("New", result),
+        summarizeResult("Existing", existing),
+    })
+}
+
+// settingsFlag collects repeated -set Key=Value flags.
+type settingsFlag []string
+
+func (f *settingsFlag) String() string {
+    return strings.Join(*f, ", ")
+}
+
+func (f *settingsFlag) Set(value string) error {
+    if !strings.Contains(value, "=") {
+        return fmt.Errorf("expected Key=Value, got %q", value)
     }
+    *f = append(*f, value)
+    return nil
+}
 
-    // Compare and display results
-    fmt.Printf("\nExisting Constants:\n")
-    fmt.Printf("P: 0x%X\n", existing.SelectedP.Value)
-    fmt.Printf("Q: 0x%X\n", existing.SelectedQ.Value)
-    
-    fmt.Printf("\nStatistical Comparison:\n")
-    fmt.Printf("                    New         Existing\n")
-    fmt.Printf("P Avalanche Score: %.4f vs %.4f\n",
-        result.SelectedP.AvalancheScore,
-        existing.SelectedP.AvalancheScore)
-    fmt.Printf("Q Avalanche Score: %.4f vs %.4f\n",
-        result.SelectedQ.AvalancheScore,
-        existing.SelectedQ.AvalancheScore)
+// warnConfig reports a configuration problem that does not stop loading.
+func warnConfig(msg string) {
+    fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
 }
 
 // Custom flag type for output format
@@ -0,0 +1,165 @@
+// Package nist implements the statistical test suite of NIST SP 800-22
+// Rev. 1a, "A Statistical Test Suite for Random and Pseudorandom Number
+// Generators for Cryptographic Applications": the fifteen tests with the
+// parameters the publication recommends, and the second-level proportion
+// and uniformity analysis over many sequences.
+//
+// Sequences are given one bit per byte, each byte 0 or 1.
+package nist
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"primer/special"
+)
+
+// ErrNotApplicable is wrapped by the errors of tests whose preconditions a
+// sequence does not meet, such as a minimum length.
+var ErrNotApplicable = errors.New("test not applicable")
+
+// Result is one p-value of a test on one sequence. Tests with several
+// statistics, such as the serial test, each template of the non-overlapping
+// template test and each state of the random excursions tests, report one
+// Result per statistic.
+type Result struct {
+	Name   string
+	PValue float64
+	// Err is set when the test is not applicable to the sequence, in which
+	// case PValue is meaningless.
+	Err error
+}
+
+// Bits unpacks data into one bit per byte, most significant bit first.
+func Bits(data []byte) []byte {
+	eps := make([]byte, 0, 8*len(data))
+	for _, b := range data {
+		for i := 7; i >= 0; i-- {
+			eps = append(eps, b>>uint(i)&1)
+		}
+	}
+	return eps
+}
+
+// Recommended parameters of the tests that take one.
+const (
+	blockFrequencyLength   = 128
+	templateLength         = 9
+	linearComplexityLength = 500
+	serialLength           = 16
+	entropyLength          = 10
+)
+
+// Run applies every test of the battery to eps, in the order of SP 800-22.
+// The serial and approximate entropy pattern lengths are reduced below
+// their recommended values for sequences too short for them.
+func Run(eps []byte) []Result {
+	n := len(eps)
+	logN := bits.Len(uint(n)) - 1
+	var results []Result
+	add := func(name string, p float64, err error) {
+		results = append(results, Result{Name: name, PValue: p, Err: err})
+	}
+
+	add("Frequency", Frequency(eps), nil)
+	p, err := BlockFrequency(eps, blockFrequencyLength)
+	add("BlockFrequency", p, err)
+	add("Runs", Runs(eps), nil)
+	p, err = LongestRun(eps)
+	add("LongestRun", p, err)
+	p, err = Rank(eps)
+	add("Rank", p, err)
+	add("FFT", DFT(eps), nil)
+
+	window := windows(eps, templateLength)
+	for _, b := range AperiodicTemplates(templateLength) {
+		p, err := nonOverlappingTemplate(window, n, templateLength, pack(b), templateBlocks)
+		add("NonOverlappingTemplate "+bitString(b), p, err)
+	}
+	p, err = OverlappingTemplate(eps)
+	add("OverlappingTemplate", p, err)
+	p, err = Universal(eps)
+	add("Universal", p, err)
+	p, err = LinearComplexity(eps, linearComplexityLength)
+	add("LinearComplexity", p, err)
+
+	p1, p2, err := Serial(eps, min(serialLength, logN-3))
+	add("Serial 1", p1, err)
+	add("Serial 2", p2, err)
+	p, err = ApproximateEntropy(eps, min(entropyLength, logN-6))
+	add("ApproximateEntropy", p, err)
+	add("CumulativeSums Forward", CumulativeSums(eps, false), nil)
+	add("CumulativeSums Reverse", CumulativeSums(eps, true), nil)
+
+	excursions, err := RandomExcursions(eps)
+	for i, x := range ExcursionStates {
+		if err != nil {
+			add(fmt.Sprintf("RandomExcursions x=%+d", x), 0, err)
+		} else {
+			add(fmt.Sprintf("RandomExcursions x=%+d", x), excursions[i], nil)
+		}
+	}
+	variant, err := RandomExcursionsVariant(eps)
+	for i, x := range VariantStates {
+		if err != nil {
+			add(fmt.Sprintf("RandomExcursionsVariant x=%+d", x), 0, err)
+		} else {
+			add(fmt.Sprintf("RandomExcursionsVariant x=%+d", x), variant[i], nil)
+		}
+	}
+	return results
+}
+
+func bitString(b []byte) string {
+	s := make([]byte, len(b))
+	for i, bit := range b {
+		s[i] = '0' + bit
+	}
+	return string(s)
+}
+
+// Proportion returns the fraction of pValues at or above alpha, and the
+// lowest fraction acceptable for that many sequences, three standard
+// deviations below 1-alpha.
+func Proportion(pValues []float64, alpha float64) (proportion, minimum float64) {
+	if len(pValues) == 0 {
+		return 0, 0
+	}
+	passed := 0
+	for _, p := range pValues {
+		if p >= alpha {
+			passed++
+		}
+	}
+	s := float64(len(pValues))
+	return float64(passed) / s, 1 - alpha - 3*math.Sqrt(alpha*(1-alpha)/s)
+}
+
+// MinUniformity is the uniformity p-value below which the p-values of a
+// test are considered not uniformly distributed.
+const MinUniformity = 0.0001
+
+// MinUniformitySequences is the number of sequences SP 800-22 recommends
+// before drawing conclusions from the uniformity of p-values.
+const MinUniformitySequences = 55
+
+// Uniformity returns the p-value of a chi-square test that pValues are
+// uniformly distributed over ten equal bins of [0, 1].
+func Uniformity(pValues []float64) float64 {
+	if len(pValues) == 0 {
+		return 0
+	}
+	var bins [10]float64
+	for _, p := range pValues {
+		bins[min(int(p*10), 9)]++
+	}
+	expected := float64(len(pValues)) / 10
+	chiSquared := 0.0
+	for _, count := range bins {
+		d := count - expected
+		chiSquared += d * d / expected
+	}
+	return special.Igamc(9.0/2, chiSquared/2)
+}
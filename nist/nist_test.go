@@ -0,0 +1,241 @@
+package nist
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// piBits are the first 100 bits of the binary expansion of π used by the
+// worked examples of SP 800-22.
+const piBits = "1100100100001111110110101010001000100001011010001100001000110100110001001100011001100010100010111000"
+
+func parseBits(s string) []byte {
+	eps := make([]byte, len(s))
+	for i := range s {
+		eps[i] = s[i] - '0'
+	}
+	return eps
+}
+
+// randomBits returns n pseudorandom bits from SHA-256 in counter mode.
+func randomBits(n int, seed uint64) []byte {
+	var data []byte
+	var block [16]byte
+	binary.BigEndian.PutUint64(block[:8], seed)
+	for i := uint64(0); len(data)*8 < n; i++ {
+		binary.BigEndian.PutUint64(block[8:], i)
+		sum := sha256.Sum256(block[:])
+		data = append(data, sum[:]...)
+	}
+	return Bits(data)[:n]
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-6
+}
+
+// Worked examples of section 2 of SP 800-22 Rev. 1a.
+func TestKnownAnswers(t *testing.T) {
+	mustP := func(p float64, err error) float64 {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"Frequency", Frequency(parseBits("1011010101")), 0.527089},
+		{"Frequency π", Frequency(parseBits(piBits)), 0.109599},
+		{"BlockFrequency", mustP(BlockFrequency(parseBits("0110011010"), 3)), 0.801252},
+		{"BlockFrequency π", mustP(BlockFrequency(parseBits(piBits), 10)), 0.706438},
+		{"Runs", Runs(parseBits("1001101011")), 0.147232},
+		{"Runs π", Runs(parseBits(piBits)), 0.500798},
+		{"LongestRun", mustP(LongestRun(parseBits("11001100000101010110110001001100111000000000001001001101010100010001001111010110100000001101011111001100111001101101100010110010"))), 0.180609},
+		{"NonOverlappingTemplate", mustP(nonOverlappingTemplate(windows(parseBits("10100100101110010110"), 3), 20, 3, 0b001, 2)), 0.344154},
+		{"ApproximateEntropy", mustP(ApproximateEntropy(parseBits("0100110101"), 3)), 0.261961},
+		{"ApproximateEntropy π", mustP(ApproximateEntropy(parseBits(piBits), 2)), 0.235301},
+		{"CumulativeSums", CumulativeSums(parseBits("1011010111"), false), 0.4116588},
+		{"CumulativeSums π forward", CumulativeSums(parseBits(piBits), false), 0.219194},
+		{"CumulativeSums π reverse", CumulativeSums(parseBits(piBits), true), 0.114866},
+		{"RandomExcursions x=+1", randomExcursions(parseBits("0110110101"))[4], 0.502488},
+		{"RandomExcursionsVariant x=+1", randomExcursionsVariant(parseBits("0110110101"))[9], 0.683091},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !near(tt.got, tt.want) {
+				t.Errorf("p-value = %.6f, want %.6f", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSerialKnownAnswer(t *testing.T) {
+	p1, p2, err := Serial(parseBits("0011011101"), 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(p1, 0.808792) || !near(p2, 0.670320) {
+		t.Errorf("Serial = %.6f, %.6f, want 0.808792, 0.670320", p1, p2)
+	}
+}
+
+func TestBerlekampMassey(t *testing.T) {
+	tests := []struct {
+		bits string
+		want int
+	}{
+		{"1101011110001", 4},
+		{"0000000000", 0},
+		{"0000000001", 10},
+		{"1111111111", 1},
+		{"1010101010", 2},
+	}
+	for _, tt := range tests {
+		if got := BerlekampMassey(parseBits(tt.bits)); got != tt.want {
+			t.Errorf("BerlekampMassey(%s) = %d, want %d", tt.bits, got, tt.want)
+		}
+	}
+}
+
+func TestAperiodicTemplates(t *testing.T) {
+	for m, want := range map[int]int{2: 2, 3: 4, 9: 148} {
+		if got := len(AperiodicTemplates(m)); got != want {
+			t.Errorf("len(AperiodicTemplates(%d)) = %d, want %d", m, got, want)
+		}
+	}
+	if got := bitString(AperiodicTemplates(9)[0]); got != "000000001" {
+		t.Errorf("first template = %s", got)
+	}
+}
+
+func TestProbabilities(t *testing.T) {
+	// Values tabulated in SP 800-22
+	if p := rankProbability(32); math.Abs(p-0.2888) > 1e-4 {
+		t.Errorf("P(rank 32) = %.4f, want 0.2888", p)
+	}
+	if p := rankProbability(31); math.Abs(p-0.5776) > 1e-4 {
+		t.Errorf("P(rank 31) = %.4f, want 0.5776", p)
+	}
+	want := []float64{0.8750000000, 0.01562500000, 0.01367187500, 0.01196289063, 0.01046752930, 0.0732727051}
+	for k, p := range excursionPi(4) {
+		if math.Abs(p-want[k]) > 1e-9 {
+			t.Errorf("π_%d(4) = %.10f, want %.10f", k, p, want[k])
+		}
+	}
+
+	for name, pi := range map[string][]float64{
+		"overlapping": overlappingPi, "linear complexity": linearComplexityPi,
+		"longest run 8": longestRunClasses[2].pi, "longest run 10000": longestRunClasses[0].pi,
+	} {
+		sum := 0.0
+		for _, p := range pi {
+			sum += p
+		}
+		if math.Abs(sum-1) > 1e-3 {
+			t.Errorf("%s probabilities sum to %v", name, sum)
+		}
+	}
+}
+
+func TestFourierMatchesNaiveDFT(t *testing.T) {
+	for _, n := range []int{8, 12, 100} {
+		eps := randomBits(n, uint64(n))
+		x := make([]complex128, n)
+		for i, b := range eps {
+			x[i] = complex(float64(2*int(b)-1), 0)
+		}
+		fourier(x)
+		for k := 0; k < n; k++ {
+			var want complex128
+			for j, b := range eps {
+				angle := -2 * math.Pi * float64(j*k) / float64(n)
+				want += complex(float64(2*int(b)-1)*math.Cos(angle), float64(2*int(b)-1)*math.Sin(angle))
+			}
+			if d := x[k] - want; math.Hypot(real(d), imag(d)) > 1e-9 {
+				t.Fatalf("n=%d: X[%d] = %v, want %v", n, k, x[k], want)
+			}
+		}
+	}
+}
+
+func TestRunOnRandomSequence(t *testing.T) {
+	eps := randomBits(1<<20, 1)
+	results := Run(eps)
+	if len(results) != 188 {
+		t.Errorf("len(Run) = %d, want 188", len(results))
+	}
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			if !errors.Is(r.Err, ErrNotApplicable) || !strings.HasPrefix(r.Name, "RandomExcursions") {
+				t.Errorf("%s: %v", r.Name, r.Err)
+			}
+			continue
+		}
+		if r.PValue < 0 || r.PValue > 1 {
+			t.Errorf("%s: p-value %v out of range", r.Name, r.PValue)
+		}
+		if r.PValue < 0.001 {
+			failed++
+		}
+	}
+	if failed > 2 {
+		t.Errorf("%d p-values below 0.001 for a good generator", failed)
+	}
+}
+
+func TestRunDetectsBias(t *testing.T) {
+	eps := randomBits(1<<16, 2)
+	for i := 0; i < len(eps); i += 3 {
+		eps[i] = 1
+	}
+	for _, r := range Run(eps) {
+		if r.Name == "Frequency" && r.PValue >= 0.01 {
+			t.Errorf("Frequency p-value = %v for a biased sequence", r.PValue)
+		}
+	}
+}
+
+// The DFT examples of SP 800-22 predate the corrected threshold, so the
+// test is checked on a periodic sequence instead.
+func TestDFTDetectsPeriodicity(t *testing.T) {
+	random := randomBits(1<<14, 3)
+	periodic := make([]byte, len(random))
+	copy(periodic, random)
+	for i := 64; i < len(periodic); i++ {
+		periodic[i] = periodic[i%64]
+	}
+	if p := DFT(random); p < 0.01 {
+		t.Errorf("DFT(random) = %v", p)
+	}
+	if p := DFT(periodic); p >= 0.01 {
+		t.Errorf("DFT(periodic) = %v", p)
+	}
+}
+
+func TestProportionAndUniformity(t *testing.T) {
+	pValues := []float64{0.05, 0.15, 0.25, 0.35, 0.45, 0.55, 0.65, 0.75, 0.85, 0.005}
+	proportion, minimum := Proportion(pValues, 0.01)
+	if proportion != 0.9 {
+		t.Errorf("proportion = %v, want 0.9", proportion)
+	}
+	if want := 0.99 - 3*math.Sqrt(0.01*0.99/10); !near(minimum, want) {
+		t.Errorf("minimum = %v, want %v", minimum, want)
+	}
+	if u := Uniformity([]float64{0.05, 0.15, 0.25, 0.35, 0.45, 0.55, 0.65, 0.75, 0.85, 0.95}); !near(u, 1) {
+		t.Errorf("Uniformity(one per bin) = %v, want 1", u)
+	}
+	if u := Uniformity([]float64{0.01, 0.02, 0.03, 0.04, 0.05, 0.06, 0.07, 0.08, 0.09, 0.001}); u >= MinUniformity {
+		t.Errorf("Uniformity(all in one bin) = %v", u)
+	}
+}
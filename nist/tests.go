@@ -0,0 +1,738 @@
+package nist
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"math/cmplx"
+
+	"primer/special"
+)
+
+// Frequency is the frequency (monobit) test.
+func Frequency(eps []byte) float64 {
+	n := len(eps)
+	sum := 0
+	for _, b := range eps {
+		sum += 2*int(b) - 1
+	}
+	sObs := math.Abs(float64(sum)) / math.Sqrt(float64(n))
+	return special.Erfc(sObs / math.Sqrt2)
+}
+
+// BlockFrequency is the frequency test within blocks of m bits.
+func BlockFrequency(eps []byte, m int) (float64, error) {
+	blocks := len(eps) / m
+	if m < 1 || blocks < 1 {
+		return 0, fmt.Errorf("%w: block frequency needs at least one block of %d bits", ErrNotApplicable, m)
+	}
+	sum := 0.0
+	for i := 0; i < blocks; i++ {
+		ones := 0
+		for _, b := range eps[i*m : (i+1)*m] {
+			ones += int(b)
+		}
+		v := float64(ones)/float64(m) - 0.5
+		sum += v * v
+	}
+	chiSquared := 4 * float64(m) * sum
+	return special.Igamc(float64(blocks)/2, chiSquared/2), nil
+}
+
+// Runs is the runs test. Its prerequisite frequency test failing yields a
+// p-value of zero, as in the reference implementation.
+func Runs(eps []byte) float64 {
+	n := float64(len(eps))
+	ones := 0
+	for _, b := range eps {
+		ones += int(b)
+	}
+	pi := float64(ones) / n
+	if math.Abs(pi-0.5) >= 2/math.Sqrt(n) {
+		return 0
+	}
+
+	vObs := 1
+	for k := 1; k < len(eps); k++ {
+		if eps[k] != eps[k-1] {
+			vObs++
+		}
+	}
+	num := math.Abs(float64(vObs) - 2*n*pi*(1-pi))
+	den := 2 * math.Sqrt(2*n) * pi * (1 - pi)
+	return special.Erfc(num / den)
+}
+
+// longestRunClasses are the block lengths, the class boundaries and the
+// class probabilities of the longest-run-of-ones test.
+var longestRunClasses = []struct {
+	minBits  int
+	m        int
+	min, max int
+	pi       []float64
+}{
+	{750000, 10000, 10, 16, []float64{0.0882, 0.2092, 0.2483, 0.1933, 0.1208, 0.0675, 0.0727}},
+	{6272, 128, 4, 9, []float64{0.1174035788, 0.242955959, 0.249363483, 0.17517706, 0.102701071, 0.112398847}},
+	{128, 8, 1, 4, []float64{0.21484375, 0.3671875, 0.23046875, 0.1875}},
+}
+
+// LongestRun is the test for the longest run of ones in a block. The block
+// length is 8, 128 or 10000 bits depending on the sequence length.
+func LongestRun(eps []byte) (float64, error) {
+	n := len(eps)
+	for _, class := range longestRunClasses {
+		if n < class.minBits {
+			continue
+		}
+		blocks := n / class.m
+		nu := make([]float64, len(class.pi))
+		for i := 0; i < blocks; i++ {
+			longest, run := 0, 0
+			for _, b := range eps[i*class.m : (i+1)*class.m] {
+				if b == 1 {
+					run++
+					longest = max(longest, run)
+				} else {
+					run = 0
+				}
+			}
+			longest = min(max(longest, class.min), class.max)
+			nu[longest-class.min]++
+		}
+		return special.Igamc(float64(len(class.pi)-1)/2, chiSquared(nu, class.pi, float64(blocks))/2), nil
+	}
+	return 0, fmt.Errorf("%w: longest run needs at least 128 bits", ErrNotApplicable)
+}
+
+// rankSize is the dimension of the matrices of the rank test.
+const rankSize = 32
+
+// Rank is the binary matrix rank test over 32x32 matrices.
+func Rank(eps []byte) (float64, error) {
+	blocks := len(eps) / (rankSize * rankSize)
+	if blocks < 38 {
+		return 0, fmt.Errorf("%w: rank needs at least 38 matrices", ErrNotApplicable)
+	}
+
+	full, fullMinusOne := 0, 0
+	var rows [rankSize]uint32
+	for k := 0; k < blocks; k++ {
+		block := eps[k*rankSize*rankSize:]
+		for i := range rows {
+			rows[i] = 0
+			for j := 0; j < rankSize; j++ {
+				rows[i] = rows[i]<<1 | uint32(block[i*rankSize+j])
+			}
+		}
+		switch binaryRank(rows[:]) {
+		case rankSize:
+			full++
+		case rankSize - 1:
+			fullMinusOne++
+		}
+	}
+
+	pFull := rankProbability(rankSize)
+	pFullMinusOne := rankProbability(rankSize - 1)
+	nu := []float64{float64(full), float64(fullMinusOne), float64(blocks - full - fullMinusOne)}
+	pi := []float64{pFull, pFullMinusOne, 1 - pFull - pFullMinusOne}
+	return math.Exp(-chiSquared(nu, pi, float64(blocks)) / 2), nil
+}
+
+// binaryRank returns the rank over GF(2) of the matrix with the given rows.
+// It clobbers rows.
+func binaryRank(rows []uint32) int {
+	rank := 0
+	for col := 31; col >= 0 && rank < len(rows); col-- {
+		bit := uint32(1) << uint(col)
+		pivot := -1
+		for i := rank; i < len(rows); i++ {
+			if rows[i]&bit != 0 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+		rows[rank], rows[pivot] = rows[pivot], rows[rank]
+		for i := range rows {
+			if i != rank && rows[i]&bit != 0 {
+				rows[i] ^= rows[rank]
+			}
+		}
+		rank++
+	}
+	return rank
+}
+
+// rankProbability is the probability that a random 32x32 binary matrix has
+// rank r.
+func rankProbability(r int) float64 {
+	product := 1.0
+	for i := 0; i < r; i++ {
+		f := 1 - math.Pow(2, float64(i-rankSize))
+		product *= f * f / (1 - math.Pow(2, float64(i-r)))
+	}
+	return math.Pow(2, float64(r*(2*rankSize-r)-rankSize*rankSize)) * product
+}
+
+// DFT is the discrete Fourier transform (spectral) test.
+func DFT(eps []byte) float64 {
+	n := len(eps)
+	x := make([]complex128, n)
+	for i, b := range eps {
+		x[i] = complex(float64(2*int(b)-1), 0)
+	}
+	fourier(x)
+
+	threshold := math.Sqrt(math.Log(1/0.05) * float64(n))
+	below := 0
+	for _, v := range x[:n/2] {
+		if cmplx.Abs(v) < threshold {
+			below++
+		}
+	}
+	n0 := 0.95 * float64(n) / 2
+	d := (float64(below) - n0) / math.Sqrt(float64(n)*0.95*0.05/4)
+	return special.Erfc(math.Abs(d) / math.Sqrt2)
+}
+
+// fourier replaces x by its discrete Fourier transform. Lengths that are
+// not a power of two go through Bluestein's chirp-z algorithm.
+func fourier(x []complex128) {
+	n := len(x)
+	if n&(n-1) == 0 {
+		fft(x)
+		return
+	}
+
+	size := 1 << bits.Len(uint(2*n-1))
+	chirp := make([]complex128, n)
+	for k := range chirp {
+		// k² mod 2n keeps the angle exact for long sequences
+		kk := uint64(k) * uint64(k) % uint64(2*n)
+		chirp[k] = cmplx.Exp(complex(0, -math.Pi*float64(kk)/float64(n)))
+	}
+	a := make([]complex128, size)
+	b := make([]complex128, size)
+	for k := 0; k < n; k++ {
+		a[k] = x[k] * chirp[k]
+		b[k] = cmplx.Conj(chirp[k])
+		if k > 0 {
+			b[size-k] = b[k]
+		}
+	}
+	fft(a)
+	fft(b)
+	for i := range a {
+		a[i] = cmplx.Conj(a[i] * b[i])
+	}
+	fft(a) // inverse transform via conjugation
+	for k := range x {
+		x[k] = cmplx.Conj(a[k]) / complex(float64(size), 0) * chirp[k]
+	}
+}
+
+// fft is an in-place radix-2 fast Fourier transform. len(x) must be a
+// power of two.
+func fft(x []complex128) {
+	n := len(x)
+	shift := 64 - uint(bits.Len(uint(n))-1)
+	for i := range x {
+		j := int(bits.Reverse64(uint64(i)) >> shift)
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	twiddle := make([]complex128, n/2)
+	for k := range twiddle {
+		s, c := math.Sincos(-2 * math.Pi * float64(k) / float64(n))
+		twiddle[k] = complex(c, s)
+	}
+	for size := 2; size <= n; size <<= 1 {
+		half, step := size/2, n/size
+		for start := 0; start < n; start += size {
+			for k := 0; k < half; k++ {
+				t := twiddle[k*step] * x[start+k+half]
+				x[start+k+half] = x[start+k] - t
+				x[start+k] += t
+			}
+		}
+	}
+}
+
+// templateBlocks is the number of blocks of the non-overlapping template
+// matching test.
+const templateBlocks = 8
+
+// NonOverlappingTemplate is the non-overlapping template matching test for
+// template b, given as bits.
+func NonOverlappingTemplate(eps, b []byte) (float64, error) {
+	return nonOverlappingTemplate(windows(eps, len(b)), len(eps), len(b), pack(b), templateBlocks)
+}
+
+// nonOverlappingTemplate runs the test on the m-bit windows of a sequence
+// of n bits split into the given number of blocks, so that the windows are
+// computed once for every template.
+func nonOverlappingTemplate(window []uint32, n, m int, template uint32, blocks int) (float64, error) {
+	blockLen := n / blocks
+	if blockLen < m {
+		return 0, fmt.Errorf("%w: non-overlapping template needs blocks of at least %d bits", ErrNotApplicable, m)
+	}
+	mu := float64(blockLen-m+1) / math.Pow(2, float64(m))
+	variance := float64(blockLen) * (1/math.Pow(2, float64(m)) - float64(2*m-1)/math.Pow(2, float64(2*m)))
+
+	chiSquared := 0.0
+	for j := 0; j < blocks; j++ {
+		block := window[j*blockLen : j*blockLen+blockLen-m+1]
+		matches := 0
+		for i := 0; i < len(block); {
+			if block[i] == template {
+				matches++
+				i += m
+			} else {
+				i++
+			}
+		}
+		d := float64(matches) - mu
+		chiSquared += d * d / variance
+	}
+	return special.Igamc(float64(blocks)/2, chiSquared/2), nil
+}
+
+// AperiodicTemplates returns the m-bit templates that do not overlap a
+// shifted copy of themselves, in increasing order. There are 148 for m = 9.
+func AperiodicTemplates(m int) [][]byte {
+	var templates [][]byte
+	for v := uint32(0); v < 1<<uint(m); v++ {
+		aperiodic := true
+		for shift := 1; shift < m && aperiodic; shift++ {
+			// Prefix of length m-shift against the suffix of the same length
+			if v>>uint(shift) == v&(1<<uint(m-shift)-1) {
+				aperiodic = false
+			}
+		}
+		if aperiodic {
+			templates = append(templates, unpack(v, m))
+		}
+	}
+	return templates
+}
+
+// Parameters of the overlapping template matching test: the all-ones
+// template of 9 bits in blocks of 1032 bits.
+const (
+	overlappingLength = 9
+	overlappingBlock  = 1032
+)
+
+// overlappingPi are the probabilities of 0 to 4 and of 5 or more matches
+// in a block, as corrected by Hamano and Kaneko.
+var overlappingPi = []float64{0.364091, 0.185659, 0.139381, 0.100571, 0.0704323, 0.139865}
+
+// OverlappingTemplate is the overlapping template matching test.
+func OverlappingTemplate(eps []byte) (float64, error) {
+	blocks := len(eps) / overlappingBlock
+	if blocks < 1 {
+		return 0, fmt.Errorf("%w: overlapping template needs at least %d bits", ErrNotApplicable, overlappingBlock)
+	}
+	window := windows(eps, overlappingLength)
+	template := uint32(1)<<overlappingLength - 1
+
+	nu := make([]float64, len(overlappingPi))
+	for i := 0; i < blocks; i++ {
+		matches := 0
+		for _, v := range window[i*overlappingBlock : i*overlappingBlock+overlappingBlock-overlappingLength+1] {
+			if v == template {
+				matches++
+			}
+		}
+		nu[min(matches, len(nu)-1)]++
+	}
+	return special.Igamc(float64(len(nu)-1)/2, chiSquared(nu, overlappingPi, float64(blocks))/2), nil
+}
+
+// universalTable holds, for L = 6 to 16, the minimum sequence length and
+// the expected value and variance of the test statistic.
+var universalTable = []struct {
+	minBits  int
+	expected float64
+	variance float64
+}{
+	{387840, 5.2177052, 2.954},
+	{904960, 6.1962507, 3.125},
+	{2068480, 7.1836656, 3.238},
+	{4654080, 8.1764248, 3.311},
+	{10342400, 9.1723243, 3.356},
+	{22753280, 10.170032, 3.384},
+	{49643520, 11.168765, 3.401},
+	{107560960, 12.168070, 3.410},
+	{231669760, 13.167693, 3.416},
+	{496435200, 14.167488, 3.419},
+	{1059061760, 15.167379, 3.421},
+}
+
+// Universal is Maurer's universal statistical test.
+func Universal(eps []byte) (float64, error) {
+	n := len(eps)
+	l := 0
+	for i, row := range universalTable {
+		if n >= row.minBits {
+			l = 6 + i
+		}
+	}
+	if l == 0 {
+		return 0, fmt.Errorf("%w: universal needs at least %d bits", ErrNotApplicable, universalTable[0].minBits)
+	}
+	row := universalTable[l-6]
+	q := 10 << uint(l)
+	k := n/l - q
+
+	c := 0.7 - 0.8/float64(l) + (4+32/float64(l))*math.Pow(float64(k), -3/float64(l))/15
+	sigma := c * math.Sqrt(row.variance/float64(k))
+
+	last := make([]int, 1<<uint(l))
+	block := func(i int) uint32 {
+		return pack(eps[(i-1)*l : i*l])
+	}
+	for i := 1; i <= q; i++ {
+		last[block(i)] = i
+	}
+	sum := 0.0
+	for i := q + 1; i <= q+k; i++ {
+		v := block(i)
+		sum += math.Log2(float64(i - last[v]))
+		last[v] = i
+	}
+	phi := sum / float64(k)
+	return special.Erfc(math.Abs(phi-row.expected) / (math.Sqrt2 * sigma)), nil
+}
+
+// linearComplexityPi are the class probabilities of the linear complexity
+// test.
+var linearComplexityPi = []float64{0.01047, 0.03125, 0.12500, 0.50000, 0.25000, 0.06250, 0.020833}
+
+// LinearComplexity is the linear complexity test over blocks of m bits.
+func LinearComplexity(eps []byte, m int) (float64, error) {
+	blocks := len(eps) / m
+	if m < 1 || blocks < 1 {
+		return 0, fmt.Errorf("%w: linear complexity needs at least one block of %d bits", ErrNotApplicable, m)
+	}
+
+	sign := 1.0
+	if m%2 == 1 {
+		sign = -1
+	}
+	mean := float64(m)/2 + (9-sign)/36 - (float64(m)/3+2.0/9)/math.Pow(2, float64(m))
+
+	nu := make([]float64, len(linearComplexityPi))
+	for i := 0; i < blocks; i++ {
+		t := sign*(float64(BerlekampMassey(eps[i*m:(i+1)*m]))-mean) + 2.0/9
+		class := int(math.Ceil(t-0.5)) + 3 // classes of width 1 centred on -3 to 3
+		nu[min(max(class, 0), len(nu)-1)]++
+	}
+	return special.Igamc(float64(len(nu)-1)/2, chiSquared(nu, linearComplexityPi, float64(blocks))/2), nil
+}
+
+// BerlekampMassey returns the linear complexity of s: the length of the
+// shortest linear feedback shift register that generates it.
+func BerlekampMassey(s []byte) int {
+	n := len(s)
+	c := make([]byte, n+1)
+	b := make([]byte, n+1)
+	t := make([]byte, n+1)
+	c[0], b[0] = 1, 1
+	l, m := 0, -1
+	for i := 0; i < n; i++ {
+		d := s[i]
+		for j := 1; j <= l; j++ {
+			d ^= c[j] & s[i-j]
+		}
+		if d == 0 {
+			continue
+		}
+		copy(t, c)
+		shift := i - m
+		for j := 0; j+shift <= n; j++ {
+			c[j+shift] ^= b[j]
+		}
+		if 2*l <= i {
+			l = i + 1 - l
+			m = i
+			b, t = t, b
+		}
+	}
+	return l
+}
+
+// Serial is the serial test for overlapping m-bit patterns. It returns
+// the p-values of the first and second differences of the ψ² statistics.
+func Serial(eps []byte, m int) (float64, float64, error) {
+	n := len(eps)
+	if m < 3 || 1<<uint(m) > n {
+		return 0, 0, fmt.Errorf("%w: serial needs 3 <= m <= log2(n)", ErrNotApplicable)
+	}
+	counts := circularCounts(eps, m)
+	psi := make([]float64, 3)
+	for i := range psi {
+		psi[i] = psiSquared(counts, n)
+		counts = prefixCounts(counts)
+	}
+	del1 := psi[0] - psi[1]
+	del2 := psi[0] - 2*psi[1] + psi[2]
+	return special.Igamc(math.Pow(2, float64(m-2)), del1/2), special.Igamc(math.Pow(2, float64(m-3)), del2/2), nil
+}
+
+// psiSquared is the ψ² statistic of the pattern counts of a sequence of n
+// bits.
+func psiSquared(counts []int, n int) float64 {
+	sum := 0.0
+	for _, c := range counts {
+		sum += float64(c) * float64(c)
+	}
+	return sum*float64(len(counts))/float64(n) - float64(n)
+}
+
+// ApproximateEntropy is the approximate entropy test for m-bit patterns.
+func ApproximateEntropy(eps []byte, m int) (float64, error) {
+	n := len(eps)
+	if m < 1 || 1<<uint(m) > n {
+		return 0, fmt.Errorf("%w: approximate entropy needs 1 <= m < log2(n)", ErrNotApplicable)
+	}
+	counts := circularCounts(eps, m+1)
+	phiNext := phi(counts, n)
+	phiM := phi(prefixCounts(counts), n)
+	apEn := phiM - phiNext
+	chiSquared := 2 * float64(n) * (math.Ln2 - apEn)
+	return special.Igamc(math.Pow(2, float64(m-1)), chiSquared/2), nil
+}
+
+// phi is the φ statistic of approximate entropy.
+func phi(counts []int, n int) float64 {
+	sum := 0.0
+	for _, c := range counts {
+		if c > 0 {
+			p := float64(c) / float64(n)
+			sum += p * math.Log(p)
+		}
+	}
+	return sum
+}
+
+// CumulativeSums is the cumulative sums test, forward or backward through
+// the sequence.
+func CumulativeSums(eps []byte, reverse bool) float64 {
+	n := len(eps)
+	s, z := 0, 0
+	for i := range eps {
+		b := eps[i]
+		if reverse {
+			b = eps[n-1-i]
+		}
+		s += 2*int(b) - 1
+		z = max(z, s, -s)
+	}
+
+	fn, fz := float64(n), float64(z)
+	sqrtN := math.Sqrt(fn)
+	sum1 := 0.0
+	for k := int((-fn/fz + 1) / 4); float64(k) <= (fn/fz-1)/4; k++ {
+		sum1 += special.NormalCDF(float64(4*k+1)*fz/sqrtN) - special.NormalCDF(float64(4*k-1)*fz/sqrtN)
+	}
+	sum2 := 0.0
+	for k := int((-fn/fz - 3) / 4); float64(k) <= (fn/fz-1)/4; k++ {
+		sum2 += special.NormalCDF(float64(4*k+3)*fz/sqrtN) - special.NormalCDF(float64(4*k+1)*fz/sqrtN)
+	}
+	return 1 - sum1 + sum2
+}
+
+// ExcursionStates are the states of the random excursions test, and
+// VariantStates those of the random excursions variant test.
+var (
+	ExcursionStates = []int{-4, -3, -2, -1, 1, 2, 3, 4}
+	VariantStates   = []int{-9, -8, -7, -6, -5, -4, -3, -2, -1, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+)
+
+// RandomExcursions is the random excursions test. It returns one p-value
+// per state in ExcursionStates, and is not applicable when the random walk
+// has fewer than max(500, 0.005√n) cycles.
+func RandomExcursions(eps []byte) ([]float64, error) {
+	if err := checkCycles(eps); err != nil {
+		return nil, err
+	}
+	return randomExcursions(eps), nil
+}
+
+func randomExcursions(eps []byte) []float64 {
+	// visits[x+4][k] counts the cycles that visit state x exactly k times,
+	// or at least 5 times for k = 5.
+	var visits [9][6]float64
+	var cycle [9]int
+	endCycle := func() {
+		for _, x := range ExcursionStates {
+			visits[x+4][min(cycle[x+4], 5)]++
+			cycle[x+4] = 0
+		}
+	}
+
+	s, cycles := 0, 0
+	for _, b := range eps {
+		s += 2*int(b) - 1
+		if s == 0 {
+			endCycle()
+			cycles++
+		} else if s >= -4 && s <= 4 {
+			cycle[s+4]++
+		}
+	}
+	if s != 0 {
+		endCycle()
+		cycles++
+	}
+
+	pValues := make([]float64, len(ExcursionStates))
+	for i, x := range ExcursionStates {
+		pValues[i] = special.Igamc(2.5, chiSquared(visits[x+4][:], excursionPi(x), float64(cycles))/2)
+	}
+	return pValues
+}
+
+// excursionPi returns the probabilities that a cycle visits state x 0 to 4
+// times and 5 or more times.
+func excursionPi(x int) []float64 {
+	a := 1 / (2 * math.Abs(float64(x)))
+	pi := []float64{1 - a, 0, 0, 0, 0, 0}
+	for k := 1; k < 5; k++ {
+		pi[k] = a * a * math.Pow(1-a, float64(k-1))
+	}
+	pi[5] = a * math.Pow(1-a, 4)
+	return pi
+}
+
+// RandomExcursionsVariant is the random excursions variant test. It returns
+// one p-value per state in VariantStates and has the same precondition as
+// RandomExcursions.
+func RandomExcursionsVariant(eps []byte) ([]float64, error) {
+	if err := checkCycles(eps); err != nil {
+		return nil, err
+	}
+	return randomExcursionsVariant(eps), nil
+}
+
+func randomExcursionsVariant(eps []byte) []float64 {
+	var visits [19]int
+	s, cycles := 0, 0
+	for _, b := range eps {
+		s += 2*int(b) - 1
+		if s == 0 {
+			cycles++
+		} else if s >= -9 && s <= 9 {
+			visits[s+9]++
+		}
+	}
+	if s != 0 {
+		cycles++
+	}
+
+	pValues := make([]float64, len(VariantStates))
+	for i, x := range VariantStates {
+		j := float64(cycles)
+		d := math.Abs(float64(visits[x+9]) - j)
+		pValues[i] = special.Erfc(d / math.Sqrt(2*j*(4*math.Abs(float64(x))-2)))
+	}
+	return pValues
+}
+
+// checkCycles reports whether the random walk of eps has enough cycles for
+// the random excursions tests.
+func checkCycles(eps []byte) error {
+	s, cycles := 0, 0
+	for _, b := range eps {
+		s += 2*int(b) - 1
+		if s == 0 {
+			cycles++
+		}
+	}
+	if s != 0 {
+		cycles++
+	}
+	if limit := max(500, 0.005*math.Sqrt(float64(len(eps)))); float64(cycles) < limit {
+		return fmt.Errorf("%w: random walk has %d cycles, fewer than %.0f", ErrNotApplicable, cycles, limit)
+	}
+	return nil
+}
+
+// chiSquared is Pearson's statistic for the observed class counts nu
+// against the class probabilities pi over total trials.
+func chiSquared(nu, pi []float64, total float64) float64 {
+	sum := 0.0
+	for i := range nu {
+		expected := total * pi[i]
+		d := nu[i] - expected
+		sum += d * d / expected
+	}
+	return sum
+}
+
+// windows returns the value of the m bits starting at each position of eps
+// that has m bits left, first bit most significant.
+func windows(eps []byte, m int) []uint32 {
+	if len(eps) < m {
+		return nil
+	}
+	mask := uint32(1)<<uint(m) - 1
+	out := make([]uint32, len(eps)-m+1)
+	v := pack(eps[:m])
+	out[0] = v
+	for i := 1; i < len(out); i++ {
+		v = (v<<1 | uint32(eps[i+m-1])) & mask
+		out[i] = v
+	}
+	return out
+}
+
+// circularCounts counts the overlapping m-bit patterns of eps with the
+// first m-1 bits appended to its end.
+func circularCounts(eps []byte, m int) []int {
+	n := len(eps)
+	counts := make([]int, 1<<uint(m))
+	mask := uint32(1)<<uint(m) - 1
+	var v uint32
+	for i := 0; i < m-1; i++ {
+		v = v<<1 | uint32(eps[i%n])
+	}
+	for i := m - 1; i < n+m-1; i++ {
+		v = (v<<1 | uint32(eps[i%n])) & mask
+		counts[v]++
+	}
+	return counts
+}
+
+// prefixCounts turns circular m-bit pattern counts into (m-1)-bit ones.
+func prefixCounts(counts []int) []int {
+	out := make([]int, len(counts)/2)
+	for v, c := range counts {
+		out[v>>1] += c
+	}
+	return out
+}
+
+// pack returns the bits of b as an integer, first bit most significant.
+func pack(b []byte) uint32 {
+	var v uint32
+	for _, bit := range b {
+		v = v<<1 | uint32(bit)
+	}
+	return v
+}
+
+// unpack returns the m bits of v, most significant first.
+func unpack(v uint32, m int) []byte {
+	b := make([]byte, m)
+	for i := range b {
+		b[i] = byte(v>>uint(m-1-i)) & 1
+	}
+	return b
+}
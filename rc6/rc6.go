@@ -0,0 +1,212 @@
+// Package rc6 implements the RC6-w/r/b block cipher with caller-supplied
+// magic constants, so that candidate P and Q values can be evaluated inside
+// the real key schedule and round function instead of a toy transform.
+package rc6
+
+import (
+	"fmt"
+	"math/bits"
+
+	"primer/word"
+)
+
+// Standard magic constants Pw = Odd((e-2)*2^w) and Qw = Odd((phi-1)*2^w).
+var (
+	P16  = word.FromUint64(0xB7E1)
+	Q16  = word.FromUint64(0x9E37)
+	P32  = word.FromUint64(0xB7E15163)
+	Q32  = word.FromUint64(0x9E3779B9)
+	P64  = word.FromUint64(0xB7E151628AED2A6B)
+	Q64  = word.FromUint64(0x9E3779B97F4A7C15)
+	P128 = word.Word{Hi: 0xB7E151628AED2A6A, Lo: 0xBF7158809CF4F3C7}
+	Q128 = word.Word{Hi: 0x9E3779B97F4A7C15, Lo: 0xF39CC0605CEDC835}
+)
+
+// DefaultRounds is the number of rounds of the AES submission, RC6-32/20/b.
+const DefaultRounds = 20
+
+// Cipher is an RC6-w/r/b instance with an expanded key schedule.
+type Cipher struct {
+	w      uint
+	lgw    uint
+	rounds int
+	s      []word.Word
+}
+
+// MagicConstants returns the published Pw and Qw for the given word size.
+func MagicConstants(w uint) (p, q word.Word, err error) {
+	switch w {
+	case 16:
+		return P16, Q16, nil
+	case 32:
+		return P32, Q32, nil
+	case 64:
+		return P64, Q64, nil
+	case 128:
+		return P128, Q128, nil
+	}
+	return word.Word{}, word.Word{}, fmt.Errorf("no published magic constants for %d-bit words", w)
+}
+
+// New expands key into an RC6-w/r/b key schedule using p and q in place of
+// Pw and Qw. Supported word sizes are 8, 16, 32, 64 and 128 bits.
+func New(key []byte, w uint, rounds int, p, q word.Word) (*Cipher, error) {
+	switch w {
+	case 8, 16, 32, 64, 128:
+	default:
+		return nil, fmt.Errorf("unsupported word size: %d", w)
+	}
+	if rounds < 1 || rounds > 255 {
+		return nil, fmt.Errorf("invalid number of rounds: %d", rounds)
+	}
+	if len(key) > 255 {
+		return nil, fmt.Errorf("key too long: %d bytes", len(key))
+	}
+
+	c := &Cipher{
+		w:      w,
+		lgw:    uint(bits.TrailingZeros(w)),
+		rounds: rounds,
+	}
+	c.expandKey(key, p.Truncate(w), q.Truncate(w))
+	return c, nil
+}
+
+// WordSize returns w in bits.
+func (c *Cipher) WordSize() uint { return c.w }
+
+// Rounds returns r.
+func (c *Cipher) Rounds() int { return c.rounds }
+
+// BlockSize returns the block size in bytes (four words).
+func (c *Cipher) BlockSize() int { return int(c.w) / 2 }
+
+// Schedule returns a copy of the expanded key array S[0..2r+3].
+func (c *Cipher) Schedule() []word.Word {
+	s := make([]word.Word, len(c.s))
+	copy(s, c.s)
+	return s
+}
+
+func (c *Cipher) expandKey(key []byte, p, q word.Word) {
+	u := int(c.w / 8)
+	words := (len(key) + u - 1) / u
+	if words == 0 {
+		words = 1
+	}
+
+	// Load the key into L little-endian, as in the reference implementation
+	l := make([]word.Word, words)
+	for i := len(key) - 1; i >= 0; i-- {
+		l[i/u] = l[i/u].Lsh(8, c.w).Or(word.FromUint64(uint64(key[i])))
+	}
+
+	t := 2*c.rounds + 4
+	c.s = make([]word.Word, t)
+	c.s[0] = p
+	for i := 1; i < t; i++ {
+		c.s[i] = c.s[i-1].Add(q, c.w)
+	}
+
+	var a, b word.Word
+	i, j := 0, 0
+	v := 3 * max(words, t)
+	for k := 0; k < v; k++ {
+		a = c.s[i].Add(a, c.w).Add(b, c.w).RotateLeft(3, c.w)
+		c.s[i] = a
+		ab := a.Add(b, c.w)
+		b = l[j].Add(ab, c.w).RotateLeft(c.amount(ab), c.w)
+		l[j] = b
+		i = (i + 1) % t
+		j = (j + 1) % words
+	}
+}
+
+// amount returns the rotation amount encoded in the low lg w bits of x.
+func (c *Cipher) amount(x word.Word) uint {
+	return uint(x.Lo) & (c.w - 1)
+}
+
+// f is the quadratic round function (x*(2x+1)) <<< lg w.
+func (c *Cipher) f(x word.Word) word.Word {
+	twoX1 := x.Add(x, c.w).Or(word.FromUint64(1))
+	return x.Mul(twoX1, c.w).RotateLeft(c.lgw, c.w)
+}
+
+// EncryptWords encrypts one block given as the four words A, B, C, D.
+func (c *Cipher) EncryptWords(a, b, cc, d word.Word) (word.Word, word.Word, word.Word, word.Word) {
+	w := c.w
+	b = b.Add(c.s[0], w)
+	d = d.Add(c.s[1], w)
+	for i := 1; i <= c.rounds; i++ {
+		t := c.f(b)
+		u := c.f(d)
+		a = a.Xor(t).RotateLeft(c.amount(u), w).Add(c.s[2*i], w)
+		cc = cc.Xor(u).RotateLeft(c.amount(t), w).Add(c.s[2*i+1], w)
+		a, b, cc, d = b, cc, d, a
+	}
+	a = a.Add(c.s[2*c.rounds+2], w)
+	cc = cc.Add(c.s[2*c.rounds+3], w)
+	return a, b, cc, d
+}
+
+// DecryptWords decrypts one block given as the four words A, B, C, D.
+func (c *Cipher) DecryptWords(a, b, cc, d word.Word) (word.Word, word.Word, word.Word, word.Word) {
+	w := c.w
+	cc = cc.Sub(c.s[2*c.rounds+3], w)
+	a = a.Sub(c.s[2*c.rounds+2], w)
+	for i := c.rounds; i >= 1; i-- {
+		a, b, cc, d = d, a, b, cc
+		u := c.f(d)
+		t := c.f(b)
+		cc = cc.Sub(c.s[2*i+1], w).RotateRight(c.amount(t), w).Xor(u)
+		a = a.Sub(c.s[2*i], w).RotateRight(c.amount(u), w).Xor(t)
+	}
+	d = d.Sub(c.s[1], w)
+	b = b.Sub(c.s[0], w)
+	return a, b, cc, d
+}
+
+// Encrypt encrypts the first block in src into dst. Words are read and
+// written little-endian. Dst and src may overlap entirely.
+func (c *Cipher) Encrypt(dst, src []byte) {
+	c.checkBlock(dst, src)
+	a, b, cc, d := c.EncryptWords(c.load(src))
+	c.store(dst, a, b, cc, d)
+}
+
+// Decrypt decrypts the first block in src into dst.
+func (c *Cipher) Decrypt(dst, src []byte) {
+	c.checkBlock(dst, src)
+	a, b, cc, d := c.DecryptWords(c.load(src))
+	c.store(dst, a, b, cc, d)
+}
+
+func (c *Cipher) checkBlock(dst, src []byte) {
+	if len(src) < c.BlockSize() {
+		panic("rc6: input not full block")
+	}
+	if len(dst) < c.BlockSize() {
+		panic("rc6: output not full block")
+	}
+}
+
+func (c *Cipher) load(src []byte) (word.Word, word.Word, word.Word, word.Word) {
+	var w [4]word.Word
+	n := int(c.w / 8)
+	for i := range w {
+		for k := n - 1; k >= 0; k-- {
+			w[i] = w[i].Lsh(8, c.w).Or(word.FromUint64(uint64(src[i*n+k])))
+		}
+	}
+	return w[0], w[1], w[2], w[3]
+}
+
+func (c *Cipher) store(dst []byte, a, b, cc, d word.Word) {
+	n := int(c.w / 8)
+	for i, v := range [4]word.Word{a, b, cc, d} {
+		for k := 0; k < n; k++ {
+			dst[i*n+k] = byte(v.Rsh(uint(8 * k)).Lo)
+		}
+	}
+}
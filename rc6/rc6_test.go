@@ -0,0 +1,243 @@
+package rc6
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"primer/word"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("bad hex %q: %v", s, err)
+	}
+	return b
+}
+
+// Known-answer vectors from the RC6 AES submission (RC6-32/20/b) and the
+// RC5/RC6 test vector draft for the other word sizes.
+func TestKnownAnswers(t *testing.T) {
+	tests := []struct {
+		name       string
+		w          uint
+		rounds     int
+		p, q       word.Word
+		key        string
+		plaintext  string
+		ciphertext string
+	}{
+		{
+			name:       "RC6-32/20/16 zero",
+			w:          32,
+			rounds:     20,
+			p:          P32,
+			q:          Q32,
+			key:        "00000000000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "8fc3a53656b1f778c129df4e9848a41e",
+		},
+		{
+			name:       "RC6-32/20/16",
+			w:          32,
+			rounds:     20,
+			p:          P32,
+			q:          Q32,
+			key:        "0123456789abcdef0112233445566778",
+			plaintext:  "02132435465768798a9bacbdcedfe0f1",
+			ciphertext: "524e192f4715c6231f51f6367ea43f18",
+		},
+		{
+			name:       "RC6-32/20/24 zero",
+			w:          32,
+			rounds:     20,
+			p:          P32,
+			q:          Q32,
+			key:        "000000000000000000000000000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "6cd61bcb190b30384e8a3f168690ae82",
+		},
+		{
+			name:       "RC6-32/20/24",
+			w:          32,
+			rounds:     20,
+			p:          P32,
+			q:          Q32,
+			key:        "0123456789abcdef0112233445566778899aabbccddeeff0",
+			plaintext:  "02132435465768798a9bacbdcedfe0f1",
+			ciphertext: "688329d019e505041e52e92af95291d4",
+		},
+		{
+			name:       "RC6-32/20/32 zero",
+			w:          32,
+			rounds:     20,
+			p:          P32,
+			q:          Q32,
+			key:        "0000000000000000000000000000000000000000000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "8f5fbd0510d15fa893fa3fda6e857ec2",
+		},
+		{
+			name:       "RC6-32/20/32",
+			w:          32,
+			rounds:     20,
+			p:          P32,
+			q:          Q32,
+			key:        "0123456789abcdef0112233445566778899aabbccddeeff01032547698badcfe",
+			plaintext:  "02132435465768798a9bacbdcedfe0f1",
+			ciphertext: "c8241816f0d7e48920ad16a1674e5d48",
+		},
+		{
+			name:       "RC6-8/12/4",
+			w:          8,
+			rounds:     12,
+			p:          word.FromUint64(0xB7),
+			q:          word.FromUint64(0x9F),
+			key:        "00010203",
+			plaintext:  "00010203",
+			ciphertext: "aefc4612",
+		},
+		{
+			name:       "RC6-16/16/8",
+			w:          16,
+			rounds:     16,
+			p:          P16,
+			q:          Q16,
+			key:        "0001020304050607",
+			plaintext:  "0001020304050607",
+			ciphertext: "2ff0b68eaeffad5b",
+		},
+		{
+			name:       "RC6-32/20/16 counting",
+			w:          32,
+			rounds:     20,
+			p:          P32,
+			q:          Q32,
+			key:        "000102030405060708090a0b0c0d0e0f",
+			plaintext:  "000102030405060708090a0b0c0d0e0f",
+			ciphertext: "3a96f9c7f6755cfe46f00e3dcd5d2a3c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(mustHex(t, tt.key), tt.w, tt.rounds, tt.p, tt.q)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			pt := mustHex(t, tt.plaintext)
+			want := mustHex(t, tt.ciphertext)
+
+			got := make([]byte, len(pt))
+			c.Encrypt(got, pt)
+			if !bytes.Equal(got, want) {
+				t.Errorf("Encrypt() = %x, want %x", got, want)
+			}
+
+			back := make([]byte, len(pt))
+			c.Decrypt(back, got)
+			if !bytes.Equal(back, pt) {
+				t.Errorf("Decrypt() = %x, want %x", back, pt)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	key := mustHex(t, "0123456789abcdef0112233445566778")
+	p := word.Word{Hi: 0x0123456789abcdef, Lo: 0x12345679}
+	q := word.Word{Hi: 0xfedcba9876543210, Lo: 0x9abcdef1}
+	for _, w := range []uint{8, 16, 32, 64, 128} {
+		for _, rounds := range []int{1, 4, 20} {
+			c, err := New(key, w, rounds, p, q)
+			if err != nil {
+				t.Fatalf("New(w=%d, r=%d) error = %v", w, rounds, err)
+			}
+
+			pt := make([]byte, c.BlockSize())
+			for i := range pt {
+				pt[i] = byte(i*37 + 11)
+			}
+			ct := make([]byte, len(pt))
+			c.Encrypt(ct, pt)
+			if bytes.Equal(ct, pt) {
+				t.Errorf("w=%d r=%d: ciphertext equals plaintext", w, rounds)
+			}
+
+			back := make([]byte, len(pt))
+			c.Decrypt(back, ct)
+			if !bytes.Equal(back, pt) {
+				t.Errorf("w=%d r=%d: Decrypt() = %x, want %x", w, rounds, back, pt)
+			}
+		}
+	}
+}
+
+func TestConstantsAffectSchedule(t *testing.T) {
+	key := make([]byte, 16)
+	a, _ := New(key, 32, DefaultRounds, P32, Q32)
+	b, _ := New(key, 32, DefaultRounds, P32, Q32.Add(word.FromUint64(2), 32))
+
+	sa, sb := a.Schedule(), b.Schedule()
+	same := 0
+	for i := range sa {
+		if sa[i] == sb[i] {
+			same++
+		}
+	}
+	if same == len(sa) {
+		t.Error("changing Q did not change the key schedule")
+	}
+}
+
+func TestMagicConstants(t *testing.T) {
+	for _, w := range []uint{16, 32, 64, 128} {
+		p, q, err := MagicConstants(w)
+		if err != nil {
+			t.Fatalf("MagicConstants(%d) error = %v", w, err)
+		}
+		if p.Bit(0) != 1 || q.Bit(0) != 1 {
+			t.Errorf("MagicConstants(%d) = %v, %v; want odd values", w, p, q)
+		}
+		if p.BitLen() != int(w) || q.BitLen() != int(w) {
+			t.Errorf("MagicConstants(%d) = %v, %v; want %d-bit values", w, p, q, w)
+		}
+	}
+	if _, _, err := MagicConstants(24); err == nil {
+		t.Error("MagicConstants(24) expected error")
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    []byte
+		w      uint
+		rounds int
+	}{
+		{"Unsupported word size", nil, 24, 20},
+		{"Zero rounds", nil, 32, 0},
+		{"Too many rounds", nil, 32, 256},
+		{"Key too long", make([]byte, 256), 32, 20},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.key, tt.w, tt.rounds, P32, Q32); err == nil {
+				t.Error("New() expected error")
+			}
+		})
+	}
+}
+
+func BenchmarkEncrypt(b *testing.B) {
+	c, _ := New(make([]byte, 16), 32, DefaultRounds, P32, Q32)
+	block := make([]byte, c.BlockSize())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Encrypt(block, block)
+	}
+}
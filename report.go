@@ -0,0 +1,39 @@
+package main
+
+import (
+    "primer/constants"
+    "fmt"
+    "os"
+)
+
+const reportUsage = `Usage: primer report [flags] <results.json>
+
+Renders a saved GenerationResult in the same formats as generate.
+
+Flags:
+`
+
+func runReport(args []string) {
+    opts := Options{}
+    fs := newFlagSet("report", reportUsage)
+    fs.Var((*outputFormatFlag)(&opts.OutputFormat), "format", "Output format (text, json, csv)")
+    fs.BoolVar(&opts.Verbose, "verbose", false, "Include the detailed analysis of each constant")
+    fs.StringVar(&opts.OutputFile, "output", "", "Output file path (json and csv)")
+    fs.Parse(args)
+
+    if fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+    if opts.OutputFormat == "" {
+        opts.OutputFormat = FormatText
+    }
+
+    result, err := constants.LoadResult(fs.Arg(0))
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        os.Exit(1)
+    }
+
+    outputResults(result, opts)
+}
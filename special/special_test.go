@@ -0,0 +1,71 @@
+package special
+
+import (
+	"math"
+	"testing"
+)
+
+func TestIncompleteGamma(t *testing.T) {
+	tests := []struct {
+		a, x, want float64
+	}{
+		{1, 1, math.Exp(-1)},
+		{0.5, 2, math.Erfc(math.Sqrt(2))},
+		{1.5, 4.5 / 2, 0.212290}, // chi-square survival, 3 degrees of freedom
+		{16384, 16384, 0.498961},
+	}
+	for _, tt := range tests {
+		if got := Igamc(tt.a, tt.x); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("Igamc(%v, %v) = %.6f, want %.6f", tt.a, tt.x, got, tt.want)
+		}
+		if got := Igam(tt.a, tt.x) + Igamc(tt.a, tt.x); math.Abs(got-1) > 1e-9 {
+			t.Errorf("Igam + Igamc (%v, %v) = %v", tt.a, tt.x, got)
+		}
+	}
+}
+
+func TestDistributions(t *testing.T) {
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"NormalCDF(0)", NormalCDF(0), 0.5},
+		{"NormalCDF(1.96)", NormalCDF(1.96), 0.975002},
+		{"NormalCDF(-1)", NormalCDF(-1), 0.158655},
+		{"NormalTwoSided(1.96)", NormalTwoSided(1.96), 0.049996},
+		{"NormalTwoSided(-1.96)", NormalTwoSided(-1.96), 0.049996},
+		{"ChiSquareSurvival(0, 3)", ChiSquareSurvival(0, 3), 1},
+		{"ChiSquareSurvival(+Inf, 3)", ChiSquareSurvival(math.Inf(1), 3), 0},
+		{"ChiSquareSurvival(4.5, 3)", ChiSquareSurvival(4.5, 3), 0.212290},
+		{"ChiSquareSurvival(3.841459, 1)", ChiSquareSurvival(3.841459, 1), 0.05},
+		{"ChiSquareSurvival(2, 2)", ChiSquareSurvival(2, 2), math.Exp(-1)},
+		{"ChiSquareSurvival(16.918978, 9)", ChiSquareSurvival(16.918978, 9), 0.05},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > 1e-6 {
+			t.Errorf("%s = %.6f, want %.6f", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestKolmogorovSmirnov(t *testing.T) {
+	// Tabulated critical values of the Kolmogorov distribution
+	for _, tt := range []struct{ lambda, want float64 }{
+		{1.3581, 0.05},
+		{1.6276, 0.01},
+		{0.1, 1},
+	} {
+		if got := KolmogorovSurvival(tt.lambda); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("KolmogorovSurvival(%v) = %.6f, want %v", tt.lambda, got, tt.want)
+		}
+	}
+
+	d, p := KolmogorovSmirnovUniform([]float64{0.05, 0.15, 0.25, 0.35, 0.45, 0.55, 0.65, 0.75, 0.85, 0.95})
+	if math.Abs(d-0.05) > 1e-12 || p < 0.99 {
+		t.Errorf("evenly spread samples: D = %v, p-value = %v", d, p)
+	}
+	d, p = KolmogorovSmirnovUniform([]float64{0.001, 0.002, 0.003, 0.004, 0.005, 0.006, 0.007, 0.008, 0.009, 0.01})
+	if math.Abs(d-0.99) > 1e-12 || p > 1e-6 {
+		t.Errorf("samples near zero: D = %v, p-value = %v", d, p)
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+    "primer/constants"
+    "encoding/json"
+    "fmt"
+    "os"
+)
+
+const verifyUsage = `Usage: primer verify [flags] <results.json>
+
+Re-checks a saved GenerationResult against its own configuration. The
+primality, validation and statistical tests are recomputed from the selected
+values; seeded results are also regenerated from their candidate indices.
+Exits with status 1 if any check fails.
+
+Flags:
+`
+
+func runVerify(args []string) {
+    fs := newFlagSet("verify", verifyUsage)
+    var format OutputFormat
+    fs.Var((*outputFormatFlag)(&format), "format", "Output format (text, json)")
+    failuresOnly := fs.Bool("failures", false, "Only list failed checks")
+    fs.Parse(args)
+
+    if fs.NArg() != 1 {
+        fs.Usage()
+        os.Exit(2)
+    }
+
+    result, err := constants.LoadResult(fs.Arg(0))
+    if err != nil {
+        fmt.Printf("Error: %v\n", err)
+        os.Exit(1)
+    }
+
+    config := result.Config
+    config.DetailedLogging = false
+    if err := constants.ValidateConfig(&config); err != nil {
+        fmt.Printf("Error: saved configuration is invalid: %v\n", err)
+        os.Exit(1)
+    }
+
+    verification := constants.NewGenerator(config).Verify(result)
+
+    switch format {
+    case FormatJSON:
+        output, err := json.MarshalIndent(verification, "", "  ")
+        if err != nil {
+            fmt.Printf("Error generating JSON output: %v\n", err)
+            os.Exit(1)
+        }
+        fmt.Println(string(output))
+    case FormatCSV:
+        fmt.Println("Error: verify supports text and json output")
+        os.Exit(2)
+    default:
+        printVerification(verification, *failuresOnly)
+    }
+
+    if !verification.Passed {
+        os.Exit(1)
+    }
+}
+
+func printVerification(v constants.Verification, failuresOnly bool) {
+    failed := 0
+    for _, check := range v.Checks {
+        if !check.Passed {
+            failed++
+        } else if failuresOnly {
+            continue
+        }
+        status := "PASS"
+        if !check.Passed {
+            status = "FAIL"
+        }
+        fmt.Printf("%s  %-3s %-24s %s\n", status, check.Constant, check.Name, check.Details)
+    }
+
+    if v.Passed {
+        fmt.Printf("\nVerified: all %d checks passed\n", len(v.Checks))
+    } else {
+        fmt.Printf("\nVerification failed: %d of %d checks failed\n", failed, len(v.Checks))
+    }
+}
@@ -0,0 +1,278 @@
+// Package word provides a fixed-width unsigned integer of up to 128 bits
+// with modular arithmetic for a configurable word size w.
+package word
+
+import (
+	"fmt"
+	"math/big"
+	"math/bits"
+	"strings"
+)
+
+// MaxBits is the largest supported word size.
+const MaxBits = 128
+
+// Word is an unsigned integer of up to 128 bits. Arithmetic methods take
+// the word size w and reduce their result modulo 2^w.
+type Word struct {
+	Hi, Lo uint64
+}
+
+// FromUint64 returns v as a Word.
+func FromUint64(v uint64) Word {
+	return Word{Lo: v}
+}
+
+// FromBytes interprets the last 16 bytes of b as a big-endian value.
+func FromBytes(b []byte) Word {
+	var x Word
+	if len(b) > 16 {
+		b = b[len(b)-16:]
+	}
+	for _, c := range b {
+		x = Word{Hi: x.Hi<<8 | x.Lo>>56, Lo: x.Lo<<8 | uint64(c)}
+	}
+	return x
+}
+
+// FromBig returns the low 128 bits of v.
+func FromBig(v *big.Int) Word {
+	var x Word
+	b := new(big.Int).Set(v)
+	lo := new(big.Int).And(b, new(big.Int).SetUint64(^uint64(0)))
+	x.Lo = lo.Uint64()
+	x.Hi = b.Rsh(b, 64).Uint64()
+	return x
+}
+
+// Parse parses a decimal or 0x-prefixed hexadecimal value.
+func Parse(s string) (Word, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "_", "")
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok || v.Sign() < 0 {
+		return Word{}, fmt.Errorf("invalid word value: %q", s)
+	}
+	if v.BitLen() > MaxBits {
+		return Word{}, fmt.Errorf("value %q exceeds %d bits", s, MaxBits)
+	}
+	return FromBig(v), nil
+}
+
+// Mask returns 2^w - 1.
+func Mask(w uint) Word {
+	switch {
+	case w >= 128:
+		return Word{Hi: ^uint64(0), Lo: ^uint64(0)}
+	case w > 64:
+		return Word{Hi: ^uint64(0) >> (128 - w), Lo: ^uint64(0)}
+	case w == 64:
+		return Word{Lo: ^uint64(0)}
+	default:
+		return Word{Lo: (uint64(1) << w) - 1}
+	}
+}
+
+// Uint64 returns the low 64 bits.
+func (x Word) Uint64() uint64 { return x.Lo }
+
+// Big returns x as a big.Int.
+func (x Word) Big() *big.Int {
+	v := new(big.Int).SetUint64(x.Hi)
+	v.Lsh(v, 64)
+	return v.Or(v, new(big.Int).SetUint64(x.Lo))
+}
+
+// IsZero reports whether x == 0.
+func (x Word) IsZero() bool { return x.Hi == 0 && x.Lo == 0 }
+
+// Cmp returns -1, 0 or +1 as x is less than, equal to or greater than y.
+func (x Word) Cmp(y Word) int {
+	switch {
+	case x.Hi < y.Hi:
+		return -1
+	case x.Hi > y.Hi:
+		return 1
+	case x.Lo < y.Lo:
+		return -1
+	case x.Lo > y.Lo:
+		return 1
+	}
+	return 0
+}
+
+// BitLen returns the number of bits needed to represent x.
+func (x Word) BitLen() int {
+	if x.Hi != 0 {
+		return 64 + bits.Len64(x.Hi)
+	}
+	return bits.Len64(x.Lo)
+}
+
+// Bit returns bit i of x.
+func (x Word) Bit(i uint) uint {
+	if i >= 64 {
+		return uint(x.Hi>>(i-64)) & 1
+	}
+	return uint(x.Lo>>i) & 1
+}
+
+// OnesCount returns the number of set bits in x.
+func (x Word) OnesCount() int {
+	return bits.OnesCount64(x.Hi) + bits.OnesCount64(x.Lo)
+}
+
+// Truncate reduces x modulo 2^w.
+func (x Word) Truncate(w uint) Word {
+	if w <= 64 {
+		return Word{Lo: x.Lo & lowMask(w)}
+	}
+	return x.And(Mask(w))
+}
+
+// lowMask returns 2^w - 1 for w <= 64.
+func lowMask(w uint) uint64 {
+	return ^uint64(0) >> (64 - w)
+}
+
+// And returns x & y.
+func (x Word) And(y Word) Word { return Word{x.Hi & y.Hi, x.Lo & y.Lo} }
+
+// Or returns x | y.
+func (x Word) Or(y Word) Word { return Word{x.Hi | y.Hi, x.Lo | y.Lo} }
+
+// Xor returns x ^ y.
+func (x Word) Xor(y Word) Word { return Word{x.Hi ^ y.Hi, x.Lo ^ y.Lo} }
+
+// Not returns the w-bit complement of x.
+func (x Word) Not(w uint) Word {
+	return Word{^x.Hi, ^x.Lo}.Truncate(w)
+}
+
+// Lsh returns x << n truncated to w bits.
+func (x Word) Lsh(n, w uint) Word {
+	var r Word
+	switch {
+	case n >= 128:
+	case n >= 64:
+		r = Word{Hi: x.Lo << (n - 64)}
+	case n == 0:
+		r = x
+	default:
+		r = Word{Hi: x.Hi<<n | x.Lo>>(64-n), Lo: x.Lo << n}
+	}
+	return r.Truncate(w)
+}
+
+// Rsh returns x >> n.
+func (x Word) Rsh(n uint) Word {
+	switch {
+	case n >= 128:
+		return Word{}
+	case n >= 64:
+		return Word{Lo: x.Hi >> (n - 64)}
+	case n == 0:
+		return x
+	}
+	return Word{Hi: x.Hi >> n, Lo: x.Lo>>n | x.Hi<<(64-n)}
+}
+
+// RotateLeft rotates the w-bit value x left by n mod w bits.
+func (x Word) RotateLeft(n, w uint) Word {
+	if n >= w {
+		n %= w
+	}
+	if w <= 64 {
+		m := lowMask(w)
+		v := x.Lo & m
+		return Word{Lo: (v<<n | v>>(w-n)) & m}
+	}
+	if n == 0 {
+		return x.Truncate(w)
+	}
+	x = x.Truncate(w)
+	return x.Lsh(n, w).Or(x.Rsh(w - n))
+}
+
+// RotateRight rotates the w-bit value x right by n mod w bits.
+func (x Word) RotateRight(n, w uint) Word {
+	if n >= w {
+		n %= w
+	}
+	return x.RotateLeft(w-n, w)
+}
+
+// Add returns x + y mod 2^w.
+func (x Word) Add(y Word, w uint) Word {
+	if w <= 64 {
+		return Word{Lo: (x.Lo + y.Lo) & lowMask(w)}
+	}
+	lo, carry := bits.Add64(x.Lo, y.Lo, 0)
+	hi, _ := bits.Add64(x.Hi, y.Hi, carry)
+	return Word{hi, lo}.Truncate(w)
+}
+
+// Sub returns x - y mod 2^w.
+func (x Word) Sub(y Word, w uint) Word {
+	if w <= 64 {
+		return Word{Lo: (x.Lo - y.Lo) & lowMask(w)}
+	}
+	lo, borrow := bits.Sub64(x.Lo, y.Lo, 0)
+	hi, _ := bits.Sub64(x.Hi, y.Hi, borrow)
+	return Word{hi, lo}.Truncate(w)
+}
+
+// Mul returns x * y mod 2^w.
+func (x Word) Mul(y Word, w uint) Word {
+	if w <= 64 {
+		return Word{Lo: (x.Lo * y.Lo) & lowMask(w)}
+	}
+	hi, lo := bits.Mul64(x.Lo, y.Lo)
+	hi += x.Hi*y.Lo + x.Lo*y.Hi
+	return Word{hi, lo}.Truncate(w)
+}
+
+// Hex returns x as 0x-prefixed upper-case hexadecimal, zero-padded to w bits.
+func (x Word) Hex(w uint) string {
+	digits := int(w+3) / 4
+	return fmt.Sprintf("0x%0*X", digits, x.Big())
+}
+
+// String returns x as 0x-prefixed upper-case hexadecimal.
+func (x Word) String() string {
+	return fmt.Sprintf("0x%X", x.Big())
+}
+
+// Format implements fmt.Formatter with the same verbs as big.Int, so that
+// %X, %x and %d print the numeric value.
+func (x Word) Format(s fmt.State, ch rune) {
+	if ch == 'v' || ch == 's' {
+		fmt.Fprint(s, x.String())
+		return
+	}
+	x.Big().Format(s, ch)
+}
+
+// MarshalText encodes x as 0x-prefixed hexadecimal.
+func (x Word) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText decodes a decimal or 0x-prefixed hexadecimal value.
+func (x *Word) UnmarshalText(text []byte) error {
+	v, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+
+// UnmarshalJSON accepts either a JSON string or a bare JSON number, so that
+// results written before values were encoded as strings still load.
+func (x *Word) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	return x.UnmarshalText([]byte(s))
+}
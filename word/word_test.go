@@ -0,0 +1,121 @@
+package word
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+)
+
+func TestArithmeticMatchesBig(t *testing.T) {
+	values := []Word{
+		{},
+		FromUint64(1),
+		FromUint64(0xB7E15163),
+		FromUint64(0x9E3779B97F4A7C15),
+		{Hi: 0xB7E151628AED2A6A, Lo: 0xBF7158809CF4F3C7},
+		{Hi: ^uint64(0), Lo: ^uint64(0)},
+	}
+
+	for _, w := range []uint{16, 32, 64, 128} {
+		mod := new(big.Int).Lsh(big.NewInt(1), w)
+		for _, a := range values {
+			for _, b := range values {
+				a, b := a.Truncate(w), b.Truncate(w)
+				ab, bb := a.Big(), b.Big()
+
+				check := func(op string, got Word, want *big.Int) {
+					want.Mod(want, mod)
+					if got.Big().Cmp(want) != 0 {
+						t.Errorf("w=%d %v %s %v = %v, want 0x%X", w, a, op, b, got, want)
+					}
+				}
+				check("+", a.Add(b, w), new(big.Int).Add(ab, bb))
+				check("-", a.Sub(b, w), new(big.Int).Add(new(big.Int).Sub(ab, bb), mod))
+				check("*", a.Mul(b, w), new(big.Int).Mul(ab, bb))
+			}
+		}
+	}
+}
+
+func TestRotate(t *testing.T) {
+	tests := []struct {
+		x    Word
+		n, w uint
+		want Word
+	}{
+		{FromUint64(0x80000001), 1, 32, FromUint64(0x00000003)},
+		{FromUint64(0x8001), 4, 16, FromUint64(0x0018)},
+		{FromUint64(0x8000000000000000), 1, 64, FromUint64(1)},
+		{Word{Hi: 1 << 63}, 1, 128, FromUint64(1)},
+		{FromUint64(1), 64, 128, Word{Hi: 1}},
+		{FromUint64(0x12345678), 32, 32, FromUint64(0x12345678)},
+	}
+
+	for _, tt := range tests {
+		got := tt.x.RotateLeft(tt.n, tt.w)
+		if got != tt.want {
+			t.Errorf("RotateLeft(%v, %d, %d) = %v, want %v", tt.x, tt.n, tt.w, got, tt.want)
+		}
+		if back := got.RotateRight(tt.n, tt.w); back != tt.x.Truncate(tt.w) {
+			t.Errorf("RotateRight(%v, %d, %d) = %v, want %v", got, tt.n, tt.w, back, tt.x)
+		}
+	}
+}
+
+func TestParseAndFormat(t *testing.T) {
+	x, err := Parse("0xB7E151628AED2A6ABF7158809CF4F3C7")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if x.Hi != 0xB7E151628AED2A6A || x.Lo != 0xBF7158809CF4F3C7 {
+		t.Errorf("Parse() = %#v", x)
+	}
+	if got := fmt.Sprintf("0x%X", x); got != "0xB7E151628AED2A6ABF7158809CF4F3C7" {
+		t.Errorf("Sprintf(%%X) = %s", got)
+	}
+	if got := FromUint64(0xB7E1).Hex(32); got != "0x0000B7E1" {
+		t.Errorf("Hex(32) = %s", got)
+	}
+
+	if _, err := Parse("0x1" + "00000000000000000000000000000000"); err == nil {
+		t.Error("Parse() accepted a 129-bit value")
+	}
+	if _, err := Parse("-5"); err == nil {
+		t.Error("Parse() accepted a negative value")
+	}
+}
+
+func TestFromBytes(t *testing.T) {
+	if got := FromBytes([]byte{0xB7, 0xE1, 0x51, 0x63}); got != FromUint64(0xB7E15163) {
+		t.Errorf("FromBytes(4) = %v", got)
+	}
+	b := []byte{
+		0xB7, 0xE1, 0x51, 0x62, 0x8A, 0xED, 0x2A, 0x6A,
+		0xBF, 0x71, 0x58, 0x80, 0x9C, 0xF4, 0xF3, 0xC7,
+	}
+	if got := FromBytes(b); got != (Word{Hi: 0xB7E151628AED2A6A, Lo: 0xBF7158809CF4F3C7}) {
+		t.Errorf("FromBytes(16) = %v", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	x := Word{Hi: 0x9E3779B97F4A7C15, Lo: 0xF39CC0605CEDC835}
+	data, err := json.Marshal(x)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `"0x9E3779B97F4A7C15F39CC0605CEDC835"` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	var y Word
+	if err := json.Unmarshal(data, &y); err != nil || y != x {
+		t.Errorf("Unmarshal() = %v, %v; want %v", y, err, x)
+	}
+
+	// Results written with numeric values still decode
+	if err := json.Unmarshal([]byte("3084996963"), &y); err != nil || y != FromUint64(0xB7E15163) {
+		t.Errorf("Unmarshal(number) = %v, %v", y, err)
+	}
+}